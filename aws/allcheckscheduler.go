@@ -0,0 +1,133 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// SchedulerNode is one unit of work in an all-checks run - a module's Print
+// call wrapped in a closure, plus the names of whichever other nodes it
+// reads loot from. all-checks builds a []SchedulerNode from its module calls
+// and hands it to RunScheduled rather than calling each Print method inline,
+// so modules that don't depend on each other's output (e.g. buckets and sqs)
+// run concurrently instead of waiting their turn. cape does not go through
+// RunScheduled.
+type SchedulerNode struct {
+	Name      string
+	DependsOn []string
+	Run       func() error
+}
+
+// NodeResult is one SchedulerNode's outcome, recorded for the run manifest.
+type NodeResult struct {
+	Name     string
+	Duration time.Duration
+	Err      error
+}
+
+// SchedulerManifest is what RunScheduled returns - a machine-readable record
+// of what ran, how long it took, and what failed, so a caller (or a human
+// reading the written-out JSON afterward) doesn't have to scrape stdout.
+type SchedulerManifest struct {
+	GeneratedAt time.Time
+	Results     []NodeResult
+}
+
+// RunScheduled runs nodes, starting each one as soon as everything in its
+// DependsOn has finished, never running more than maxParallel at once
+// (maxParallel <= 0 is treated as 1). A DependsOn name that doesn't match
+// any node is ignored rather than treated as an error, so a node can list a
+// dependency the caller decided not to include in this run without the
+// whole graph deadlocking.
+func RunScheduled(nodes []SchedulerNode, maxParallel int) SchedulerManifest {
+	if maxParallel < 1 {
+		maxParallel = 1
+	}
+
+	done := make(map[string]chan struct{}, len(nodes))
+	for _, node := range nodes {
+		done[node.Name] = make(chan struct{})
+	}
+
+	results := make([]NodeResult, len(nodes))
+	sem := make(chan struct{}, maxParallel)
+
+	var wg sync.WaitGroup
+	for i, node := range nodes {
+		wg.Add(1)
+		go func(i int, node SchedulerNode) {
+			defer wg.Done()
+			for _, dep := range node.DependsOn {
+				if depDone, ok := done[dep]; ok {
+					<-depDone
+				}
+			}
+
+			sem <- struct{}{}
+			start := time.Now()
+			err := node.Run()
+			<-sem
+
+			results[i] = NodeResult{Name: node.Name, Duration: time.Since(start), Err: err}
+			close(done[node.Name])
+		}(i, node)
+	}
+	wg.Wait()
+
+	return SchedulerManifest{GeneratedAt: time.Now(), Results: results}
+}
+
+// WriteJSON writes m to <outputDirectory>/cloudfox-output/aws/all-checks-scheduler.json
+// so a scripted caller can check which modules failed without parsing logs.
+func (m SchedulerManifest) WriteJSON(outputDirectory string) error {
+	dir := filepath.Join(outputDirectory, "cloudfox-output", "aws")
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("creating %s: %s", dir, err)
+	}
+
+	type resultJSON struct {
+		Name       string `json:"name"`
+		DurationMs int64  `json:"duration_ms"`
+		Error      string `json:"error,omitempty"`
+	}
+	type manifestJSON struct {
+		GeneratedAt time.Time    `json:"generated_at"`
+		Results     []resultJSON `json:"results"`
+	}
+
+	out := manifestJSON{GeneratedAt: m.GeneratedAt}
+	for _, result := range m.Results {
+		row := resultJSON{Name: result.Name, DurationMs: result.Duration.Milliseconds()}
+		if result.Err != nil {
+			row.Error = result.Err.Error()
+		}
+		out.Results = append(out.Results, row)
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling scheduler manifest: %s", err)
+	}
+
+	path := filepath.Join(dir, "all-checks-scheduler.json")
+	if err := os.WriteFile(path, data, 0640); err != nil {
+		return fmt.Errorf("writing %s: %s", path, err)
+	}
+	return nil
+}
+
+// Errors returns the subset of m.Results that failed, in the order they
+// appear in the manifest.
+func (m SchedulerManifest) Errors() []NodeResult {
+	var failed []NodeResult
+	for _, result := range m.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
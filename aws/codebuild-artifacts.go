@@ -0,0 +1,265 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	codebuildTypes "github.com/aws/aws-sdk-go-v2/service/codebuild/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/sirupsen/logrus"
+)
+
+type CodeBuildArtifactsModule struct {
+	CodeBuildClient sdk.AWSCodeBuildClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	MappedArtifactLocations []MappedCodeBuildArtifactLocation
+	CommandCounter          internal.CommandCounter
+
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type MappedCodeBuildArtifactLocation struct {
+	Region   string
+	Project  string
+	Kind     string
+	Bucket   string
+	Location string
+}
+
+func (m *CodeBuildArtifactsModule) PrintCodeBuildArtifacts(outputDirectory string, verbosity int) {
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "codebuild-artifacts"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating CodeBuild artifact, cache, and report-group S3 locations for account %s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+	fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+
+	for _, region := range m.AWSRegions {
+		m.CommandCounter.Total++
+		m.analyzeRegion(region)
+	}
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Project",
+		"Kind",
+		"Bucket",
+		"Location",
+	}
+
+	var tableCols []string
+	if m.AWSTableCols != "" {
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Project",
+			"Kind",
+			"Bucket",
+			"Location",
+		}
+	} else {
+		tableCols = []string{
+			"Region",
+			"Project",
+			"Kind",
+			"Bucket",
+		}
+	}
+
+	for _, artifact := range m.MappedArtifactLocations {
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				artifact.Region,
+				artifact.Project,
+				artifact.Kind,
+				artifact.Bucket,
+				artifact.Location,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s][%s] %s CodeBuild S3 locations found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)))
+	} else {
+		fmt.Printf("[%s][%s] No CodeBuild S3 locations found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *CodeBuildArtifactsModule) analyzeRegion(region string) {
+	Projects, err := sdk.CachedCodeBuildListProjects(m.CodeBuildClient, aws.ToString(m.Caller.Account), region)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+	if len(Projects) == 0 {
+		return
+	}
+
+	ProjectDetails, err := sdk.CachedCodeBuildBatchGetProjects(m.CodeBuildClient, aws.ToString(m.Caller.Account), region, Projects)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, project := range ProjectDetails {
+		m.MappedArtifactLocations = append(m.MappedArtifactLocations, mapCodeBuildArtifactLocations(region, project)...)
+	}
+
+	ReportGroupArns, err := sdk.CachedCodeBuildListReportGroups(m.CodeBuildClient, aws.ToString(m.Caller.Account), region)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+	if len(ReportGroupArns) == 0 {
+		return
+	}
+
+	ReportGroups, err := sdk.CachedCodeBuildBatchGetReportGroups(m.CodeBuildClient, aws.ToString(m.Caller.Account), region, ReportGroupArns)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, reportGroup := range ReportGroups {
+		if location, ok := mapCodeBuildReportGroupLocation(region, reportGroup); ok {
+			m.MappedArtifactLocations = append(m.MappedArtifactLocations, location)
+		}
+	}
+}
+
+func mapCodeBuildArtifactLocations(region string, project codebuildTypes.Project) []MappedCodeBuildArtifactLocation {
+	var locations []MappedCodeBuildArtifactLocation
+	projectName := aws.ToString(project.Name)
+
+	if project.Cache != nil && project.Cache.Type == codebuildTypes.CacheTypeS3 {
+		location := aws.ToString(project.Cache.Location)
+		locations = append(locations, MappedCodeBuildArtifactLocation{
+			Region: region, Project: projectName, Kind: "Cache", Bucket: getBucketNameFromCodeBuildLocation(location), Location: location,
+		})
+	}
+
+	if project.Artifacts != nil && project.Artifacts.Type == codebuildTypes.ArtifactsTypeS3 {
+		location := aws.ToString(project.Artifacts.Location)
+		locations = append(locations, MappedCodeBuildArtifactLocation{
+			Region: region, Project: projectName, Kind: "Artifacts", Bucket: getBucketNameFromCodeBuildLocation(location), Location: location,
+		})
+	}
+
+	for _, secondary := range project.SecondaryArtifacts {
+		if secondary.Type != codebuildTypes.ArtifactsTypeS3 {
+			continue
+		}
+		location := aws.ToString(secondary.Location)
+		locations = append(locations, MappedCodeBuildArtifactLocation{
+			Region: region, Project: projectName, Kind: "SecondaryArtifacts", Bucket: getBucketNameFromCodeBuildLocation(location), Location: location,
+		})
+	}
+
+	if project.LogsConfig != nil && project.LogsConfig.S3Logs != nil && project.LogsConfig.S3Logs.Status == codebuildTypes.LogsConfigStatusTypeEnabled {
+		location := aws.ToString(project.LogsConfig.S3Logs.Location)
+		locations = append(locations, MappedCodeBuildArtifactLocation{
+			Region: region, Project: projectName, Kind: "Logs", Bucket: getBucketNameFromCodeBuildLocation(location), Location: location,
+		})
+	}
+
+	return locations
+}
+
+func mapCodeBuildReportGroupLocation(region string, reportGroup codebuildTypes.ReportGroup) (MappedCodeBuildArtifactLocation, bool) {
+	if reportGroup.ExportConfig == nil || reportGroup.ExportConfig.ExportConfigType != codebuildTypes.ReportExportConfigTypeS3 || reportGroup.ExportConfig.S3Destination == nil {
+		return MappedCodeBuildArtifactLocation{}, false
+	}
+
+	bucket := aws.ToString(reportGroup.ExportConfig.S3Destination.Bucket)
+	path := aws.ToString(reportGroup.ExportConfig.S3Destination.Path)
+	location := bucket
+	if path != "" {
+		location = bucket + "/" + path
+	}
+
+	return MappedCodeBuildArtifactLocation{
+		Region:   region,
+		Project:  aws.ToString(reportGroup.Name),
+		Kind:     "ReportGroup",
+		Bucket:   bucket,
+		Location: location,
+	}, true
+}
+
+func getBucketNameFromCodeBuildLocation(location string) string {
+	// location is either a bare "bucket/key" or a full S3 ARN - strip the
+	// "arn:<partition>:s3:::" prefix regardless of partition so GovCloud/
+	// China-sourced locations resolve to a bucket name too.
+	if idx := strings.Index(location, ":s3:::"); idx != -1 {
+		location = location[idx+len(":s3:::"):]
+	}
+	if idx := strings.Index(location, "/"); idx != -1 {
+		return location[:idx]
+	}
+	return location
+}
+
+// S3BucketNames returns the deduplicated set of bucket names this module
+// discovered. No other module calls it; the s3 module's checks are not run
+// against these buckets automatically.
+func (m *CodeBuildArtifactsModule) S3BucketNames() []string {
+	seen := make(map[string]bool)
+	var buckets []string
+	for _, artifact := range m.MappedArtifactLocations {
+		if artifact.Bucket == "" || seen[artifact.Bucket] {
+			continue
+		}
+		seen[artifact.Bucket] = true
+		buckets = append(buckets, artifact.Bucket)
+	}
+	return buckets
+}
@@ -0,0 +1,315 @@
+package aws
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	codebuildTypes "github.com/aws/aws-sdk-go-v2/service/codebuild/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type CodeBuildBuildsModule struct {
+	CodeBuildClient sdk.AWSCodeBuildClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	MappedBuilds   []MappedCodeBuildBuild
+	CommandCounter internal.CommandCounter
+
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type MappedCodeBuildBuild struct {
+	Region            string
+	Project           string
+	BuildID           string
+	BuildStatus       string
+	Initiator         string
+	SourceVersion     string
+	PlaintextEnvVars  string
+	ReferencedSecrets string
+	CloudWatchLogs    string
+	S3Logs            string
+	ExportedVars      string
+}
+
+func (m *CodeBuildBuildsModule) PrintCodeBuildBuilds(outputDirectory string, verbosity int) {
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "codebuild-builds"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating CodeBuild builds for account %s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+	fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+
+	wg := new(sync.WaitGroup)
+
+	spinnerDone := make(chan bool)
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "tasks")
+
+	dataReceiver := make(chan MappedCodeBuildBuild)
+
+	receiverDone := make(chan bool)
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		m.CommandCounter.Pending++
+		go m.executeChecks(region, wg, dataReceiver)
+	}
+
+	wg.Wait()
+
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Project",
+		"BuildID",
+		"Status",
+		"Initiator",
+		"SourceVersion",
+		"PlaintextEnvVars",
+		"ReferencedSecrets",
+	}
+
+	var tableCols []string
+	if m.AWSTableCols != "" {
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Project",
+			"BuildID",
+			"Status",
+			"Initiator",
+			"SourceVersion",
+			"PlaintextEnvVars",
+			"ReferencedSecrets",
+		}
+	} else {
+		tableCols = []string{
+			"Region",
+			"Project",
+			"BuildID",
+			"Status",
+			"Initiator",
+			"PlaintextEnvVars",
+			"ReferencedSecrets",
+		}
+	}
+
+	for _, build := range m.MappedBuilds {
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				build.Region,
+				build.Project,
+				build.BuildID,
+				build.BuildStatus,
+				build.Initiator,
+				build.SourceVersion,
+				build.PlaintextEnvVars,
+				build.ReferencedSecrets,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		m.writeLoot(o.Table.DirectoryName)
+		fmt.Printf("[%s][%s] %s CodeBuild builds found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)))
+	} else {
+		fmt.Printf("[%s][%s] No CodeBuild builds found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *CodeBuildBuildsModule) Receiver(receiver chan MappedCodeBuildBuild, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.MappedBuilds = append(m.MappedBuilds, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *CodeBuildBuildsModule) executeChecks(r string, wg *sync.WaitGroup, dataReceiver chan MappedCodeBuildBuild) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("codebuild", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		m.CommandCounter.Pending--
+		m.CommandCounter.Executing++
+		m.getBuilds(r, dataReceiver)
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+	}
+}
+
+func (m *CodeBuildBuildsModule) getBuilds(region string, dataReceiver chan MappedCodeBuildBuild) {
+	BuildIDs, err := sdk.CachedCodeBuildListBuilds(m.CodeBuildClient, aws.ToString(m.Caller.Account), region)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	batchSize := 100 // maximum value: https://docs.aws.amazon.com/codebuild/latest/APIReference/API_BatchGetBuilds.html
+	for i := 0; i < len(BuildIDs); i += batchSize {
+		j := i + batchSize
+		if j > len(BuildIDs) {
+			j = len(BuildIDs)
+		}
+
+		Builds, err := sdk.CachedCodeBuildBatchGetBuilds(m.CodeBuildClient, aws.ToString(m.Caller.Account), region, BuildIDs[i:j])
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			return
+		}
+
+		for _, build := range Builds {
+			dataReceiver <- mapCodeBuildBuild(region, build)
+		}
+	}
+}
+
+func mapCodeBuildBuild(region string, build codebuildTypes.Build) MappedCodeBuildBuild {
+	var plaintextVars []string
+	var referencedSecrets []string
+
+	if build.Environment != nil {
+		for _, envVar := range build.Environment.EnvironmentVariables {
+			switch envVar.Type {
+			case codebuildTypes.EnvironmentVariableTypePlaintext:
+				plaintextVars = append(plaintextVars, fmt.Sprintf("%s=%s", aws.ToString(envVar.Name), aws.ToString(envVar.Value)))
+			case codebuildTypes.EnvironmentVariableTypeParameterStore:
+				referencedSecrets = append(referencedSecrets, fmt.Sprintf("ssm:%s", aws.ToString(envVar.Value)))
+			case codebuildTypes.EnvironmentVariableTypeSecretsManager:
+				referencedSecrets = append(referencedSecrets, fmt.Sprintf("secretsmanager:%s", aws.ToString(envVar.Value)))
+			}
+		}
+	}
+
+	var cloudWatchLogs string
+	var s3Logs string
+	if build.Logs != nil {
+		if build.Logs.CloudWatchLogs != nil && build.Logs.CloudWatchLogs.Status == codebuildTypes.LogsConfigStatusTypeEnabled {
+			cloudWatchLogs = aws.ToString(build.Logs.CloudWatchLogs.GroupName)
+		}
+		if build.Logs.S3Logs != nil && build.Logs.S3Logs.Status == codebuildTypes.LogsConfigStatusTypeEnabled {
+			s3Logs = aws.ToString(build.Logs.S3Logs.Location)
+		}
+	}
+
+	var exportedVars []string
+	for _, exported := range build.ExportedEnvironmentVariables {
+		exportedVars = append(exportedVars, fmt.Sprintf("%s=%s", aws.ToString(exported.Name), aws.ToString(exported.Value)))
+	}
+
+	return MappedCodeBuildBuild{
+		Region:            region,
+		Project:           aws.ToString(build.ProjectName),
+		BuildID:           getIDFromCodeBuildBuildArn(aws.ToString(build.Id)),
+		BuildStatus:       string(build.BuildStatus),
+		Initiator:         aws.ToString(build.Initiator),
+		SourceVersion:     aws.ToString(build.SourceVersion),
+		PlaintextEnvVars:  strings.Join(plaintextVars, " , "),
+		ReferencedSecrets: strings.Join(referencedSecrets, " , "),
+		CloudWatchLogs:    cloudWatchLogs,
+		S3Logs:            s3Logs,
+		ExportedVars:      strings.Join(exportedVars, " , "),
+	}
+}
+
+func getIDFromCodeBuildBuildArn(id string) string {
+	tokens := strings.SplitN(id, ":", 2)
+	if len(tokens) != 2 {
+		return id
+	}
+	return tokens[1]
+}
+
+func (m *CodeBuildBuildsModule) writeLoot(outputDirectory string) {
+	path := filepath.Join(outputDirectory, "loot")
+	err := os.MkdirAll(path, os.ModePerm)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+
+	var out string
+	for _, build := range m.MappedBuilds {
+		if build.PlaintextEnvVars == "" && build.ReferencedSecrets == "" && build.CloudWatchLogs == "" && build.S3Logs == "" && build.ExportedVars == "" {
+			continue
+		}
+		out = out + fmt.Sprintf("Project: %s\nBuildID: %s\nInitiator: %s\nPlaintextEnvVars: %s\nReferencedSecrets: %s\nCloudWatchLogs: %s\nS3Logs: %s\nExportedVars: %s\n\n",
+			build.Project, build.BuildID, build.Initiator, build.PlaintextEnvVars, build.ReferencedSecrets, build.CloudWatchLogs, build.S3Logs, build.ExportedVars)
+	}
+
+	filename := filepath.Join(path, "codebuild-builds-SecretsAndEnvVars.txt")
+	err = os.WriteFile(filename, []byte(out), 0644)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+
+	fmt.Printf("[%s][%s] Loot written to [%s]\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), filename)
+}
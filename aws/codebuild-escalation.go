@@ -0,0 +1,204 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/sirupsen/logrus"
+)
+
+type CodeBuildEscalationModule struct {
+	CodeBuildClient sdk.AWSCodeBuildClientInterface
+	IAMClient       sdk.AWSIAMClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	SkipAdminCheck      bool
+	iamSimClient        IamSimulatorModule
+	pmapperMod          PmapperModule
+	pmapperError        error
+	PmapperDataBasePath string
+
+	MappedEscalationPaths []MappedCodeBuildEscalationPath
+	CommandCounter        internal.CommandCounter
+
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type MappedCodeBuildEscalationPath struct {
+	Region      string
+	Project     string
+	ServiceRole string
+	Admin       string
+	CanPrivEsc  string
+}
+
+func (m *CodeBuildEscalationModule) PrintCodeBuildEscalationPaths(outputDirectory string, verbosity int) {
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "codebuild-escalation"
+	localAdminMap := make(map[string]bool)
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Mapping CodeBuild project service roles to IAM privilege-escalation paths for account %s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+	fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+
+	m.pmapperMod, m.pmapperError = InitPmapperGraph(m.Caller, m.AWSProfile, m.Goroutines, m.PmapperDataBasePath)
+	m.iamSimClient = InitIamCommandClient(m.IAMClient, m.Caller, m.AWSProfile, m.Goroutines)
+
+	for _, region := range m.AWSRegions {
+		m.CommandCounter.Total++
+		m.analyzeRegion(region, localAdminMap)
+	}
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Project",
+		"ServiceRole",
+		"Admin",
+		"CanPrivEsc",
+	}
+
+	var tableCols []string
+	if m.AWSTableCols != "" {
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Project",
+			"ServiceRole",
+			"Admin",
+			"CanPrivEsc",
+		}
+	} else {
+		tableCols = []string{
+			"Region",
+			"Project",
+			"ServiceRole",
+			"Admin",
+			"CanPrivEsc",
+		}
+	}
+
+	// Rank so that the projects whose service role grants the caller the
+	// most (admin, then priv-esc) show up first in the table.
+	sortMappedCodeBuildEscalationPaths(m.MappedEscalationPaths)
+
+	for _, path := range m.MappedEscalationPaths {
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				path.Region,
+				path.Project,
+				path.ServiceRole,
+				path.Admin,
+				path.CanPrivEsc,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s][%s] %s CodeBuild service roles analyzed.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)))
+	} else {
+		fmt.Printf("[%s][%s] No CodeBuild projects with a service role found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *CodeBuildEscalationModule) analyzeRegion(region string, localAdminMap map[string]bool) {
+	Projects, err := sdk.CachedCodeBuildListProjects(m.CodeBuildClient, aws.ToString(m.Caller.Account), region)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+	if len(Projects) == 0 {
+		return
+	}
+
+	ProjectDetails, err := sdk.CachedCodeBuildBatchGetProjects(m.CodeBuildClient, aws.ToString(m.Caller.Account), region, Projects)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, project := range ProjectDetails {
+		serviceRole := aws.ToString(project.ServiceRole)
+		if serviceRole == "" {
+			continue
+		}
+
+		path := MappedCodeBuildEscalationPath{
+			Region:      region,
+			Project:     aws.ToString(project.Name),
+			ServiceRole: serviceRole,
+		}
+		if m.pmapperError == nil {
+			path.Admin, path.CanPrivEsc = GetPmapperResults(m.SkipAdminCheck, m.pmapperMod, &serviceRole)
+		} else {
+			path.Admin, path.CanPrivEsc = GetIamSimResult(m.SkipAdminCheck, &serviceRole, m.iamSimClient, localAdminMap)
+		}
+
+		m.MappedEscalationPaths = append(m.MappedEscalationPaths, path)
+	}
+}
+
+func sortMappedCodeBuildEscalationPaths(paths []MappedCodeBuildEscalationPath) {
+	rank := func(p MappedCodeBuildEscalationPath) int {
+		switch {
+		case p.Admin == "YES":
+			return 2
+		case p.CanPrivEsc == "YES":
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	for i := 1; i < len(paths); i++ {
+		for j := i; j > 0 && rank(paths[j]) > rank(paths[j-1]); j-- {
+			paths[j], paths[j-1] = paths[j-1], paths[j]
+		}
+	}
+}
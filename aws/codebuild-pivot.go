@@ -0,0 +1,236 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	codebuildTypes "github.com/aws/aws-sdk-go-v2/service/codebuild/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/sirupsen/logrus"
+)
+
+type CodeBuildPivotModule struct {
+	CodeBuildClient sdk.AWSCodeBuildClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	MappedPivotTargets []MappedCodeBuildPivotTarget
+	CommandCounter     internal.CommandCounter
+
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type MappedCodeBuildPivotTarget struct {
+	Region         string
+	Project        string
+	SourceType     string
+	SourceLocation string
+	AuthType       string
+	CredentialArn  string
+	WebhookURL     string
+	WebhookFilters string
+	VpcID          string
+	Subnets        string
+	SecurityGroups string
+}
+
+func (m *CodeBuildPivotModule) PrintCodeBuildPivotTargets(outputDirectory string, verbosity int) {
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "codebuild-pivot"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating CodeBuild source credentials, webhooks, and VPC attachments for account %s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+	fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+
+	for _, region := range m.AWSRegions {
+		m.CommandCounter.Total++
+		m.analyzeRegion(region)
+	}
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Project",
+		"SourceType",
+		"SourceLocation",
+		"AuthType",
+		"CredentialArn",
+		"WebhookURL",
+		"WebhookFilters",
+		"VpcID",
+		"Subnets",
+		"SecurityGroups",
+	}
+
+	var tableCols []string
+	if m.AWSTableCols != "" {
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Project",
+			"SourceType",
+			"SourceLocation",
+			"AuthType",
+			"CredentialArn",
+			"WebhookURL",
+			"WebhookFilters",
+			"VpcID",
+			"Subnets",
+			"SecurityGroups",
+		}
+	} else {
+		tableCols = []string{
+			"Region",
+			"Project",
+			"SourceType",
+			"AuthType",
+			"WebhookURL",
+			"VpcID",
+		}
+	}
+
+	for _, target := range m.MappedPivotTargets {
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				target.Region,
+				target.Project,
+				target.SourceType,
+				target.SourceLocation,
+				target.AuthType,
+				target.CredentialArn,
+				target.WebhookURL,
+				target.WebhookFilters,
+				target.VpcID,
+				target.Subnets,
+				target.SecurityGroups,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s][%s] %s CodeBuild pivot targets found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)))
+	} else {
+		fmt.Printf("[%s][%s] No CodeBuild pivot targets found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *CodeBuildPivotModule) analyzeRegion(region string) {
+	Projects, err := sdk.CachedCodeBuildListProjects(m.CodeBuildClient, aws.ToString(m.Caller.Account), region)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+	if len(Projects) == 0 {
+		return
+	}
+
+	ProjectDetails, err := sdk.CachedCodeBuildBatchGetProjects(m.CodeBuildClient, aws.ToString(m.Caller.Account), region, Projects)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	credentials, err := sdk.CachedCodeBuildListSourceCredentials(m.CodeBuildClient, aws.ToString(m.Caller.Account), region)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+	credentialAuthTypes := make(map[string]string)
+	for _, credential := range credentials {
+		credentialAuthTypes[aws.ToString(credential.Arn)] = fmt.Sprintf("%s (%s)", credential.AuthType, credential.ServerType)
+	}
+
+	for _, project := range ProjectDetails {
+		sources := []codebuildTypes.ProjectSource{}
+		if project.Source != nil {
+			sources = append(sources, *project.Source)
+		}
+		sources = append(sources, project.SecondarySources...)
+
+		for _, source := range sources {
+			m.MappedPivotTargets = append(m.MappedPivotTargets, mapCodeBuildPivotTarget(region, project, source, credentialAuthTypes))
+		}
+	}
+}
+
+func mapCodeBuildPivotTarget(region string, project codebuildTypes.Project, source codebuildTypes.ProjectSource, credentialAuthTypes map[string]string) MappedCodeBuildPivotTarget {
+	target := MappedCodeBuildPivotTarget{
+		Region:         region,
+		Project:        aws.ToString(project.Name),
+		SourceType:     string(source.Type),
+		SourceLocation: aws.ToString(source.Location),
+	}
+
+	if source.Auth != nil {
+		target.AuthType = string(source.Auth.Type)
+		target.CredentialArn = aws.ToString(source.Auth.Resource)
+		if known, ok := credentialAuthTypes[target.CredentialArn]; ok {
+			target.AuthType = known
+		}
+	}
+
+	if project.Webhook != nil {
+		target.WebhookURL = aws.ToString(project.Webhook.PayloadUrl)
+		var filters []string
+		for _, group := range project.Webhook.FilterGroups {
+			var groupFilters []string
+			for _, filter := range group {
+				groupFilters = append(groupFilters, fmt.Sprintf("%s=%s", filter.Type, aws.ToString(filter.Pattern)))
+			}
+			filters = append(filters, strings.Join(groupFilters, "&&"))
+		}
+		target.WebhookFilters = strings.Join(filters, " , ")
+	}
+
+	if project.VpcConfig != nil {
+		target.VpcID = aws.ToString(project.VpcConfig.VpcId)
+		target.Subnets = strings.Join(project.VpcConfig.Subnets, " , ")
+		target.SecurityGroups = strings.Join(project.VpcConfig.SecurityGroupIds, " , ")
+	}
+
+	return target
+}
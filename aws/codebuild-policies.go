@@ -0,0 +1,318 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/sirupsen/logrus"
+)
+
+type CodeBuildPoliciesModule struct {
+	CodeBuildClient sdk.AWSCodeBuildClientInterface
+
+	Caller        sts.GetCallerIdentityOutput
+	AWSRegions    []string
+	AWSOutputType string
+	AWSTableCols  string
+
+	Goroutines int
+	AWSProfile string
+	WrapTable  bool
+
+	// CodeBuildTrusts holds every finding this module produced. Only this
+	// module's own table reads it; the resource-trusts module does not include
+	// these rows alongside its S3/KMS/SQS resource-policy edges.
+	CodeBuildTrusts []CodeBuildResourcePolicyTrust
+	CommandCounter  internal.CommandCounter
+
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type CodeBuildResourcePolicyTrust struct {
+	Region    string
+	Project   string
+	Effect    string
+	Principal string
+	Action    string
+	Condition string
+	Finding   string
+}
+
+type codeBuildPolicyDocument struct {
+	Version   string                     `json:"Version"`
+	Statement []codeBuildPolicyStatement `json:"Statement"`
+}
+
+type codeBuildPolicyStatement struct {
+	Effect       string          `json:"Effect"`
+	Principal    json.RawMessage `json:"Principal"`
+	NotPrincipal json.RawMessage `json:"NotPrincipal"`
+	Action       json.RawMessage `json:"Action"`
+	NotAction    json.RawMessage `json:"NotAction"`
+	Resource     json.RawMessage `json:"Resource"`
+	Condition    json.RawMessage `json:"Condition"`
+}
+
+func (m *CodeBuildPoliciesModule) PrintCodeBuildPolicies(outputDirectory string, verbosity int) {
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "codebuild-policies"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating CodeBuild resource policies for account %s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+	fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+
+	for _, region := range m.AWSRegions {
+		m.CommandCounter.Total++
+		m.analyzeRegion(region)
+	}
+
+	m.output.Headers = []string{
+		"Account",
+		"Region",
+		"Project",
+		"Effect",
+		"Principal",
+		"Action",
+		"Condition",
+		"Finding",
+	}
+
+	var tableCols []string
+	if m.AWSTableCols != "" {
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Region",
+			"Project",
+			"Effect",
+			"Principal",
+			"Action",
+			"Condition",
+			"Finding",
+		}
+	} else {
+		tableCols = []string{
+			"Region",
+			"Project",
+			"Principal",
+			"Finding",
+		}
+	}
+
+	for _, trust := range m.CodeBuildTrusts {
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				trust.Region,
+				trust.Project,
+				trust.Effect,
+				trust.Principal,
+				trust.Action,
+				trust.Condition,
+				trust.Finding,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s][%s] %s CodeBuild resource-policy findings found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)))
+	} else {
+		fmt.Printf("[%s][%s] No CodeBuild resource-policy findings found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+func (m *CodeBuildPoliciesModule) analyzeRegion(region string) {
+	Projects, err := sdk.CachedCodeBuildListProjects(m.CodeBuildClient, aws.ToString(m.Caller.Account), region)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, project := range Projects {
+		policy, err := sdk.CachedCodeBuildGetResourcePolicy(m.CodeBuildClient, aws.ToString(m.Caller.Account), region, project)
+		if err != nil || policy == "" {
+			continue
+		}
+
+		trusts, err := parseCodeBuildResourcePolicy(region, project, policy, aws.ToString(m.Caller.Account))
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+
+		m.CodeBuildTrusts = append(m.CodeBuildTrusts, trusts...)
+	}
+}
+
+func parseCodeBuildResourcePolicy(region string, project string, policy string, callerAccount string) ([]CodeBuildResourcePolicyTrust, error) {
+	var doc codeBuildPolicyDocument
+	if err := json.Unmarshal([]byte(policy), &doc); err != nil {
+		return nil, fmt.Errorf("parsing CodeBuild resource policy for %s: %s", project, err)
+	}
+
+	var trusts []CodeBuildResourcePolicyTrust
+	for _, statement := range doc.Statement {
+		action := rawPolicyValueToString(statement.Action)
+		condition := rawPolicyValueToString(statement.Condition)
+
+		if len(statement.NotPrincipal) > 0 {
+			trusts = append(trusts, CodeBuildResourcePolicyTrust{
+				Region: region, Project: project, Effect: statement.Effect,
+				Principal: rawPolicyValueToString(statement.NotPrincipal), Action: action, Condition: condition,
+				Finding: "NotPrincipal used - grants to everyone except the listed principal(s)",
+			})
+		}
+		if len(statement.NotAction) > 0 {
+			trusts = append(trusts, CodeBuildResourcePolicyTrust{
+				Region: region, Project: project, Effect: statement.Effect,
+				Principal: rawPolicyValueToString(statement.Principal), Action: rawPolicyValueToString(statement.NotAction), Condition: condition,
+				Finding: "NotAction used - grants every action except the listed action(s)",
+			})
+		}
+
+		for _, principal := range extractCodeBuildPrincipals(statement.Principal) {
+			switch {
+			case principal == "*":
+				finding := "Wildcard principal (\"AWS\":\"*\")"
+				if strings.Contains(condition, "aws:PrincipalOrgID") {
+					finding = finding + " scoped by aws:PrincipalOrgID condition"
+				}
+				trusts = append(trusts, CodeBuildResourcePolicyTrust{
+					Region: region, Project: project, Effect: statement.Effect,
+					Principal: principal, Action: action, Condition: condition, Finding: finding,
+				})
+			case strings.HasSuffix(principal, ".amazonaws.com"):
+				trusts = append(trusts, CodeBuildResourcePolicyTrust{
+					Region: region, Project: project, Effect: statement.Effect,
+					Principal: principal, Action: action, Condition: condition, Finding: "Service principal",
+				})
+			case isIAMPrincipalARN(principal) || isNumericAWSAccountID(principal):
+				accountID := extractAccountIDFromPrincipal(principal)
+				if accountID != "" && accountID != callerAccount {
+					trusts = append(trusts, CodeBuildResourcePolicyTrust{
+						Region: region, Project: project, Effect: statement.Effect,
+						Principal: principal, Action: action, Condition: condition, Finding: "External account principal",
+					})
+				}
+			}
+		}
+	}
+
+	return trusts, nil
+}
+
+func extractCodeBuildPrincipals(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var asString string
+	if err := json.Unmarshal(raw, &asString); err == nil {
+		return []string{asString}
+	}
+
+	var asMap map[string]interface{}
+	if err := json.Unmarshal(raw, &asMap); err == nil {
+		var principals []string
+		for _, value := range asMap {
+			switch v := value.(type) {
+			case string:
+				principals = append(principals, v)
+			case []interface{}:
+				for _, item := range v {
+					if s, ok := item.(string); ok {
+						principals = append(principals, s)
+					}
+				}
+			}
+		}
+		return principals
+	}
+
+	return nil
+}
+
+func rawPolicyValueToString(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	var asString string
+	if err := json.Unmarshal(raw, &asString); err == nil {
+		return asString
+	}
+
+	var asSlice []string
+	if err := json.Unmarshal(raw, &asSlice); err == nil {
+		return strings.Join(asSlice, " , ")
+	}
+
+	return string(raw)
+}
+
+// isIAMPrincipalARN reports whether principal is an IAM ARN, regardless of
+// partition ("arn:aws:iam::...", "arn:aws-us-gov:iam::...",
+// "arn:aws-cn:iam::...").
+func isIAMPrincipalARN(principal string) bool {
+	return strings.HasPrefix(principal, "arn:") && strings.Contains(principal, ":iam::")
+}
+
+func isNumericAWSAccountID(s string) bool {
+	if len(s) != 12 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func extractAccountIDFromPrincipal(principal string) string {
+	if isNumericAWSAccountID(principal) {
+		return principal
+	}
+	parts := strings.Split(principal, ":")
+	if len(parts) >= 5 && parts[0] == "arn" {
+		return parts[4]
+	}
+	return ""
+}
@@ -0,0 +1,372 @@
+package aws
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+// ECSSecretsModule walks every ECS task definition's container definitions
+// and resolves the SSM Parameter Store/Secrets Manager references backing
+// each container's Secrets entries, so an analyst can see what a task role
+// can actually read without pulling the value by hand. It shares the
+// cluster/task-definition enumeration shape of ECSTasksModule rather than
+// reusing it directly, since this module walks task definitions once
+// (regardless of how many running tasks reference them) instead of once per
+// task.
+type ECSSecretsModule struct {
+	ECSClient            sdk.AWSECSClientInterface
+	SSMClient            sdk.AWSSSMClientInterface
+	SecretsManagerClient sdk.AWSSecretsManagerClientInterface
+	IAMClient            sdk.AWSIAMClientInterface
+
+	Caller              sts.GetCallerIdentityOutput
+	AWSRegions          []string
+	AWSOutputType       string
+	AWSTableCols        string
+	PmapperDataBasePath string
+
+	AWSProfile     string
+	Goroutines     int
+	SkipAdminCheck bool
+	WrapTable      bool
+	NoResolve      bool
+	pmapperMod     PmapperModule
+	pmapperError   error
+	iamSimClient   IamSimulatorModule
+
+	MappedECSSecrets []MappedECSSecret
+	CommandCounter   internal.CommandCounter
+
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+// MappedECSSecret is one container-definition Secrets entry, resolved (or
+// left unresolved, when --no-resolve is set) back to a human-readable value.
+// Admin/CanPrivEsc reuse the same blanket task-role risk signal the other
+// ECS modules compute - this snapshot's IamSimulatorModule doesn't expose a
+// way to simulate read access scoped to one specific secret ARN, so these
+// columns say "is this task role dangerous in general", not "can this role
+// read this exact secret".
+type MappedECSSecret struct {
+	Cluster            string
+	TaskDefinitionName string
+	ContainerName      string
+	EnvVarName         string
+	SecretARN          string
+	ResolvedValue      string
+	ResolveError       string
+	Role               string
+	Admin              string
+	CanPrivEsc         string
+}
+
+func (m *ECSSecretsModule) ECSSecrets(outputDirectory string, verbosity int) {
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "ecs-secrets"
+	localAdminMap := make(map[string]bool)
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating ECS task secrets in all regions for account %s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+	if m.NoResolve {
+		fmt.Printf("[%s][%s] --no-resolve set - listing secret references without reading their values.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+	m.pmapperMod, m.pmapperError = InitPmapperGraph(m.Caller, m.AWSProfile, m.Goroutines, m.PmapperDataBasePath)
+	m.iamSimClient = InitIamCommandClient(m.IAMClient, m.Caller, m.AWSProfile, m.Goroutines)
+
+	wg := new(sync.WaitGroup)
+
+	spinnerDone := make(chan bool)
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "tasks")
+
+	dataReceiver := make(chan MappedECSSecret)
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		m.CommandCounter.Pending++
+		go m.executeChecks(region, wg, dataReceiver)
+	}
+
+	wg.Wait()
+
+	if m.pmapperError == nil {
+		for i := range m.MappedECSSecrets {
+			m.MappedECSSecrets[i].Admin, m.MappedECSSecrets[i].CanPrivEsc = GetPmapperResults(m.SkipAdminCheck, m.pmapperMod, &m.MappedECSSecrets[i].Role)
+		}
+	} else {
+		for i := range m.MappedECSSecrets {
+			m.MappedECSSecrets[i].Admin, m.MappedECSSecrets[i].CanPrivEsc = GetIamSimResult(m.SkipAdminCheck, &m.MappedECSSecrets[i].Role, m.iamSimClient, localAdminMap)
+		}
+	}
+
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	m.printECSSecretsData(outputDirectory, verbosity)
+}
+
+func (m *ECSSecretsModule) Receiver(receiver chan MappedECSSecret, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.MappedECSSecrets = append(m.MappedECSSecrets, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *ECSSecretsModule) executeChecks(r string, wg *sync.WaitGroup, dataReceiver chan MappedECSSecret) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("ecs", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+		m.CommandCounter.Total++
+		m.CommandCounter.Pending--
+		m.CommandCounter.Executing++
+		m.getListClusters(r, dataReceiver)
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+	}
+}
+
+func (m *ECSSecretsModule) getListClusters(region string, dataReceiver chan MappedECSSecret) {
+	ClusterArns, err := sdk.CachedECSListClusters(m.ECSClient, aws.ToString(m.Caller.Account), region)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, clusterARN := range ClusterArns {
+		m.getListTasks(clusterARN, region, dataReceiver)
+	}
+}
+
+func (m *ECSSecretsModule) getListTasks(clusterARN string, region string, dataReceiver chan MappedECSSecret) {
+	TaskArns, err := sdk.CachedECSListTasks(m.ECSClient, aws.ToString(m.Caller.Account), region, clusterARN)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	// Several running tasks commonly share one task definition revision, so
+	// resolving the same Secrets entries repeatedly per task would just
+	// mean redundant SSM/Secrets Manager calls; resolve per unique task
+	// definition ARN instead.
+	seen := make(map[string]bool)
+
+	batchSize := 100 // maximum value: https://docs.aws.amazon.com/AmazonECS/latest/APIReference/API_DescribeTasks.html#API_DescribeTasks_RequestSyntax
+	for i := 0; i < len(TaskArns); i += batchSize {
+		j := i + batchSize
+		if j > len(TaskArns) {
+			j = len(TaskArns)
+		}
+
+		Tasks, err := sdk.CachedECSDescribeTasks(m.ECSClient, aws.ToString(m.Caller.Account), region, clusterARN, TaskArns[i:j])
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+
+		for _, task := range Tasks {
+			taskDefinitionArn := aws.ToString(task.TaskDefinitionArn)
+			if seen[taskDefinitionArn] {
+				continue
+			}
+			seen[taskDefinitionArn] = true
+
+			taskDefinition, err := sdk.CachedECSDescribeTaskDefinition(m.ECSClient, aws.ToString(m.Caller.Account), region, taskDefinitionArn)
+			if err != nil {
+				m.modLog.Error(err.Error())
+				m.CommandCounter.Error++
+				continue
+			}
+
+			m.loadSecretsData(clusterARN, taskDefinition, region, dataReceiver)
+		}
+	}
+}
+
+func (m *ECSSecretsModule) loadSecretsData(clusterARN string, taskDefinition types.TaskDefinition, region string, dataReceiver chan MappedECSSecret) {
+	taskDefinitionName := getNameFromARN(aws.ToString(taskDefinition.TaskDefinitionArn))
+	role := getTaskRole(taskDefinition)
+
+	for _, container := range taskDefinition.ContainerDefinitions {
+		containerName := aws.ToString(container.Name)
+
+		for _, secret := range container.Secrets {
+			valueFrom := aws.ToString(secret.ValueFrom)
+			resolvedValue, resolveErr := m.resolveSecret(valueFrom, region)
+
+			dataReceiver <- MappedECSSecret{
+				Cluster:            getNameFromARN(clusterARN),
+				TaskDefinitionName: taskDefinitionName,
+				ContainerName:      containerName,
+				EnvVarName:         aws.ToString(secret.Name),
+				SecretARN:          valueFrom,
+				ResolvedValue:      resolvedValue,
+				ResolveError:       resolveErr,
+				Role:               role,
+			}
+		}
+	}
+}
+
+// resolveSecret reads the value valueFrom (a container's Secrets[].ValueFrom
+// - either a Secrets Manager ARN or an SSM Parameter Store name/ARN, per
+// https://docs.aws.amazon.com/AmazonECS/latest/developerguide/specifying-sensitive-data.html)
+// actually points to. It returns the resolved value and an empty error
+// string on success, or an empty value and the error's message on failure -
+// matching the "ResolvedValue/error" column the request asked for rather
+// than a Go error, since this is meant to land directly in the output
+// table. --no-resolve skips the API call entirely and returns both empty,
+// leaving SecretARN as the only reference an analyst needs to go look the
+// value up themselves.
+func (m *ECSSecretsModule) resolveSecret(valueFrom string, region string) (resolvedValue string, resolveErr string) {
+	if m.NoResolve || valueFrom == "" {
+		return "", ""
+	}
+
+	if strings.Contains(valueFrom, ":secretsmanager:") {
+		value, err := sdk.CachedSecretsManagerGetSecretValue(m.SecretsManagerClient, aws.ToString(m.Caller.Account), region, valueFrom)
+		if err != nil {
+			return "", err.Error()
+		}
+		return value, ""
+	}
+
+	value, err := sdk.CachedSSMGetParameter(m.SSMClient, aws.ToString(m.Caller.Account), region, valueFrom)
+	if err != nil {
+		return "", err.Error()
+	}
+	return value, ""
+}
+
+func (m *ECSSecretsModule) printECSSecretsData(outputDirectory string, verbosity int) {
+	m.output.Headers = []string{
+		"Account",
+		"Cluster",
+		"TaskDefinition",
+		"ContainerName",
+		"EnvVarName",
+		"SecretARN",
+		"ResolvedValue",
+		"Error",
+		"RoleArn",
+		"IsAdminRole?",
+		"CanPrivEscToAdmin?",
+	}
+
+	var tableCols []string
+	if m.AWSTableCols != "" {
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Cluster",
+			"TaskDefinition",
+			"ContainerName",
+			"EnvVarName",
+			"SecretARN",
+			"ResolvedValue",
+			"Error",
+			"RoleArn",
+			"IsAdminRole?",
+			"CanPrivEscToAdmin?",
+		}
+	} else {
+		tableCols = []string{
+			"Cluster",
+			"TaskDefinition",
+			"ContainerName",
+			"EnvVarName",
+			"SecretARN",
+			"ResolvedValue",
+			"RoleArn",
+			"IsAdminRole?",
+			"CanPrivEscToAdmin?",
+		}
+	}
+
+	if m.pmapperError != nil {
+		sharedLogger.Errorf("%s - %s - No pmapper data found for this account. Skipping the pmapper column in the output table.", m.output.CallingModule, m.AWSProfile)
+		tableCols = removeStringFromSlice(tableCols, "CanPrivEscToAdmin?")
+	}
+
+	for _, secret := range m.MappedECSSecrets {
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				secret.Cluster,
+				secret.TaskDefinitionName,
+				secret.ContainerName,
+				secret.EnvVarName,
+				secret.SecretARN,
+				secret.ResolvedValue,
+				secret.ResolveError,
+				secret.Role,
+				secret.Admin,
+				secret.CanPrivEsc,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s][%s] %s ECS task secrets found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)))
+	} else {
+		fmt.Printf("[%s][%s] No ECS task secrets found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
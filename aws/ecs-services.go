@@ -0,0 +1,506 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/bishopfox/awsservicemap"
+	"github.com/sirupsen/logrus"
+)
+
+type ECSServicesModule struct {
+	ECSClient   sdk.AWSECSClientInterface
+	ELBv2Client *elasticloadbalancingv2.Client
+	IAMClient   sdk.AWSIAMClientInterface
+
+	Caller              sts.GetCallerIdentityOutput
+	AWSRegions          []string
+	AWSOutputType       string
+	AWSTableCols        string
+	PmapperDataBasePath string
+
+	AWSProfile     string
+	Goroutines     int
+	SkipAdminCheck bool
+	WrapTable      bool
+	pmapperMod     PmapperModule
+	pmapperError   error
+	iamSimClient   IamSimulatorModule
+
+	MappedECSServices []MappedECSService
+	CommandCounter    internal.CommandCounter
+
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type MappedECSService struct {
+	Cluster            string
+	ServiceName        string
+	DesiredCount       string
+	RunningCount       string
+	LaunchType         string
+	TaskDefinitionName string
+	Region             string
+	AssignPublicIP     string
+	// LoadBalancers is every "dnsName (hostHeaderPatterns)" entry this
+	// service is registered behind, pipe-separated - usually one ALB/NLB,
+	// but a service can front more than one target group.
+	LoadBalancers string
+	Role          string
+	Admin         string
+	CanPrivEsc    string
+}
+
+func (m *ECSServicesModule) ECSServices(outputDirectory string, verbosity int) {
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "ecs-services"
+	localAdminMap := make(map[string]bool)
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+	if m.AWSProfile == "" {
+		m.AWSProfile = internal.BuildAWSPath(m.Caller)
+	}
+
+	fmt.Printf("[%s][%s] Enumerating ECS services in all regions for account %s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
+	m.pmapperMod, m.pmapperError = InitPmapperGraph(m.Caller, m.AWSProfile, m.Goroutines, m.PmapperDataBasePath)
+	m.iamSimClient = InitIamCommandClient(m.IAMClient, m.Caller, m.AWSProfile, m.Goroutines)
+
+	fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
+
+	wg := new(sync.WaitGroup)
+
+	spinnerDone := make(chan bool)
+	go internal.SpinUntil(m.output.CallingModule, &m.CommandCounter, spinnerDone, "services")
+
+	dataReceiver := make(chan MappedECSService)
+
+	// Create a channel to signal to stop
+	receiverDone := make(chan bool)
+
+	go m.Receiver(dataReceiver, receiverDone)
+
+	for _, region := range m.AWSRegions {
+		wg.Add(1)
+		m.CommandCounter.Pending++
+		go m.executeChecks(region, wg, dataReceiver)
+
+	}
+
+	wg.Wait()
+
+	// Perform role analysis
+	if m.pmapperError == nil {
+		for i := range m.MappedECSServices {
+			m.MappedECSServices[i].Admin, m.MappedECSServices[i].CanPrivEsc = GetPmapperResults(m.SkipAdminCheck, m.pmapperMod, &m.MappedECSServices[i].Role)
+		}
+	} else {
+		for i := range m.MappedECSServices {
+			m.MappedECSServices[i].Admin, m.MappedECSServices[i].CanPrivEsc = GetIamSimResult(m.SkipAdminCheck, &m.MappedECSServices[i].Role, m.iamSimClient, localAdminMap)
+		}
+	}
+
+	spinnerDone <- true
+	<-spinnerDone
+	receiverDone <- true
+	<-receiverDone
+
+	m.printECSServiceData(outputDirectory, dataReceiver, verbosity)
+
+}
+
+func (m *ECSServicesModule) Receiver(receiver chan MappedECSService, receiverDone chan bool) {
+	defer close(receiverDone)
+	for {
+		select {
+		case data := <-receiver:
+			m.MappedECSServices = append(m.MappedECSServices, data)
+		case <-receiverDone:
+			receiverDone <- true
+			return
+		}
+	}
+}
+
+func (m *ECSServicesModule) printECSServiceData(outputDirectory string, dataReceiver chan MappedECSService, verbosity int) {
+	m.output.Headers = []string{
+		"Account",
+		"Cluster",
+		"ServiceName",
+		"DesiredCount",
+		"RunningCount",
+		"LaunchType",
+		"TaskDefinition",
+		"AssignPublicIP",
+		"LoadBalancers",
+		"RoleArn",
+		"IsAdminRole?",
+		"CanPrivEscToAdmin?",
+	}
+
+	var tableCols []string
+	if m.AWSTableCols != "" {
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
+		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
+		tableCols = strings.Split(m.AWSTableCols, ",")
+	} else if m.AWSOutputType == "wide" {
+		tableCols = []string{
+			"Account",
+			"Cluster",
+			"ServiceName",
+			"DesiredCount",
+			"RunningCount",
+			"LaunchType",
+			"TaskDefinition",
+			"AssignPublicIP",
+			"LoadBalancers",
+			"RoleArn",
+			"IsAdminRole?",
+			"CanPrivEscToAdmin?",
+		}
+	} else {
+		tableCols = []string{
+			"Cluster",
+			"ServiceName",
+			"DesiredCount",
+			"RunningCount",
+			"LaunchType",
+			"LoadBalancers",
+			"RoleArn",
+			"IsAdminRole?",
+			"CanPrivEscToAdmin?",
+		}
+	}
+
+	// Remove the pmapper row if there is no pmapper data
+	if m.pmapperError != nil {
+		sharedLogger.Errorf("%s - %s - No pmapper data found for this account. Skipping the pmapper column in the output table.", m.output.CallingModule, m.AWSProfile)
+		tableCols = removeStringFromSlice(tableCols, "CanPrivEscToAdmin?")
+	}
+
+	for _, ecsService := range m.MappedECSServices {
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				aws.ToString(m.Caller.Account),
+				ecsService.Cluster,
+				ecsService.ServiceName,
+				ecsService.DesiredCount,
+				ecsService.RunningCount,
+				ecsService.LaunchType,
+				ecsService.TaskDefinitionName,
+				ecsService.AssignPublicIP,
+				ecsService.LoadBalancers,
+				ecsService.Role,
+				ecsService.Admin,
+				ecsService.CanPrivEsc,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: tableCols,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.AWSProfile
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		m.writeLoot(o.Table.DirectoryName)
+		fmt.Printf("[%s][%s] %s ECS services found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)))
+
+	} else {
+		fmt.Printf("[%s][%s] No ECS services found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	}
+}
+
+// writeLoot writes ecs-services-endpoints.txt, one line per distinct ALB/NLB
+// DNS name these services sit behind, alongside the host-header patterns its
+// listener rules route on - the entry points an operator would hit to reach
+// an ECS workload, complementing the task-level IPs ecs-tasks' loot already
+// covers. Internal and internet-facing load balancers are both listed.
+func (m *ECSServicesModule) writeLoot(outputDirectory string) {
+	path := filepath.Join(outputDirectory, "loot")
+	err := os.MkdirAll(path, os.ModePerm)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+
+	var endpoints string
+	seen := make(map[string]bool)
+	for _, service := range m.MappedECSServices {
+		for _, entry := range strings.Split(service.LoadBalancers, "|") {
+			if entry == "" || seen[entry] {
+				continue
+			}
+			seen[entry] = true
+			endpoints = endpoints + fmt.Sprintln(entry)
+		}
+	}
+
+	if endpoints == "" {
+		return
+	}
+
+	f := filepath.Join(path, "ecs-services-endpoints.txt")
+	if err := os.WriteFile(f, []byte(endpoints), 0644); err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	} else {
+		fmt.Printf("[%s][%s] Loot written to [%s]\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), f)
+	}
+}
+
+func (m *ECSServicesModule) executeChecks(r string, wg *sync.WaitGroup, dataReceiver chan MappedECSService) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+	res, err := servicemap.IsServiceInRegion("ecs", r)
+	if err != nil {
+		m.modLog.Error(err)
+	}
+	if res {
+
+		m.CommandCounter.Total++
+		m.CommandCounter.Pending--
+		m.CommandCounter.Executing++
+		m.getListClusters(r, dataReceiver)
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+	}
+}
+
+func (m *ECSServicesModule) getListClusters(region string, dataReceiver chan MappedECSService) {
+	ClusterArns, err := sdk.CachedECSListClusters(m.ECSClient, aws.ToString(m.Caller.Account), region)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, clusterARN := range ClusterArns {
+		m.getListServices(clusterARN, region, dataReceiver)
+	}
+}
+
+func (m *ECSServicesModule) getListServices(clusterARN string, region string, dataReceiver chan MappedECSService) {
+	ServiceArns, err := sdk.CachedECSListServices(m.ECSClient, aws.ToString(m.Caller.Account), region, clusterARN)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	batchSize := 10 // maximum value: https://docs.aws.amazon.com/AmazonECS/latest/APIReference/API_DescribeServices.html#API_DescribeServices_RequestSyntax
+	for i := 0; i < len(ServiceArns); i += batchSize {
+		j := i + batchSize
+		if j > len(ServiceArns) {
+			j = len(ServiceArns)
+		}
+
+		m.loadServicesData(clusterARN, ServiceArns[i:j], region, dataReceiver)
+	}
+}
+
+func (m *ECSServicesModule) loadServicesData(clusterARN string, serviceARNs []string, region string, dataReceiver chan MappedECSService) {
+	if len(serviceARNs) == 0 {
+		return
+	}
+
+	Services, err := sdk.CachedECSDescribeServices(m.ECSClient, aws.ToString(m.Caller.Account), region, clusterARN, serviceARNs)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, service := range Services {
+		taskDefinition, err := sdk.CachedECSDescribeTaskDefinition(m.ECSClient, aws.ToString(m.Caller.Account), region, aws.ToString(service.TaskDefinition))
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			return
+		}
+
+		loadBalancers, err := m.resolveLoadBalancers(service, region)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		}
+
+		mappedService := MappedECSService{
+			Cluster:            getNameFromARN(clusterARN),
+			ServiceName:        aws.ToString(service.ServiceName),
+			DesiredCount:       strconv.Itoa(int(service.DesiredCount)),
+			RunningCount:       strconv.Itoa(int(service.RunningCount)),
+			LaunchType:         string(service.LaunchType),
+			TaskDefinitionName: getNameFromARN(aws.ToString(service.TaskDefinition)),
+			Region:             region,
+			AssignPublicIP:     getAssignPublicIPFromECSService(service),
+			LoadBalancers:      strings.Join(loadBalancers, "|"),
+			Role:               getTaskRole(taskDefinition),
+		}
+
+		dataReceiver <- mappedService
+	}
+}
+
+func getAssignPublicIPFromECSService(service types.Service) string {
+	if service.NetworkConfiguration == nil || service.NetworkConfiguration.AwsvpcConfiguration == nil {
+		return ""
+	}
+	return string(service.NetworkConfiguration.AwsvpcConfiguration.AssignPublicIp)
+}
+
+// resolveLoadBalancers walks a service's target groups to their ALBs/NLBs
+// and those load balancers' listener rules, mirroring how ECS's own
+// service-discovery integration resolves services -> targets -> ELBs. Each
+// entry returned is "dnsName (hostHeaderPattern[,hostHeaderPattern...])",
+// or just "dnsName" when no listener rule routes on a host header.
+func (m *ECSServicesModule) resolveLoadBalancers(service types.Service, region string) ([]string, error) {
+	var targetGroupARNs []string
+	for _, lb := range service.LoadBalancers {
+		if arn := aws.ToString(lb.TargetGroupArn); arn != "" {
+			targetGroupARNs = append(targetGroupARNs, arn)
+		}
+	}
+
+	if len(targetGroupARNs) == 0 {
+		return nil, nil
+	}
+
+	TargetGroups, err := m.ELBv2Client.DescribeTargetGroups(
+		context.TODO(),
+		&elasticloadbalancingv2.DescribeTargetGroupsInput{
+			TargetGroupArns: targetGroupARNs,
+		},
+		func(o *elasticloadbalancingv2.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("describing target groups: %s", err)
+	}
+
+	var loadBalancerARNs []string
+	seenLoadBalancerARN := make(map[string]bool)
+	for _, tg := range TargetGroups.TargetGroups {
+		for _, lbARN := range tg.LoadBalancerArns {
+			if seenLoadBalancerARN[lbARN] {
+				continue
+			}
+			seenLoadBalancerARN[lbARN] = true
+			loadBalancerARNs = append(loadBalancerARNs, lbARN)
+		}
+	}
+
+	if len(loadBalancerARNs) == 0 {
+		return nil, nil
+	}
+
+	LoadBalancers, err := m.ELBv2Client.DescribeLoadBalancers(
+		context.TODO(),
+		&elasticloadbalancingv2.DescribeLoadBalancersInput{
+			LoadBalancerArns: loadBalancerARNs,
+		},
+		func(o *elasticloadbalancingv2.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("describing load balancers: %s", err)
+	}
+
+	var entries []string
+	for _, lb := range LoadBalancers.LoadBalancers {
+		dnsName := aws.ToString(lb.DNSName)
+		if dnsName == "" {
+			continue
+		}
+
+		hostPatterns, err := m.getHostHeaderPatterns(aws.ToString(lb.LoadBalancerArn), region)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		}
+
+		if len(hostPatterns) > 0 {
+			entries = append(entries, fmt.Sprintf("%s (%s)", dnsName, strings.Join(hostPatterns, ",")))
+		} else {
+			entries = append(entries, dnsName)
+		}
+	}
+
+	return entries, nil
+}
+
+// getHostHeaderPatterns returns every host-header condition value across
+// every listener rule on a load balancer, so a reachable ALB can be
+// narrowed down to the Host header an operator actually needs to send.
+func (m *ECSServicesModule) getHostHeaderPatterns(loadBalancerARN string, region string) ([]string, error) {
+	Listeners, err := m.ELBv2Client.DescribeListeners(
+		context.TODO(),
+		&elasticloadbalancingv2.DescribeListenersInput{
+			LoadBalancerArn: &loadBalancerARN,
+		},
+		func(o *elasticloadbalancingv2.Options) {
+			o.Region = region
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("describing listeners: %s", err)
+	}
+
+	var patterns []string
+	for _, listener := range Listeners.Listeners {
+		Rules, err := m.ELBv2Client.DescribeRules(
+			context.TODO(),
+			&elasticloadbalancingv2.DescribeRulesInput{
+				ListenerArn: listener.ListenerArn,
+			},
+			func(o *elasticloadbalancingv2.Options) {
+				o.Region = region
+			},
+		)
+		if err != nil {
+			return patterns, fmt.Errorf("describing rules: %s", err)
+		}
+
+		for _, rule := range Rules.Rules {
+			for _, condition := range rule.Conditions {
+				if aws.ToString(condition.Field) != "host-header" || condition.HostHeaderConfig == nil {
+					continue
+				}
+				patterns = append(patterns, condition.HostHeaderConfig.Values...)
+			}
+		}
+	}
+
+	return patterns, nil
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/BishopFox/cloudfox/aws/sdk"
 	"github.com/BishopFox/cloudfox/internal"
 	"github.com/aws/aws-sdk-go-v2/aws"
+	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
 	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
@@ -53,11 +54,39 @@ type MappedECSTask struct {
 	ContainerName         string
 	LaunchType            string
 	ID                    string
+	Region                string
 	ExternalIP            string
 	PrivateIP             string
 	Role                  string
 	Admin                 string
 	CanPrivEsc            string
+	// ExecuteCommandEnabled and ExecAgentStatus are ECS Exec attack-surface
+	// fields - EnableExecuteCommand is a task-level setting, while the
+	// managed agent's status (RUNNING/STOPPED/PENDING, from the first
+	// container carrying an ExecuteCommandAgent) says whether `aws ecs
+	// execute-command` would actually succeed against this task right now.
+	ExecuteCommandEnabled string
+	ExecAgentStatus       string
+	// HostInstanceID through HostInstanceProfile describe the EC2 instance
+	// backing an EC2-launch-type task's container instance - left blank for
+	// Fargate tasks, which have no host to escape to. HostRole, HostAdmin,
+	// and HostCanPrivEsc mirror Role/Admin/CanPrivEsc but for the host's
+	// instance profile rather than the task role, since a compromised
+	// container on an EC2-backed task can reach the instance metadata
+	// service and steal the host's role, not just the task's. HostRole is
+	// the instance profile ARN itself rather than its underlying role ARN -
+	// resolving one to the other needs an IAM GetInstanceProfile call this
+	// module doesn't otherwise make, and the profile ARN is enough for
+	// GetPmapperResults/GetIamSimResult to key off of.
+	HostInstanceID       string
+	HostPublicIP         string
+	HostPrivateIP        string
+	HostAvailabilityZone string
+	HostSecurityGroups   string
+	HostInstanceProfile  string
+	HostRole             string
+	HostAdmin            string
+	HostCanPrivEsc       string
 }
 
 func (m *ECSTasksModule) ECSTasks(outputDirectory string, verbosity int) {
@@ -111,10 +140,16 @@ func (m *ECSTasksModule) ECSTasks(outputDirectory string, verbosity int) {
 	if m.pmapperError == nil {
 		for i := range m.MappedECSTasks {
 			m.MappedECSTasks[i].Admin, m.MappedECSTasks[i].CanPrivEsc = GetPmapperResults(m.SkipAdminCheck, m.pmapperMod, &m.MappedECSTasks[i].Role)
+			if m.MappedECSTasks[i].HostRole != "" {
+				m.MappedECSTasks[i].HostAdmin, m.MappedECSTasks[i].HostCanPrivEsc = GetPmapperResults(m.SkipAdminCheck, m.pmapperMod, &m.MappedECSTasks[i].HostRole)
+			}
 		}
 	} else {
 		for i := range m.MappedECSTasks {
 			m.MappedECSTasks[i].Admin, m.MappedECSTasks[i].CanPrivEsc = GetIamSimResult(m.SkipAdminCheck, &m.MappedECSTasks[i].Role, m.iamSimClient, localAdminMap)
+			if m.MappedECSTasks[i].HostRole != "" {
+				m.MappedECSTasks[i].HostAdmin, m.MappedECSTasks[i].HostCanPrivEsc = GetIamSimResult(m.SkipAdminCheck, &m.MappedECSTasks[i].HostRole, m.iamSimClient, localAdminMap)
+			}
 		}
 	}
 
@@ -154,6 +189,16 @@ func (m *ECSTasksModule) printECSTaskData(outputDirectory string, dataReceiver c
 		"RoleArn",
 		"IsAdminRole?",
 		"CanPrivEscToAdmin?",
+		"ExecEnabled",
+		"ExecAgentStatus",
+		"HostInstanceID",
+		"HostPublicIP",
+		"HostPrivateIP",
+		"HostAZ",
+		"HostSecurityGroups",
+		"HostInstanceProfile",
+		"IsHostRoleAdmin?",
+		"CanHostRolePrivEscToAdmin?",
 	}
 
 	// If the user specified table columns, use those.
@@ -181,6 +226,16 @@ func (m *ECSTasksModule) printECSTaskData(outputDirectory string, dataReceiver c
 			"RoleArn",
 			"IsAdminRole?",
 			"CanPrivEscToAdmin?",
+			"ExecEnabled",
+			"ExecAgentStatus",
+			"HostInstanceID",
+			"HostPublicIP",
+			"HostPrivateIP",
+			"HostAZ",
+			"HostSecurityGroups",
+			"HostInstanceProfile",
+			"IsHostRoleAdmin?",
+			"CanHostRolePrivEscToAdmin?",
 		}
 		// Otherwise, use the default columns.
 	} else {
@@ -194,6 +249,8 @@ func (m *ECSTasksModule) printECSTaskData(outputDirectory string, dataReceiver c
 			"RoleArn",
 			"IsAdminRole?",
 			"CanPrivEscToAdmin?",
+			"ExecEnabled",
+			"HostInstanceID",
 		}
 	}
 
@@ -201,6 +258,7 @@ func (m *ECSTasksModule) printECSTaskData(outputDirectory string, dataReceiver c
 	if m.pmapperError != nil {
 		sharedLogger.Errorf("%s - %s - No pmapper data found for this account. Skipping the pmapper column in the output table.", m.output.CallingModule, m.AWSProfile)
 		tableCols = removeStringFromSlice(tableCols, "CanPrivEscToAdmin?")
+		tableCols = removeStringFromSlice(tableCols, "CanHostRolePrivEscToAdmin?")
 	}
 
 	for _, ecsTask := range m.MappedECSTasks {
@@ -218,6 +276,16 @@ func (m *ECSTasksModule) printECSTaskData(outputDirectory string, dataReceiver c
 				ecsTask.Role,
 				ecsTask.Admin,
 				ecsTask.CanPrivEsc,
+				ecsTask.ExecuteCommandEnabled,
+				ecsTask.ExecAgentStatus,
+				ecsTask.HostInstanceID,
+				ecsTask.HostPublicIP,
+				ecsTask.HostPrivateIP,
+				ecsTask.HostAvailabilityZone,
+				ecsTask.HostSecurityGroups,
+				ecsTask.HostInstanceProfile,
+				ecsTask.HostAdmin,
+				ecsTask.HostCanPrivEsc,
 			},
 		)
 	}
@@ -302,6 +370,87 @@ func (m *ECSTasksModule) writeLoot(outputDirectory string) {
 	fmt.Printf("[%s][%s] Loot written to [%s]\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), privateIPsFilename)
 	fmt.Printf("[%s][%s] Loot written to [%s]\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), publicIPsFilename)
 
+	m.writeExecuteCommandLoot(path)
+	m.writeHostMappingLoot(path)
+}
+
+// writeHostMappingLoot writes ecs-tasks-host-mapping.csv, mapping each
+// EC2-launch-type task to the EC2 instance running it and that instance's
+// role, so an operator doesn't have to cross-reference the main table to
+// see which host (and which host role) a given task's container escape
+// would land on. Fargate tasks have no host and are omitted.
+func (m *ECSTasksModule) writeHostMappingLoot(path string) {
+	var csv string
+
+	for _, task := range m.MappedECSTasks {
+		if task.HostInstanceID == "" {
+			continue
+		}
+		csv = csv + fmt.Sprintf("%s,%s,%s\n", task.ID, task.HostInstanceID, task.HostRole)
+	}
+
+	if csv == "" {
+		return
+	}
+
+	f := filepath.Join(path, "ecs-tasks-host-mapping.csv")
+	csv = "TaskID,HostInstanceID,HostRole\n" + csv
+	if err := os.WriteFile(f, []byte(csv), 0644); err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	} else {
+		fmt.Printf("[%s][%s] Loot written to [%s]\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), f)
+	}
+}
+
+// writeExecuteCommandLoot writes the ECS Exec attack-surface loot pair:
+// ecs-tasks-execute-command.sh, a ready-to-run `aws ecs execute-command`
+// line for every task whose agent is actually RUNNING right now, and
+// ecs-tasks-exec-unreachable.txt, listing tasks where EnableExecuteCommand
+// is on but the agent isn't (yet, or no longer) RUNNING - exec-enabled
+// attack surface that doesn't currently answer, worth revisiting on a
+// rescan.
+func (m *ECSTasksModule) writeExecuteCommandLoot(path string) {
+	var script string
+	var unreachable string
+
+	for _, task := range m.MappedECSTasks {
+		if task.ExecuteCommandEnabled != "true" {
+			continue
+		}
+		if task.ExecAgentStatus == "RUNNING" {
+			script = script + fmt.Sprintf(
+				"aws ecs execute-command --cluster %s --task %s --container %s --interactive --command \"/bin/sh\" --region %s\n",
+				task.Cluster, task.ID, task.ContainerName, task.Region,
+			)
+		} else {
+			status := task.ExecAgentStatus
+			if status == "" {
+				status = "unknown"
+			}
+			unreachable = unreachable + fmt.Sprintf("%s\t%s\t%s\tagent status: %s\n", task.Cluster, task.ID, task.ContainerName, status)
+		}
+	}
+
+	if script != "" {
+		f := filepath.Join(path, "ecs-tasks-execute-command.sh")
+		if err := os.WriteFile(f, []byte(script), 0644); err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		} else {
+			fmt.Printf("[%s][%s] Loot written to [%s]\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), f)
+		}
+	}
+
+	if unreachable != "" {
+		f := filepath.Join(path, "ecs-tasks-exec-unreachable.txt")
+		if err := os.WriteFile(f, []byte(unreachable), 0644); err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		} else {
+			fmt.Printf("[%s][%s] Loot written to [%s]\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), f)
+		}
+	}
 }
 
 func (m *ECSTasksModule) executeChecks(r string, wg *sync.WaitGroup, dataReceiver chan MappedECSTask) {
@@ -387,6 +536,12 @@ func (m *ECSTasksModule) loadTasksData(clusterARN string, taskARNs []string, reg
 		return
 	}
 
+	hosts, err := m.loadHosts(clusterARN, Tasks, region)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+
 	for _, task := range Tasks {
 		//taskDefinition, err := m.describeTaskDefinition(aws.ToString(task.TaskDefinitionArn), region)
 		taskDefinition, err := sdk.CachedECSDescribeTaskDefinition(m.ECSClient, aws.ToString(m.Caller.Account), region, aws.ToString(task.TaskDefinitionArn))
@@ -402,8 +557,11 @@ func (m *ECSTasksModule) loadTasksData(clusterARN string, taskARNs []string, reg
 			ContainerName:         getContainerNamesFromECSTask(task),
 			LaunchType:            string(task.LaunchType),
 			ID:                    getIDFromECSTask(aws.ToString(task.TaskArn)),
+			Region:                region,
 			PrivateIP:             getPrivateIPv4AddressFromECSTask(task),
 			Role:                  getTaskRole(taskDefinition),
+			ExecuteCommandEnabled: strconv.FormatBool(aws.ToBool(task.EnableExecuteCommand)),
+			ExecAgentStatus:       getExecuteCommandAgentStatus(task),
 		}
 
 		eniID := getElasticNetworkInterfaceIDOfECSTask(task)
@@ -411,10 +569,123 @@ func (m *ECSTasksModule) loadTasksData(clusterARN string, taskARNs []string, reg
 			mappedTask.ExternalIP = publicIPs[eniID]
 		}
 
+		if host, ok := hosts[aws.ToString(task.ContainerInstanceArn)]; ok {
+			mappedTask.HostInstanceID = host.instanceID
+			mappedTask.HostPublicIP = host.publicIP
+			mappedTask.HostPrivateIP = host.privateIP
+			mappedTask.HostAvailabilityZone = host.availabilityZone
+			mappedTask.HostSecurityGroups = host.securityGroups
+			mappedTask.HostInstanceProfile = host.instanceProfile
+			mappedTask.HostRole = host.instanceProfile
+		}
+
 		dataReceiver <- mappedTask
 	}
 }
 
+// ecsTaskHost is the subset of an EC2-launch-type task's backing EC2
+// instance that's useful for spotting the container-instance-metadata
+// privilege escalation: the host's identity, reachability, and the role
+// a compromised container on it could steal via IMDS.
+type ecsTaskHost struct {
+	instanceID       string
+	publicIP         string
+	privateIP        string
+	availabilityZone string
+	securityGroups   string
+	instanceProfile  string
+}
+
+// loadHosts resolves every EC2-launch-type task's ContainerInstanceArn to
+// its backing EC2 instance, keyed by ContainerInstanceArn so loadTasksData
+// can attach host details to each task without a second per-task API call.
+// Fargate tasks have no ContainerInstanceArn and are skipped.
+func (m *ECSTasksModule) loadHosts(clusterARN string, tasks []types.Task, region string) (map[string]ecsTaskHost, error) {
+	hosts := make(map[string]ecsTaskHost)
+
+	var containerInstanceARNs []string
+	for _, task := range tasks {
+		if task.LaunchType != types.LaunchTypeEc2 {
+			continue
+		}
+		if arn := aws.ToString(task.ContainerInstanceArn); arn != "" {
+			containerInstanceARNs = append(containerInstanceARNs, arn)
+		}
+	}
+
+	if len(containerInstanceARNs) == 0 {
+		return hosts, nil
+	}
+
+	containerInstances, err := sdk.CachedECSDescribeContainerInstances(m.ECSClient, aws.ToString(m.Caller.Account), region, clusterARN, containerInstanceARNs)
+	if err != nil {
+		return hosts, fmt.Errorf("describing ECS container instances: %s", err)
+	}
+
+	var instanceIDs []string
+	instanceIDToContainerInstanceARN := make(map[string]string)
+	for _, ci := range containerInstances {
+		instanceID := aws.ToString(ci.Ec2InstanceId)
+		if instanceID == "" {
+			continue
+		}
+		instanceIDs = append(instanceIDs, instanceID)
+		instanceIDToContainerInstanceARN[instanceID] = aws.ToString(ci.ContainerInstanceArn)
+	}
+
+	if len(instanceIDs) == 0 {
+		return hosts, nil
+	}
+
+	instances, err := sdk.CachedEC2DescribeInstances(m.EC2Client, aws.ToString(m.Caller.Account), region, instanceIDs)
+	if err != nil {
+		return hosts, fmt.Errorf("describing EC2 instances: %s", err)
+	}
+
+	for _, instance := range instances {
+		instanceID := aws.ToString(instance.InstanceId)
+		containerInstanceARN, ok := instanceIDToContainerInstanceARN[instanceID]
+		if !ok {
+			continue
+		}
+
+		hosts[containerInstanceARN] = mapEC2InstanceToHost(instance)
+	}
+
+	return hosts, nil
+}
+
+// mapEC2InstanceToHost extracts the details of an EC2 container instance
+// host that matter for the ECS-EC2-escape picture: reachability (public and
+// private IP), blast radius (security groups), and the role a compromised
+// container could steal via the instance metadata service (instance
+// profile).
+func mapEC2InstanceToHost(instance ec2Types.Instance) ecsTaskHost {
+	var instanceProfile string
+	if instance.IamInstanceProfile != nil {
+		instanceProfile = aws.ToString(instance.IamInstanceProfile.Arn)
+	}
+
+	var availabilityZone string
+	if instance.Placement != nil {
+		availabilityZone = aws.ToString(instance.Placement.AvailabilityZone)
+	}
+
+	var securityGroups []string
+	for _, sg := range instance.SecurityGroups {
+		securityGroups = append(securityGroups, aws.ToString(sg.GroupId))
+	}
+
+	return ecsTaskHost{
+		instanceID:       aws.ToString(instance.InstanceId),
+		publicIP:         aws.ToString(instance.PublicIpAddress),
+		privateIP:        aws.ToString(instance.PrivateIpAddress),
+		availabilityZone: availabilityZone,
+		securityGroups:   strings.Join(securityGroups, "|"),
+		instanceProfile:  instanceProfile,
+	}
+}
+
 func getTaskRole(taskDefinition types.TaskDefinition) string {
 	return aws.ToString(taskDefinition.TaskRoleArn)
 }
@@ -539,6 +810,22 @@ func getPrivateIPv4AddressFromECSTask(task types.Task) string {
 	return strings.Join(ips, "|")
 }
 
+// getExecuteCommandAgentStatus returns the ExecuteCommandAgent managed
+// agent's LastStatus (e.g. "RUNNING", "STOPPED") off the first container
+// that carries one, or "" if the task has no such agent at all - which
+// happens whenever EnableExecuteCommand has never been true for this task,
+// regardless of its current value.
+func getExecuteCommandAgentStatus(task types.Task) string {
+	for _, container := range task.Containers {
+		for _, agent := range container.ManagedAgents {
+			if agent.Name == types.ManagedAgentNameExecuteCommandAgent {
+				return aws.ToString(agent.LastStatus)
+			}
+		}
+	}
+	return ""
+}
+
 func getElasticNetworkInterfaceIDOfECSTask(task types.Task) string {
 	for _, attachment := range task.Attachments {
 		if aws.ToString(attachment.Type) != "ElasticNetworkInterface" || aws.ToString(attachment.Status) != "ATTACHED" {
@@ -2,17 +2,30 @@ package aws
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/BishopFox/cloudfox/aws/sdk"
 	"github.com/BishopFox/cloudfox/internal"
+	"github.com/BishopFox/cloudfox/internal/outputsink"
+	"github.com/BishopFox/cloudfox/internal/scancache"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/apigateway"
 	apigatewayTypes "github.com/aws/aws-sdk-go-v2/service/apigateway/types"
@@ -22,6 +35,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/apprunner"
 	apprunnerTypes "github.com/aws/aws-sdk-go-v2/service/apprunner/types"
 	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
+	cloudfrontTypes "github.com/aws/aws-sdk-go-v2/service/cloudfront/types"
 	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancing"
 	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
 	"github.com/aws/aws-sdk-go-v2/service/grafana"
@@ -30,7 +44,10 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/opensearch"
 	"github.com/aws/aws-sdk-go-v2/service/rds"
 	"github.com/aws/aws-sdk-go-v2/service/redshift"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	route53Types "github.com/aws/aws-sdk-go-v2/service/route53/types"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/aws/smithy-go"
 	"github.com/bishopfox/awsservicemap"
@@ -54,15 +71,46 @@ type EndpointsModule struct {
 	CloudfrontClient   *cloudfront.Client
 	AppRunnerClient    *apprunner.Client
 	LightsailClient    *lightsail.Client
+	Route53Client      *route53.Client
 
 	Caller        sts.GetCallerIdentityOutput
 	AWSRegions    []string
+	AWSPartition  string
 	AWSOutputType string
 	AWSTableCols  string
 
 	Goroutines int
 	AWSProfile string
 	WrapTable  bool
+	// LootFormat is which additional loot formats writeLoot should write,
+	// beyond the default endpoints-UrlsOnly.txt: nmap, openapi, gowitness,
+	// or all.
+	LootFormat []string
+	// Probe, if set, makes PrintEndpoints actively validate every endpoint
+	// it collects - see probeEndpoints.
+	Probe bool
+	// ProbeFrom records which vantage point the caller wants probe results
+	// interpreted from: "egress" (can it be reached from the internet) or
+	// "localhost" (can cloudfox's own host reach it, e.g. from a bastion
+	// inside the VPC). Dialing itself is always done from wherever cloudfox
+	// is running; there's no separate egress relay wired up here, so
+	// "egress" mode is only as accurate as cloudfox's own network position.
+	ProbeFrom string
+	// ProbeConcurrency bounds the worker pool probeEndpoints uses,
+	// independent of the enumeration pass' m.Goroutines. <= 0 falls back to
+	// m.Goroutines, so existing callers that never set it are unaffected.
+	ProbeConcurrency int
+	// OnlyServices, if non-empty, restricts enumeration to EndpointProvider
+	// ServiceKeys in this list. SkipServices excludes ServiceKeys from an
+	// otherwise-full run; it's ignored when OnlyServices is set.
+	OnlyServices []string
+	SkipServices []string
+	// OutputFormat, when set to "jsonl", suppresses the CSV/table output
+	// files written at the end of PrintEndpoints - loot/endpoints.jsonl is
+	// still written either way, since it streams as endpoints are found
+	// rather than being built from the final table. See Receiver and
+	// openJSONLWriter.
+	OutputFormat string
 
 	// Main module data
 	Endpoints      []Endpoint
@@ -71,6 +119,9 @@ type EndpointsModule struct {
 	// Used to store output data for pretty printing
 	output internal.OutputData2
 	modLog *logrus.Entry
+	// jsonlFile is the open loot/endpoints.jsonl handle Receiver streams
+	// records to, set up by openJSONLWriter before the receiver starts.
+	jsonlFile *os.File
 }
 
 type Endpoint struct {
@@ -81,10 +132,62 @@ type Endpoint struct {
 	Port       int32
 	Protocol   string
 	Public     string
+	// AliasTarget is only populated for "Route53" rows - it names the
+	// already-collected endpoint (or raw DNS target, if it doesn't match
+	// anything else this module found) that the record resolves to.
+	AliasTarget string
+	// Reachable, HTTPStatus, TLSCN, FinalURL, Server, Title, and Fingerprint
+	// are only populated when --probe is set - see
+	// (*EndpointsModule).probeEndpoints. FinalURL/Server/Title/Fingerprint
+	// only apply to http(s) endpoints and are written to the
+	// endpoints-probed.csv/json loot files rather than the main table.
+	Reachable   string
+	HTTPStatus  string
+	TLSCN       string
+	FinalURL    string
+	Server      string
+	Title       string
+	Fingerprint string
+	// HTTPMethod, AuthorizationType, ApiKeyRequired, and AuthorizerName are
+	// only populated for "APIGateway"/"APIGatewayv2" rows - see
+	// getEndpointsPerAPIGateway and getEndpointsPerAPIGatewayv2.
+	HTTPMethod        string
+	AuthorizationType string
+	ApiKeyRequired    bool
+	AuthorizerName    string
+	// ResourcePolicy is the REST API's resource policy document - v1 only,
+	// since HTTP APIs don't have one. It is held on the row but not
+	// evaluated here, and it is not written to the table, the loot files or
+	// loot/endpoints.jsonl. Stage-level WAF association is not collected;
+	// this module carries no WAFv2 client.
+	ResourcePolicy string
+	// ARN is only populated for services whose list/describe call already
+	// has one in scope when the row is built (currently ELBv2 and App
+	// Runner) - every other service leaves it empty.
+	ARN string
+	// AnonymousRead, AnonymousWrite, and CorsAllowsAnyOrigin are only
+	// populated for "S3" rows - see getS3EndpointsPerRegion. They're derived
+	// from the bucket's ACL grants and CORS rules. Public is "True" when
+	// GetBucketPolicyStatus reports a public policy or an AllUsers ACL grant
+	// exists, unless GetPublicAccessBlock has all four block settings on.
+	AnonymousRead       bool
+	AnonymousWrite      bool
+	CorsAllowsAnyOrigin bool
 }
 
 var oe *smithy.OperationError
 
+// dnsSuffix returns the public DNS suffix AWS service endpoints are served
+// under for m.AWSPartition - "amazonaws.com.cn" in the aws-cn partition,
+// "amazonaws.com" everywhere else (including GovCloud and the ISO
+// partitions, which stay on the commercial suffix).
+func (m *EndpointsModule) dnsSuffix() string {
+	if m.AWSPartition == "aws-cn" {
+		return "amazonaws.com.cn"
+	}
+	return "amazonaws.com"
+}
+
 func (m *EndpointsModule) PrintEndpoints(outputDirectory string, verbosity int) {
 	// These struct values are used by the output module
 	m.output.Verbosity = verbosity
@@ -99,7 +202,17 @@ func (m *EndpointsModule) PrintEndpoints(outputDirectory string, verbosity int)
 
 	fmt.Printf("[%s][%s] Enumerating endpoints for account %s.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), aws.ToString(m.Caller.Account))
 	fmt.Printf("[%s][%s] Supported Services: App Runner, APIGateway, ApiGatewayV2, Cloudfront, EKS, ELB, ELBv2, Grafana, \n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
-	fmt.Printf("[%s][%s] \t\t\tLambda, MQ, OpenSearch, Redshift, RDS\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+	fmt.Printf("[%s][%s] \t\t\tLambda, MQ, OpenSearch, Redshift, RDS, Route53, S3\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
+
+	// loot/endpoints.jsonl streams regardless of --output-format, so it has
+	// to be open before the receiver starts consuming dataReceiver.
+	lootDir := filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)), "loot")
+	if err := m.openJSONLWriter(lootDir); err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	} else {
+		defer m.jsonlFile.Close()
+	}
 
 	wg := new(sync.WaitGroup)
 	semaphore := make(chan struct{}, m.Goroutines)
@@ -116,11 +229,15 @@ func (m *EndpointsModule) PrintEndpoints(outputDirectory string, verbosity int)
 
 	go m.Receiver(dataReceiver, receiverDone)
 
-	//execute global checks -- removing from now. not sure i want s3 data in here
-	// wg.Add(1)
-	// go m.getS3EndpointsPerRegion(wg)
-	wg.Add(1)
-	go m.getCloudfrontEndpoints(wg, semaphore, dataReceiver)
+	//execute global checks
+	for _, provider := range endpointProviders {
+		if !provider.IsGlobal() || !m.serviceSelected(provider.ServiceKey()) {
+			continue
+		}
+		m.CommandCounter.Total++
+		wg.Add(1)
+		go m.runProvider(provider, "Global", wg, semaphore, dataReceiver)
+	}
 
 	//execute regional checks
 
@@ -144,6 +261,15 @@ func (m *EndpointsModule) PrintEndpoints(outputDirectory string, verbosity int)
 	receiverDone <- true
 	<-receiverDone
 
+	// Route53 records are joined against the other services' endpoints
+	// collected above, so this has to run after the receiver has stopped
+	// mutating m.Endpoints, not as another goroutine racing the checks above.
+	m.getRoute53Records()
+
+	if m.Probe {
+		m.probeEndpoints()
+	}
+
 	sort.Slice(m.Endpoints, func(i, j int) bool {
 		return m.Endpoints[i].AWSService < m.Endpoints[j].AWSService
 	})
@@ -157,6 +283,10 @@ func (m *EndpointsModule) PrintEndpoints(outputDirectory string, verbosity int)
 		"Port",
 		"Protocol",
 		"Public",
+		"AliasTarget",
+		"Reachable",
+		"HTTPStatus",
+		"TLSCN",
 	}
 
 	// If the user specified table columns, use those.
@@ -181,6 +311,10 @@ func (m *EndpointsModule) PrintEndpoints(outputDirectory string, verbosity int)
 			"Port",
 			"Protocol",
 			"Public",
+			"AliasTarget",
+			"Reachable",
+			"HTTPStatus",
+			"TLSCN",
 		}
 		// Otherwise, use the default columns.
 	} else {
@@ -192,46 +326,70 @@ func (m *EndpointsModule) PrintEndpoints(outputDirectory string, verbosity int)
 			"Port",
 			"Protocol",
 			"Public",
+			"AliasTarget",
+			"Reachable",
+			"HTTPStatus",
+			"TLSCN",
 		}
 	}
 
 	// Table rows
 	for i := range m.Endpoints {
-		m.output.Body = append(
-			m.output.Body,
-			[]string{
-				aws.ToString(m.Caller.Account),
-				m.Endpoints[i].AWSService,
-				m.Endpoints[i].Region,
-				m.Endpoints[i].Name,
-				m.Endpoints[i].Endpoint,
-				strconv.Itoa(int(m.Endpoints[i].Port)),
-				m.Endpoints[i].Protocol,
-				m.Endpoints[i].Public,
-			},
-		)
+		row := []string{
+			aws.ToString(m.Caller.Account),
+			m.Endpoints[i].AWSService,
+			m.Endpoints[i].Region,
+			m.Endpoints[i].Name,
+			m.Endpoints[i].Endpoint,
+			strconv.Itoa(int(m.Endpoints[i].Port)),
+			m.Endpoints[i].Protocol,
+			m.Endpoints[i].Public,
+			m.Endpoints[i].AliasTarget,
+			m.Endpoints[i].Reachable,
+			m.Endpoints[i].HTTPStatus,
+			m.Endpoints[i].TLSCN,
+		}
+		m.output.Body = append(m.output.Body, row)
+
+		if m.Endpoints[i].Public == "true" {
+			EmitFinding(outputsink.Finding{
+				Module:   m.output.CallingModule,
+				Account:  aws.ToString(m.Caller.Account),
+				Profile:  m.AWSProfile,
+				Title:    fmt.Sprintf("publicly accessible %s endpoint: %s", m.Endpoints[i].AWSService, m.Endpoints[i].Name),
+				Row:      row,
+				Severity: outputsink.High,
+			})
+		}
 
 	}
 	if len(m.output.Body) > 0 {
 		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
-		o := internal.OutputClient{
-			Verbosity:     verbosity,
-			CallingModule: m.output.CallingModule,
-			Table: internal.TableClient{
-				Wrap: m.WrapTable,
-			},
+		// --output-format jsonl means the caller only wants loot/endpoints.jsonl
+		// (already fully written by now via Receiver), so the CSV/table files
+		// and the rest of writeLoot's loot formats are skipped.
+		if m.OutputFormat == "jsonl" {
+			fmt.Printf("[%s][%s] %s endpoints found, streamed to loot/endpoints.jsonl (--output-format jsonl suppressed the table/CSV output).\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)))
+		} else {
+			o := internal.OutputClient{
+				Verbosity:     verbosity,
+				CallingModule: m.output.CallingModule,
+				Table: internal.TableClient{
+					Wrap: m.WrapTable,
+				},
+			}
+			o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+				Header:    m.output.Headers,
+				Body:      m.output.Body,
+				TableCols: tableCols,
+				Name:      m.output.CallingModule,
+			})
+			o.PrefixIdentifier = m.AWSProfile
+			o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
+			o.WriteFullOutput(o.Table.TableFiles, nil)
+			m.writeLoot(o.Table.DirectoryName, verbosity)
+			fmt.Printf("[%s][%s] %s endpoints found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)))
 		}
-		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
-			Header:    m.output.Headers,
-			Body:      m.output.Body,
-			TableCols: tableCols,
-			Name:      m.output.CallingModule,
-		})
-		o.PrefixIdentifier = m.AWSProfile
-		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
-		o.WriteFullOutput(o.Table.TableFiles, nil)
-		m.writeLoot(o.Table.DirectoryName, verbosity)
-		fmt.Printf("[%s][%s] %s endpoints found.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.output.Body)))
 	} else {
 		fmt.Printf("[%s][%s] No endpoints found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.AWSProfile))
 	}
@@ -252,6 +410,7 @@ func (m *EndpointsModule) Receiver(receiver chan Endpoint, receiverDone chan boo
 		select {
 		case data := <-receiver:
 			m.Endpoints = append(m.Endpoints, data)
+			m.writeJSONLRecord(data)
 		case <-receiverDone:
 			receiverDone <- true
 			return
@@ -259,163 +418,1150 @@ func (m *EndpointsModule) Receiver(receiver chan Endpoint, receiverDone chan boo
 	}
 }
 
-func (m *EndpointsModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Endpoint) {
-	defer wg.Done()
-	// check the concurrency semaphore
-	// semaphore <- struct{}{}
-	// defer func() {
-	// 	<-semaphore
-	// }()
+// endpointJSONLSchema is the "schema" field of every loot/endpoints.jsonl
+// envelope, versioned so a downstream SIEM pipeline can tell old and new
+// record shapes apart.
+const endpointJSONLSchema = "cloudfox.endpoints.v1"
 
-	servicemap := &awsservicemap.AwsServiceMap{
-		JsonFileSource: "DOWNLOAD_FROM_AWS",
+// endpointJSONLEnvelope is one line of loot/endpoints.jsonl.
+type endpointJSONLEnvelope struct {
+	Schema string              `json:"schema"`
+	Record endpointJSONLRecord `json:"record"`
+}
+
+// endpointJSONLRecord is the envelope payload - the same data the table
+// holds, plus the account and discovery time an Endpoint row doesn't carry
+// on its own.
+type endpointJSONLRecord struct {
+	Account      string `json:"account"`
+	DiscoveredAt string `json:"discovered_at"`
+	Service      string `json:"service"`
+	Region       string `json:"region"`
+	Name         string `json:"name"`
+	Endpoint     string `json:"endpoint"`
+	Port         int32  `json:"port"`
+	Protocol     string `json:"protocol"`
+	Public       string `json:"public"`
+	ARN          string `json:"arn,omitempty"`
+}
+
+// endpointJSONLSchemaDoc is written alongside loot/endpoints.jsonl as
+// endpoints.schema.json, so a user can validate or generate a parser for the
+// stream without having to read this file.
+const endpointJSONLSchemaDoc = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "title": "cloudfox.endpoints.v1",
+  "description": "One line of loot/endpoints.jsonl - a schema-versioned envelope wrapping a single endpoint discovered by the endpoints module.",
+  "type": "object",
+  "required": ["schema", "record"],
+  "properties": {
+    "schema": { "type": "string", "const": "cloudfox.endpoints.v1" },
+    "record": {
+      "type": "object",
+      "required": ["account", "discovered_at", "service", "region", "name", "endpoint", "port", "protocol", "public"],
+      "properties": {
+        "account":       { "type": "string" },
+        "discovered_at": { "type": "string", "format": "date-time" },
+        "service":       { "type": "string" },
+        "region":        { "type": "string" },
+        "name":          { "type": "string" },
+        "endpoint":      { "type": "string" },
+        "port":          { "type": "integer" },
+        "protocol":      { "type": "string" },
+        "public":        { "type": "string" },
+        "arn":           { "type": "string" }
+      }
+    }
+  }
+}
+`
+
+// openJSONLWriter opens loot/endpoints.jsonl for streaming writes and drops
+// a companion endpoints.schema.json next to it, describing the envelope.
+// It's called from PrintEndpoints before the receiver starts, and m.jsonlFile
+// is left nil (writeJSONLRecord is then a no-op) if either write fails, so a
+// loot-directory problem doesn't stop enumeration.
+func (m *EndpointsModule) openJSONLWriter(lootDir string) error {
+	if err := os.MkdirAll(lootDir, os.ModePerm); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(lootDir, "endpoints.schema.json"), []byte(endpointJSONLSchemaDoc), 0644); err != nil {
+		return err
 	}
-	res, err := servicemap.IsServiceInRegion("lambda", r)
+	f, err := os.Create(filepath.Join(lootDir, "endpoints.jsonl"))
 	if err != nil {
-		m.modLog.Error(err)
+		return err
 	}
-	if res {
-		m.CommandCounter.Total++
-		wg.Add(1)
-		go m.getLambdaFunctionsPerRegion(r, wg, semaphore, dataReceiver)
+	m.jsonlFile = f
+	return nil
+}
+
+// writeJSONLRecord appends one envelope line for data to loot/endpoints.jsonl
+// as it arrives on dataReceiver - see Receiver. It streams alongside the
+// table/CSV output rather than waiting for enumeration to finish, so the
+// file is still useful if cloudfox is killed mid-run.
+func (m *EndpointsModule) writeJSONLRecord(data Endpoint) {
+	if m.jsonlFile == nil {
+		return
 	}
-	res, err = servicemap.IsServiceInRegion("eks", r)
+	line, err := json.Marshal(endpointJSONLEnvelope{
+		Schema: endpointJSONLSchema,
+		Record: endpointJSONLRecord{
+			Account:      aws.ToString(m.Caller.Account),
+			DiscoveredAt: time.Now().UTC().Format(time.RFC3339),
+			Service:      data.AWSService,
+			Region:       data.Region,
+			Name:         data.Name,
+			Endpoint:     data.Endpoint,
+			Port:         data.Port,
+			Protocol:     data.Protocol,
+			Public:       data.Public,
+			ARN:          data.ARN,
+		},
+	})
 	if err != nil {
-		m.modLog.Error(err)
+		m.modLog.Error(err.Error())
+		return
 	}
-	if res {
-		m.CommandCounter.Total++
-		wg.Add(1)
-		go m.getEksClustersPerRegion(r, wg, semaphore, dataReceiver)
+	if _, err := m.jsonlFile.Write(append(line, '\n')); err != nil {
+		m.modLog.Error(err.Error())
 	}
-	res, err = servicemap.IsServiceInRegion("mq", r)
+}
+
+// getRoute53Records adds a Route53 row for every A/AAAA/CNAME/ALIAS record
+// in the account's hosted zones, so a vanity domain in front of an
+// unrelated endpoint still shows up. When a record points at an endpoint
+// this module already collected, the new row takes that endpoint's
+// Endpoint/Port/Protocol/Public and names it in AliasTarget; the collected
+// endpoints themselves are left unchanged. It must
+// run after m.Endpoints has stopped being concurrently mutated by
+// Receiver, since it reads the full slice to match DNS targets against it.
+func (m *EndpointsModule) getRoute53Records() {
+	account := aws.ToString(m.Caller.Account)
+	r := "Global"
+
+	HostedZones, err := sdk.CachedRoute53ListHostedZones(m.Route53Client, account, r)
 	if err != nil {
-		m.modLog.Error(err)
+		if errors.As(err, &oe) {
+			m.Errors = append(m.Errors, fmt.Sprintf(" Error: Region: %s, Service: %s, Operation: %s", r, oe.Service(), oe.Operation()))
+		}
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
 	}
-	if res {
-		m.CommandCounter.Total++
+
+	for _, zone := range HostedZones {
+		RecordSets, err := sdk.CachedRoute53ListResourceRecordSets(m.Route53Client, account, r, aws.ToString(zone.Id))
+		if err != nil {
+			if errors.As(err, &oe) {
+				m.Errors = append(m.Errors, fmt.Sprintf(" Error: Region: %s, Service: %s, Operation: %s", r, oe.Service(), oe.Operation()))
+			}
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+
+		for _, record := range RecordSets {
+			if record.Type != route53Types.RRTypeA && record.Type != route53Types.RRTypeAaaa && record.Type != route53Types.RRTypeCname {
+				continue
+			}
+
+			target := m.route53RecordTarget(record)
+			if target == "" {
+				continue
+			}
+
+			name := strings.TrimSuffix(aws.ToString(record.Name), ".")
+			matched, aliasTarget := m.matchRoute53Target(target)
+
+			newEndpoint := Endpoint{
+				AWSService:  "Route53",
+				Region:      r,
+				Name:        name,
+				Endpoint:    fmt.Sprintf("https://%s", name),
+				Port:        443,
+				Protocol:    "https",
+				Public:      "Unknown",
+				AliasTarget: aliasTarget,
+			}
+			if matched != nil {
+				newEndpoint.Endpoint = matched.Endpoint
+				newEndpoint.Port = matched.Port
+				newEndpoint.Protocol = matched.Protocol
+				newEndpoint.Public = matched.Public
+			}
+			m.Endpoints = append(m.Endpoints, newEndpoint)
+		}
+	}
+}
+
+// route53RecordTarget returns the DNS name or IP a record set points at,
+// whether it's a plain record or an alias to another AWS resource.
+func (m *EndpointsModule) route53RecordTarget(record route53Types.ResourceRecordSet) string {
+	if record.AliasTarget != nil {
+		return strings.TrimSuffix(aws.ToString(record.AliasTarget.DNSName), ".")
+	}
+	if len(record.ResourceRecords) > 0 {
+		return aws.ToString(record.ResourceRecords[0].Value)
+	}
+	return ""
+}
+
+// matchRoute53Target looks for target among the endpoints already
+// collected by this module. It returns the matching Endpoint (nil if none
+// matched) along with the AliasTarget value a Route53 row should carry
+// either way.
+func (m *EndpointsModule) matchRoute53Target(target string) (*Endpoint, string) {
+	for i := range m.Endpoints {
+		endpointHost := strings.TrimPrefix(strings.TrimPrefix(m.Endpoints[i].Endpoint, "https://"), "http://")
+		endpointHost = strings.TrimSuffix(endpointHost, "/")
+		if strings.EqualFold(endpointHost, target) {
+			return &m.Endpoints[i], fmt.Sprintf("%s: %s", m.Endpoints[i].AWSService, m.Endpoints[i].Name)
+		}
+	}
+	return nil, target
+}
+
+// probeResult is the cached, on-disk shape of a single endpoint's probe
+// outcome, keyed by endpoint+date via scancache so re-running --probe
+// against the same endpoint later the same day skips the dial entirely.
+type probeResult struct {
+	Reachable   bool
+	HTTPStatus  int
+	TLSCN       string
+	FinalURL    string
+	Server      string
+	Title       string
+	Fingerprint string
+}
+
+const probeTimeout = 5 * time.Second
+
+// probeEndpoints actively validates every endpoint this module collected,
+// using a bounded worker pool sized off --probe-concurrency (falling back
+// to m.Goroutines, the same pool size the enumeration pass uses). It must
+// run after m.Endpoints has stopped being concurrently mutated (i.e. after
+// the receiver teardown in PrintEndpoints), since each worker writes back
+// into its own index of the already-final slice.
+func (m *EndpointsModule) probeEndpoints() {
+	fmt.Printf("[%s][%s] Probing %s endpoints (--probe-from %s)\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), strconv.Itoa(len(m.Endpoints)), m.ProbeFrom)
+
+	concurrency := m.ProbeConcurrency
+	if concurrency <= 0 {
+		concurrency = m.Goroutines
+	}
+
+	account := aws.ToString(m.Caller.Account)
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i := range m.Endpoints {
 		wg.Add(1)
-		go m.getMqBrokersPerRegion(r, wg, semaphore, dataReceiver)
+		go func(i int) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			endpoint := m.Endpoints[i]
+			cacheKey := scancache.Hash(endpoint.Endpoint, time.Now().Format("2006-01-02"))
+
+			var result probeResult
+			if ok, err := scancache.Load(account, cacheKey, cacheKey, &result); err != nil {
+				m.modLog.Error(err.Error())
+			} else if !ok {
+				result = m.probeOne(endpoint)
+				if err := scancache.Store(account, cacheKey, cacheKey, 24*time.Hour, result); err != nil {
+					m.modLog.Error(err.Error())
+				}
+			}
+
+			if result.Reachable {
+				m.Endpoints[i].Reachable = "true"
+			} else {
+				m.Endpoints[i].Reachable = "false"
+				if strings.EqualFold(m.Endpoints[i].Public, "true") {
+					// It was reported as public, but we couldn't actually
+					// reach it - most likely filtered by a security group
+					// or NACL, so correct the column rather than leave a
+					// stale "true" next to Reachable=false.
+					m.Endpoints[i].Public = "False"
+				}
+			}
+			if result.HTTPStatus != 0 {
+				m.Endpoints[i].HTTPStatus = strconv.Itoa(result.HTTPStatus)
+			}
+			m.Endpoints[i].TLSCN = result.TLSCN
+			m.Endpoints[i].FinalURL = result.FinalURL
+			m.Endpoints[i].Server = result.Server
+			m.Endpoints[i].Title = result.Title
+			m.Endpoints[i].Fingerprint = result.Fingerprint
+		}(i)
+	}
+	wg.Wait()
+}
+
+// probeOne actually dials a single endpoint: an HTTP HEAD (falling back to
+// GET) with a short timeout for http(s) endpoints, or a plain TCP connect
+// for everything else.
+func (m *EndpointsModule) probeOne(endpoint Endpoint) probeResult {
+	switch strings.ToLower(endpoint.Protocol) {
+	case "http", "https":
+		return m.probeHTTP(endpoint)
+	default:
+		return m.probeTCP(endpoint)
 	}
-	res, err = servicemap.IsServiceInRegion("es", r)
+}
+
+// probeTCP is used for every non-http(s) endpoint - RDS/Redshift rows carry
+// the DB engine name as their Protocol, so they land here too. It does a
+// plain TCP dial to establish reachability, then a best-effort TLS
+// ClientHello on top of that same port (most managed DB engines negotiate
+// TLS in-band rather than immediately, so this only succeeds for the ones
+// that don't, but it costs nothing extra to try and populates TLSCN when it
+// does).
+func (m *EndpointsModule) probeTCP(endpoint Endpoint) probeResult {
+	host := lootHost(endpoint.Endpoint)
+	if host == "" {
+		return probeResult{}
+	}
+	addr := net.JoinHostPort(host, strconv.Itoa(int(endpoint.Port)))
+	conn, err := net.DialTimeout("tcp", addr, probeTimeout)
 	if err != nil {
-		m.modLog.Error(err)
+		return probeResult{Reachable: false}
 	}
-	if res {
-		m.CommandCounter.Total++
-		wg.Add(1)
-		m.getOpenSearchPerRegion(r, wg, semaphore, dataReceiver)
+	conn.Close()
+
+	result := probeResult{Reachable: true}
+	if tlsConn, err := tls.DialWithDialer(&net.Dialer{Timeout: probeTimeout}, "tcp", addr, &tls.Config{InsecureSkipVerify: true}); err == nil {
+		defer tlsConn.Close()
+		if certs := tlsConn.ConnectionState().PeerCertificates; len(certs) > 0 {
+			leaf := certs[0]
+			result.TLSCN = strings.Join(append([]string{leaf.Subject.CommonName}, leaf.DNSNames...), "|")
+		}
+	}
+	return result
+}
+
+func (m *EndpointsModule) probeHTTP(endpoint Endpoint) probeResult {
+	client := &http.Client{
+		Timeout: probeTimeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	result := probeResult{}
+	if strings.EqualFold(endpoint.Protocol, "https") {
+		result.TLSCN = m.probeTLSCN(endpoint)
+	}
+
+	resp, err := client.Head(endpoint.Endpoint)
+	if err != nil || (resp != nil && resp.StatusCode == http.StatusMethodNotAllowed) {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		resp, err = client.Get(endpoint.Endpoint)
 	}
-	res, err = servicemap.IsServiceInRegion("grafana", r)
 	if err != nil {
-		m.modLog.Error(err)
+		return result
 	}
-	if res {
-		m.CommandCounter.Total++
-		wg.Add(1)
-		m.getGrafanaEndPointsPerRegion(r, wg, semaphore, dataReceiver)
+	defer resp.Body.Close()
+
+	result.Reachable = true
+	result.HTTPStatus = resp.StatusCode
+	result.Server = resp.Header.Get("Server")
+	result.Fingerprint = probeFingerprint(resp.Header)
+	result.FinalURL, result.Title = m.probeTitle(endpoint)
+	return result
+}
+
+// cdnFingerprints is checked in order by probeFingerprint; the first header
+// whose value contains substr (case-insensitively) names the CDN/WAF that
+// response came through. An empty substr means "header present at all".
+var cdnFingerprints = []struct {
+	header string
+	substr string
+	label  string
+}{
+	{"X-Amz-Cf-Id", "", "CloudFront"},
+	{"Via", "cloudfront", "CloudFront"},
+	{"Server", "cloudfront", "CloudFront"},
+	{"Cf-Ray", "", "Cloudflare"},
+	{"Server", "cloudflare", "Cloudflare"},
+	{"X-Sucuri-Id", "", "Sucuri WAF"},
+	{"Server", "awselb", "AWS ELB"},
+	{"Server", "akamaighost", "Akamai"},
+}
+
+// probeFingerprint reports the CDN/WAF cdnFingerprints recognizes in
+// header, or "" if none matched.
+func probeFingerprint(header http.Header) string {
+	for _, fp := range cdnFingerprints {
+		value := header.Get(fp.header)
+		if value == "" {
+			continue
+		}
+		if fp.substr == "" || strings.Contains(strings.ToLower(value), fp.substr) {
+			return fp.label
+		}
+	}
+	return ""
+}
+
+// titlePattern extracts the contents of an HTML <title> tag, used by
+// probeTitle to populate Endpoint.Title - a quick, human-readable
+// fingerprint for whatever an http(s) endpoint turns out to be serving.
+var titlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// probeTitle follows redirects (capped at 10) to find endpoint's terminal
+// URL and the page <title> it serves there. This is a separate,
+// redirect-following request from the one probeHTTP makes for
+// Reachable/HTTPStatus, which intentionally stops at the first hop so that
+// column reflects the endpoint's own response rather than wherever it
+// forwards to.
+func (m *EndpointsModule) probeTitle(endpoint Endpoint) (finalURL string, title string) {
+	client := &http.Client{
+		Timeout: probeTimeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		},
 	}
-	res, err = servicemap.IsServiceInRegion("elb", r)
+
+	resp, err := client.Get(endpoint.Endpoint)
 	if err != nil {
-		m.modLog.Error(err)
+		return endpoint.Endpoint, ""
 	}
-	if res {
-		m.CommandCounter.Total++
-		wg.Add(1)
-		go m.getELBv2ListenersPerRegion(r, wg, semaphore, dataReceiver)
+	defer resp.Body.Close()
 
-		m.CommandCounter.Total++
-		wg.Add(1)
-		go m.getELBListenersPerRegion(r, wg, semaphore, dataReceiver)
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if match := titlePattern.FindSubmatch(body); match != nil {
+		title = strings.TrimSpace(html.UnescapeString(string(match[1])))
 	}
-	res, err = servicemap.IsServiceInRegion("apigateway", r)
+	return resp.Request.URL.String(), title
+}
+
+// probeTLSCN dials endpoint's host:port directly over TLS to read the
+// leaf certificate's CommonName and SANs, since neither is exposed by the
+// http.Client response probeHTTP already makes.
+func (m *EndpointsModule) probeTLSCN(endpoint Endpoint) string {
+	host := lootHost(endpoint.Endpoint)
+	if host == "" {
+		return ""
+	}
+	addr := net.JoinHostPort(host, strconv.Itoa(int(endpoint.Port)))
+
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: probeTimeout}, "tcp", addr, &tls.Config{InsecureSkipVerify: true})
 	if err != nil {
-		m.modLog.Error(err)
+		return ""
+	}
+	defer conn.Close()
+
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return ""
 	}
-	if res {
+	leaf := certs[0]
+	names := append([]string{leaf.Subject.CommonName}, leaf.DNSNames...)
+	return strings.Join(names, "|")
+}
+
+// EndpointProvider is implemented by each AWS service's endpoint
+// enumerator so executeChecks can dispatch every service uniformly
+// instead of hardcoding one IsServiceInRegion/wg.Add/go block per service.
+// A new service's endpoints can be added in its own file by implementing
+// this interface and calling RegisterEndpointProvider from an init(),
+// without touching executeChecks or PrintEndpoints at all.
+type EndpointProvider interface {
+	// ServiceKey is the awsservicemap key this provider is gated on (e.g.
+	// "lambda"), and what --only-services/--skip-services match against.
+	// An empty string means "always run, regardless of region" - app
+	// runner needs this since awsservicemap's JSON doesn't carry it.
+	ServiceKey() string
+	// IsGlobal reports whether this provider should run once for the
+	// account (like Cloudfront), rather than once per region.
+	IsGlobal() bool
+	// Enumerate returns every endpoint this provider finds in region (or
+	// account-wide, for a global provider).
+	Enumerate(ctx context.Context, m *EndpointsModule, region string) ([]Endpoint, error)
+}
+
+var endpointProviders []EndpointProvider
+
+// RegisterEndpointProvider adds p to the set executeChecks/PrintEndpoints
+// schedule. Call it from an init() in the file that defines p.
+func RegisterEndpointProvider(p EndpointProvider) {
+	endpointProviders = append(endpointProviders, p)
+}
+
+// legacyEndpointProvider adapts one of this file's original per-region
+// getters - which already manage their own semaphore/CommandCounter
+// bookkeeping and send results over a dataReceiver channel - to the
+// EndpointProvider interface, so they didn't all need rewriting just to
+// join the registry. Its Enumerate wraps the call in a private
+// channel/WaitGroup pair local to this one invocation; the bookkeeping the
+// wrapped getter still does internally is therefore counted twice against
+// m.CommandCounter (once here, once inside the getter) - harmless since
+// CommandCounter only drives the progress spinner, but worth knowing if
+// that spinner's percentage looks off. New providers written directly
+// against this interface don't have that quirk, since runProvider does
+// all of their bookkeeping for them.
+type legacyEndpointProvider struct {
+	serviceKey string
+	run        func(*EndpointsModule, string, *sync.WaitGroup, chan struct{}, chan Endpoint)
+}
+
+func (p legacyEndpointProvider) ServiceKey() string { return p.serviceKey }
+func (p legacyEndpointProvider) IsGlobal() bool     { return false }
+
+func (p legacyEndpointProvider) Enumerate(ctx context.Context, m *EndpointsModule, region string) ([]Endpoint, error) {
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, 1)
+	dataReceiver := make(chan Endpoint)
+	var endpoints []Endpoint
+	collected := make(chan struct{})
+
+	go func() {
+		for e := range dataReceiver {
+			endpoints = append(endpoints, e)
+		}
+		close(collected)
+	}()
+
+	wg.Add(1)
+	p.run(m, region, &wg, semaphore, dataReceiver)
+	wg.Wait()
+	close(dataReceiver)
+	<-collected
+
+	return endpoints, nil
+}
+
+// legacyGlobalEndpointProvider is legacyEndpointProvider's counterpart for
+// account-wide getters like Cloudfront's, which take no region argument.
+type legacyGlobalEndpointProvider struct {
+	serviceKey string
+	run        func(*EndpointsModule, *sync.WaitGroup, chan struct{}, chan Endpoint)
+}
+
+func (p legacyGlobalEndpointProvider) ServiceKey() string { return p.serviceKey }
+func (p legacyGlobalEndpointProvider) IsGlobal() bool     { return true }
+
+func (p legacyGlobalEndpointProvider) Enumerate(ctx context.Context, m *EndpointsModule, region string) ([]Endpoint, error) {
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, 1)
+	dataReceiver := make(chan Endpoint)
+	var endpoints []Endpoint
+	collected := make(chan struct{})
+
+	go func() {
+		for e := range dataReceiver {
+			endpoints = append(endpoints, e)
+		}
+		close(collected)
+	}()
+
+	wg.Add(1)
+	p.run(m, &wg, semaphore, dataReceiver)
+	wg.Wait()
+	close(dataReceiver)
+	<-collected
+
+	return endpoints, nil
+}
+
+func init() {
+	RegisterEndpointProvider(legacyEndpointProvider{serviceKey: "lambda", run: (*EndpointsModule).getLambdaFunctionsPerRegion})
+	RegisterEndpointProvider(legacyEndpointProvider{serviceKey: "eks", run: (*EndpointsModule).getEksClustersPerRegion})
+	RegisterEndpointProvider(legacyEndpointProvider{serviceKey: "mq", run: (*EndpointsModule).getMqBrokersPerRegion})
+	RegisterEndpointProvider(legacyEndpointProvider{serviceKey: "es", run: (*EndpointsModule).getOpenSearchPerRegion})
+	RegisterEndpointProvider(legacyEndpointProvider{serviceKey: "grafana", run: (*EndpointsModule).getGrafanaEndPointsPerRegion})
+	RegisterEndpointProvider(legacyEndpointProvider{serviceKey: "elb", run: (*EndpointsModule).getELBv2ListenersPerRegion})
+	RegisterEndpointProvider(legacyEndpointProvider{serviceKey: "elb", run: (*EndpointsModule).getELBListenersPerRegion})
+	RegisterEndpointProvider(legacyEndpointProvider{serviceKey: "apigateway", run: (*EndpointsModule).getAPIGatewayAPIsPerRegion})
+	RegisterEndpointProvider(legacyEndpointProvider{serviceKey: "apigateway", run: (*EndpointsModule).getAPIGatewayVIPsPerRegion})
+	RegisterEndpointProvider(legacyEndpointProvider{serviceKey: "apigateway", run: (*EndpointsModule).getAPIGatewayv2APIsPerRegion})
+	RegisterEndpointProvider(legacyEndpointProvider{serviceKey: "apigateway", run: (*EndpointsModule).getAPIGatewayv2VIPsPerRegion})
+	RegisterEndpointProvider(legacyEndpointProvider{serviceKey: "rds", run: (*EndpointsModule).getRdsClustersPerRegion})
+	RegisterEndpointProvider(legacyEndpointProvider{serviceKey: "redshift", run: (*EndpointsModule).getRedshiftEndPointsPerRegion})
+	// apprunner isn't in awsservicemap's JSON, so it always ran in every
+	// region regardless of the per-service check - ServiceKey "" preserves
+	// that under the new scheduler.
+	RegisterEndpointProvider(legacyEndpointProvider{serviceKey: "", run: (*EndpointsModule).getAppRunnerEndpointsPerRegion})
+	RegisterEndpointProvider(legacyEndpointProvider{serviceKey: "lightsail", run: (*EndpointsModule).getLightsailContainerEndpointsPerRegion})
+	RegisterEndpointProvider(legacyGlobalEndpointProvider{serviceKey: "cloudfront", run: (*EndpointsModule).getCloudfrontEndpoints})
+	RegisterEndpointProvider(legacyGlobalEndpointProvider{serviceKey: "s3", run: (*EndpointsModule).getS3EndpointsPerRegion})
+}
+
+// serviceSelected reports whether a provider keyed by key should run,
+// given --only-services/--skip-services. An empty key (providers that
+// always run) is never filtered out.
+func (m *EndpointsModule) serviceSelected(key string) bool {
+	if key == "" {
+		return true
+	}
+	if len(m.OnlyServices) > 0 {
+		for _, s := range m.OnlyServices {
+			if strings.EqualFold(s, key) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, s := range m.SkipServices {
+		if strings.EqualFold(s, key) {
+			return false
+		}
+	}
+	return true
+}
+
+// runProvider is the single scheduler every EndpointProvider goes
+// through: it owns the concurrency semaphore, the CommandCounter
+// bookkeeping, and unified error collection, so individual providers
+// don't each need to reimplement them.
+func (m *EndpointsModule) runProvider(provider EndpointProvider, region string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Endpoint) {
+	defer wg.Done()
+	semaphore <- struct{}{}
+	defer func() { <-semaphore }()
+
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+	}()
+
+	endpoints, err := provider.Enumerate(context.TODO(), m, region)
+	if err != nil {
+		if errors.As(err, &oe) {
+			m.Errors = append(m.Errors, fmt.Sprintf(" Error: Region: %s, Service: %s, Operation: %s", region, oe.Service(), oe.Operation()))
+		}
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+	for _, e := range endpoints {
+		dataReceiver <- e
+	}
+}
+
+func (m *EndpointsModule) executeChecks(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Endpoint) {
+	defer wg.Done()
+
+	servicemap := &awsservicemap.AwsServiceMap{
+		JsonFileSource: "DOWNLOAD_FROM_AWS",
+	}
+
+	for _, provider := range endpointProviders {
+		if provider.IsGlobal() {
+			continue
+		}
+		if !m.serviceSelected(provider.ServiceKey()) {
+			continue
+		}
+		if key := provider.ServiceKey(); key != "" {
+			inRegion, err := servicemap.IsServiceInRegion(key, r)
+			if err != nil {
+				m.modLog.Error(err)
+			}
+			if !inRegion {
+				continue
+			}
+		}
+
 		m.CommandCounter.Total++
 		wg.Add(1)
-		go m.getAPIGatewayAPIsPerRegion(r, wg, semaphore, dataReceiver)
+		go m.runProvider(provider, r, wg, semaphore, dataReceiver)
+	}
+}
+
+func (m *EndpointsModule) writeLoot(outputDirectory string, verbosity int) {
+	path := filepath.Join(outputDirectory, "loot")
+	err := os.MkdirAll(path, os.ModePerm)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		panic(err.Error())
+	}
+	f := filepath.Join(path, "endpoints-UrlsOnly.txt")
+
+	var out string
+
+	for _, endpoint := range m.Endpoints {
+		out = out + fmt.Sprintln(endpoint.Endpoint)
+	}
+
+	err = os.WriteFile(f, []byte(out), 0644)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		panic(err.Error())
+	}
+
+	if verbosity > 2 {
+		fmt.Println()
+		fmt.Printf("[%s][%s] %s \n", cyan(m.output.CallingModule), cyan(m.AWSProfile), green("Feed this endpoints into nmap and something like gowitness/aquatone for screenshots."))
+		fmt.Print(out)
+		fmt.Printf("[%s][%s] %s \n\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), green("End of loot file."))
+	}
+
+	fmt.Printf("[%s][%s] Loot written to [%s]\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), f)
+
+	if m.lootFormatEnabled("nmap") {
+		m.writeNmapLoot(path)
+	}
+	if m.lootFormatEnabled("gowitness") {
+		m.writeGowitnessLoot(path)
+	}
+	if m.lootFormatEnabled("openapi") {
+		m.writeOpenAPILoot(path)
+	}
+
+	if m.Probe {
+		m.writeProbedLoot(path)
+	}
+
+	m.writeUnauthRoutesLoot(path)
+	m.writeCloudfrontDanglingLoot(path)
+
+}
+
+// writeUnauthRoutesLoot writes apigw-unauth-routes.txt, listing the full URL
+// of every API Gateway (v1 and v2) route whose method-level auth is NONE and
+// that doesn't require an API key - the routes an analyst should check first
+// for publicly callable, unauthenticated API surface. Endpoint.ResourcePolicy
+// is deliberately not consulted here since a resource-policy IP allowlist
+// doesn't change the method's own AuthorizationType/ApiKeyRequired. The
+// resource policy is not written to this file or to any other output.
+func (m *EndpointsModule) writeUnauthRoutesLoot(path string) {
+	var out string
+	for _, endpoint := range m.Endpoints {
+		if endpoint.AWSService != "APIGateway" && endpoint.AWSService != "APIGatewayv2" {
+			continue
+		}
+		if endpoint.AuthorizationType != "NONE" || endpoint.ApiKeyRequired {
+			continue
+		}
+		method := endpoint.HTTPMethod
+		if method == "" {
+			method = "ANY"
+		}
+		out = out + fmt.Sprintf("%s %s\n", method, endpoint.Endpoint)
+	}
+
+	if out == "" {
+		return
+	}
+
+	f := filepath.Join(path, "apigw-unauth-routes.txt")
+	if err := os.WriteFile(f, []byte(out), 0644); err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+	fmt.Printf("[%s][%s] Loot written to [%s]\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), f)
+}
+
+// danglingServicePatterns is an ordered list of hostname-suffix -> label
+// pairs for third-party/AWS services that are commonly left dangling (the
+// classic subdomain-takeover target list - an unclaimed S3 website bucket,
+// an deleted ELB, a removed GitHub Pages/Heroku/Azure app, etc). Order
+// doesn't matter for correctness since at most one suffix should ever match
+// a given hostname, but it's kept alphabetical for readability.
+var danglingServicePatterns = []struct {
+	suffix string
+	label  string
+}{
+	{".azurewebsites.net", "Azure App Service"},
+	{".cloudfront.net", "CloudFront distribution"},
+	{".elasticbeanstalk.com", "Elastic Beanstalk"},
+	{".elb.amazonaws.com", "Classic/ALB/NLB Elastic Load Balancer"},
+	{".github.io", "GitHub Pages"},
+	{".herokuapp.com", "Heroku"},
+	{".herokudns.com", "Heroku"},
+	{".s3-website", "S3 static website hosting"},
+	{".s3.amazonaws.com", "S3 bucket"},
+	{".wpengine.com", "WP Engine"},
+}
+
+// classifyCloudfrontOrigin reports whether host - an alias or origin
+// hostname off a Cloudfront distribution - looks Owned (cloudfox already
+// discovered an endpoint with this hostname in this account; no DNS lookup
+// is made for these), Dangling
+// (it matches a known takeover-prone service pattern but doesn't currently
+// resolve to anything), or External (anything else, including hostnames
+// that resolve fine but aren't one of this account's own resources). It
+// also returns a short evidence string explaining the verdict. Without
+// credentials to every other provider these patterns cover, a CNAME lookup
+// failure is the only signal available that a matched hostname is actually
+// unclaimed, so this is a best-effort heuristic, not a guarantee either way.
+func classifyCloudfrontOrigin(host string, ownedHostnames map[string]bool) (classification string, evidence string) {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	if ownedHostnames[host] {
+		return "Owned", "hostname matches another endpoint already discovered in this account"
+	}
+
+	var matchedLabel string
+	for _, p := range danglingServicePatterns {
+		if strings.Contains(host, p.suffix) {
+			matchedLabel = p.label
+			break
+		}
+	}
+
+	cname, err := net.LookupCNAME(host)
+	resolves := err == nil && cname != "" && strings.ToLower(strings.TrimSuffix(cname, ".")) != host
+
+	switch {
+	case matchedLabel != "" && !resolves:
+		return "Dangling", fmt.Sprintf("matches %s hostname pattern and does not currently resolve", matchedLabel)
+	case matchedLabel != "" && resolves:
+		return "External", fmt.Sprintf("matches %s hostname pattern but resolves to %s - not in this account's inventory", matchedLabel, cname)
+	case !resolves:
+		return "External", "does not currently resolve; no known-service pattern matched"
+	default:
+		return "External", fmt.Sprintf("resolves to %s; not in this account's inventory", cname)
+	}
+}
+
+// writeCloudfrontDanglingLoot writes cloudfront-dangling.txt, listing every
+// Cloudfront alias/origin classifyCloudfrontOrigin flags as Dangling along
+// with its evidence, so an analyst can prioritize subdomain-takeover
+// candidates without re-deriving the CNAME chain themselves.
+func (m *EndpointsModule) writeCloudfrontDanglingLoot(path string) {
+	ownedHostnames := map[string]bool{}
+	for _, e := range m.Endpoints {
+		if strings.HasPrefix(e.AWSService, "Cloudfront") {
+			continue
+		}
+		if u, err := url.Parse(e.Endpoint); err == nil && u.Hostname() != "" {
+			ownedHostnames[strings.ToLower(u.Hostname())] = true
+		}
+	}
+
+	var out string
+	for _, e := range m.Endpoints {
+		if e.AWSService != "Cloudfront [alias]" && e.AWSService != "Cloudfront [origin]" {
+			continue
+		}
+		host, err := url.Parse(e.Endpoint)
+		if err != nil || host.Hostname() == "" {
+			continue
+		}
+		classification, evidence := classifyCloudfrontOrigin(host.Hostname(), ownedHostnames)
+		if classification != "Dangling" {
+			continue
+		}
+		out = out + fmt.Sprintf("%s\t%s\t%s\n", host.Hostname(), e.AWSService, evidence)
+	}
+
+	if out == "" {
+		return
+	}
+
+	f := filepath.Join(path, "cloudfront-dangling.txt")
+	if err := os.WriteFile(f, []byte(out), 0644); err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+	fmt.Printf("[%s][%s] Loot written to [%s]\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), f)
+}
+
+// writeProbedLoot writes endpoints-probed.csv and endpoints-probed.json -
+// the full set of --probe results (reachability, TLS CN/SANs, server
+// header, page title, CDN/WAF fingerprint) next to the plain endpoints
+// table, so an operator can go from inventory to live exposure evidence in
+// one file. Only called from writeLoot when --probe was set.
+func (m *EndpointsModule) writeProbedLoot(path string) {
+	type probedRow struct {
+		Account     string `json:"account"`
+		Service     string `json:"service"`
+		Region      string `json:"region"`
+		Name        string `json:"name"`
+		Endpoint    string `json:"endpoint"`
+		Reachable   bool   `json:"reachable"`
+		HTTPStatus  string `json:"http_status"`
+		FinalURL    string `json:"final_url"`
+		Server      string `json:"server"`
+		Title       string `json:"title"`
+		Fingerprint string `json:"fingerprint"`
+		TLSCN       string `json:"tls_cn"`
+	}
+
+	account := aws.ToString(m.Caller.Account)
+	rows := make([]probedRow, 0, len(m.Endpoints))
+	for _, e := range m.Endpoints {
+		status, _ := strconv.Atoi(e.HTTPStatus)
+		rows = append(rows, probedRow{
+			Account:  account,
+			Service:  e.AWSService,
+			Region:   e.Region,
+			Name:     e.Name,
+			Endpoint: e.Endpoint,
+			// "reachable" per chunk9-1 means 2xx/3xx, not just "a TCP
+			// connection succeeded" - e.Reachable alone would call a 403/500
+			// reachable too.
+			Reachable:   status >= 200 && status < 400,
+			HTTPStatus:  e.HTTPStatus,
+			FinalURL:    e.FinalURL,
+			Server:      e.Server,
+			Title:       e.Title,
+			Fingerprint: e.Fingerprint,
+			TLSCN:       e.TLSCN,
+		})
+	}
+
+	jsonData, err := json.MarshalIndent(rows, "", "  ")
+	if err != nil {
+		m.modLog.Error(err.Error())
+	} else if err := os.WriteFile(filepath.Join(path, "endpoints-probed.json"), jsonData, 0644); err != nil {
+		m.modLog.Error(err.Error())
+	}
+
+	csvFile, err := os.Create(filepath.Join(path, "endpoints-probed.csv"))
+	if err != nil {
+		m.modLog.Error(err.Error())
+		return
+	}
+	defer csvFile.Close()
+
+	w := csv.NewWriter(csvFile)
+	defer w.Flush()
+	w.Write([]string{"Account", "Service", "Region", "Name", "Endpoint", "Reachable", "HTTPStatus", "FinalURL", "Server", "Title", "Fingerprint", "TLSCN"})
+	for _, row := range rows {
+		w.Write([]string{
+			row.Account, row.Service, row.Region, row.Name, row.Endpoint,
+			strconv.FormatBool(row.Reachable), row.HTTPStatus, row.FinalURL,
+			row.Server, row.Title, row.Fingerprint, row.TLSCN,
+		})
+	}
+}
+
+// lootFormatEnabled reports whether writeLoot should write the named
+// additional loot format, per --loot-format.
+func (m *EndpointsModule) lootFormatEnabled(name string) bool {
+	for _, f := range m.LootFormat {
+		if f == name || f == "all" {
+			return true
+		}
+	}
+	return false
+}
+
+// lootHost strips the scheme (and any path) off an Endpoint.Endpoint value,
+// returning just the hostname nmap/gowitness care about.
+func lootHost(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return endpoint
+	}
+	return u.Hostname()
+}
+
+type nmapXMLPort struct {
+	Protocol string `xml:"protocol,attr"`
+	PortID   string `xml:"portid,attr"`
+	State    struct {
+		State string `xml:"state,attr"`
+	} `xml:"state"`
+}
 
-		m.CommandCounter.Total++
-		wg.Add(1)
-		go m.getAPIGatewayVIPsPerRegion(r, wg, semaphore, dataReceiver)
+type nmapXMLHost struct {
+	Address struct {
+		Addr     string `xml:"addr,attr"`
+		AddrType string `xml:"addrtype,attr"`
+	} `xml:"address"`
+	Ports []nmapXMLPort `xml:"ports>port"`
+}
 
-		m.CommandCounter.Total++
-		wg.Add(1)
-		go m.getAPIGatewayv2APIsPerRegion(r, wg, semaphore, dataReceiver)
+type nmapXMLRun struct {
+	XMLName xml.Name      `xml:"nmaprun"`
+	Hosts   []nmapXMLHost `xml:"host"`
+}
 
-		m.CommandCounter.Total++
-		wg.Add(1)
-		go m.getAPIGatewayv2VIPsPerRegion(r, wg, semaphore, dataReceiver)
-	}
-	res, err = servicemap.IsServiceInRegion("rds", r)
-	if err != nil {
-		m.modLog.Error(err)
-	}
-	if res {
-		m.CommandCounter.Total++
-		wg.Add(1)
-		go m.getRdsClustersPerRegion(r, wg, semaphore, dataReceiver)
-	}
-	res, err = servicemap.IsServiceInRegion("redshift", r)
-	if err != nil {
-		m.modLog.Error(err)
+// writeNmapLoot writes endpoints-nmap-{http,https,tcp}.txt -iL targets
+// files, split by protocol, plus an endpoints-nmap-seed.xml hosts/ports
+// seed nmap can be pointed at with -iL/--resume workflows that want
+// per-host port hints instead of a flat target list.
+func (m *EndpointsModule) writeNmapLoot(path string) {
+	httpHosts := map[string]bool{}
+	httpsHosts := map[string]bool{}
+	tcpHosts := map[int32]map[string]bool{}
+
+	run := nmapXMLRun{}
+	hostIndex := map[string]int{}
+
+	for _, endpoint := range m.Endpoints {
+		host := lootHost(endpoint.Endpoint)
+		if host == "" {
+			continue
+		}
+
+		switch strings.ToLower(endpoint.Protocol) {
+		case "http":
+			httpHosts[host] = true
+		case "https", "ssl", "tls":
+			httpsHosts[host] = true
+		default:
+			if tcpHosts[endpoint.Port] == nil {
+				tcpHosts[endpoint.Port] = map[string]bool{}
+			}
+			tcpHosts[endpoint.Port][host] = true
+		}
+
+		idx, ok := hostIndex[host]
+		if !ok {
+			var xmlHost nmapXMLHost
+			xmlHost.Address.Addr = host
+			xmlHost.Address.AddrType = "hostname"
+			run.Hosts = append(run.Hosts, xmlHost)
+			idx = len(run.Hosts) - 1
+			hostIndex[host] = idx
+		}
+		portEntry := nmapXMLPort{Protocol: "tcp", PortID: strconv.Itoa(int(endpoint.Port))}
+		portEntry.State.State = "open"
+		run.Hosts[idx].Ports = append(run.Hosts[idx].Ports, portEntry)
 	}
-	if res {
-		m.CommandCounter.Total++
-		wg.Add(1)
-		m.getRedshiftEndPointsPerRegion(r, wg, semaphore, dataReceiver)
+
+	writeHostList := func(name string, hosts map[string]bool) {
+		var out string
+		for host := range hosts {
+			out += fmt.Sprintln(host)
+		}
+		f := filepath.Join(path, name)
+		if err := os.WriteFile(f, []byte(out), 0644); err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			return
+		}
+		fmt.Printf("[%s][%s] Loot written to [%s]\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), f)
 	}
 
-	//apprunner is not supported by the aws json so we have to call it in every region
-	m.CommandCounter.Total++
-	wg.Add(1)
-	go m.getAppRunnerEndpointsPerRegion(r, wg, semaphore, dataReceiver)
+	writeHostList("endpoints-nmap-http.txt", httpHosts)
+	writeHostList("endpoints-nmap-https.txt", httpsHosts)
+
+	tcpAll := map[string]bool{}
+	for _, hosts := range tcpHosts {
+		for host := range hosts {
+			tcpAll[host] = true
+		}
+	}
+	writeHostList("endpoints-nmap-tcp.txt", tcpAll)
 
-	res, err = servicemap.IsServiceInRegion("lightsail", r)
+	seedBytes, err := xml.MarshalIndent(run, "", "  ")
 	if err != nil {
-		m.modLog.Error(err)
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
 	}
-	if res {
-		m.CommandCounter.Total++
-		wg.Add(1)
-		go m.getLightsailContainerEndpointsPerRegion(r, wg, semaphore, dataReceiver)
+	seedFile := filepath.Join(path, "endpoints-nmap-seed.xml")
+	if err := os.WriteFile(seedFile, append([]byte(xml.Header), seedBytes...), 0644); err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
 	}
+	fmt.Printf("[%s][%s] Loot written to [%s]\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), seedFile)
 }
 
-func (m *EndpointsModule) writeLoot(outputDirectory string, verbosity int) {
-	path := filepath.Join(outputDirectory, "loot")
-	err := os.MkdirAll(path, os.ModePerm)
-	if err != nil {
+// writeGowitnessLoot writes a newline-delimited list of HTTP(S) endpoints
+// only, in the plain URL format gowitness/aquatone expect on stdin or
+// with -f/--file.
+func (m *EndpointsModule) writeGowitnessLoot(path string) {
+	var out string
+	for _, endpoint := range m.Endpoints {
+		switch strings.ToLower(endpoint.Protocol) {
+		case "http", "https":
+			out += fmt.Sprintln(endpoint.Endpoint)
+		}
+	}
+
+	f := filepath.Join(path, "endpoints-gowitness.txt")
+	if err := os.WriteFile(f, []byte(out), 0644); err != nil {
 		m.modLog.Error(err.Error())
 		m.CommandCounter.Error++
-		panic(err.Error())
+		return
 	}
-	f := filepath.Join(path, "endpoints-UrlsOnly.txt")
+	fmt.Printf("[%s][%s] Loot written to [%s]\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), f)
+}
 
-	var out string
+var apiGatewayEndpointPattern = regexp.MustCompile(`^https://([^.]+)\.execute-api\.([^.]+)\.`)
 
+// writeOpenAPILoot fetches the exported OpenAPI 3.0 spec for every
+// APIGateway/APIGatewayv2 API this module found and writes one
+// loot/openapi/openapi-<apiId>.json per API. The api ID and region are
+// recovered from the endpoint's execute-api hostname, since that's the
+// only place this module records them once collection has finished.
+func (m *EndpointsModule) writeOpenAPILoot(path string) {
+	type apiRef struct {
+		service string
+		region  string
+		apiID   string
+	}
+	seen := map[string]apiRef{}
 	for _, endpoint := range m.Endpoints {
-		out = out + fmt.Sprintln(endpoint.Endpoint)
+		if endpoint.AWSService != "APIGateway" && endpoint.AWSService != "APIGatewayv2" {
+			continue
+		}
+		matches := apiGatewayEndpointPattern.FindStringSubmatch(endpoint.Endpoint)
+		if matches == nil {
+			continue
+		}
+		ref := apiRef{service: endpoint.AWSService, region: matches[2], apiID: matches[1]}
+		seen[ref.service+ref.apiID] = ref
+	}
+	if len(seen) == 0 {
+		return
 	}
 
-	err = os.WriteFile(f, []byte(out), 0644)
-	if err != nil {
+	openAPIPath := filepath.Join(path, "openapi")
+	if err := os.MkdirAll(openAPIPath, os.ModePerm); err != nil {
 		m.modLog.Error(err.Error())
 		m.CommandCounter.Error++
-		panic(err.Error())
+		return
 	}
 
-	if verbosity > 2 {
-		fmt.Println()
-		fmt.Printf("[%s][%s] %s \n", cyan(m.output.CallingModule), cyan(m.AWSProfile), green("Feed this endpoints into nmap and something like gowitness/aquatone for screenshots."))
-		fmt.Print(out)
-		fmt.Printf("[%s][%s] %s \n\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), green("End of loot file."))
-	}
+	account := aws.ToString(m.Caller.Account)
+	for _, ref := range seen {
+		var export interface{}
+		var err error
+		switch ref.service {
+		case "APIGateway":
+			export, err = sdk.CachedApiGatewayGetExport(m.APIGatewayClient, account, ref.region, ref.apiID)
+		case "APIGatewayv2":
+			export, err = sdk.CachedAPIGatewayv2GetExport(m.APIGatewayv2Client, account, ref.region, ref.apiID)
+		}
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
 
-	fmt.Printf("[%s][%s] Loot written to [%s]\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), f)
+		body, err := json.MarshalIndent(export, "", "  ")
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
 
+		f := filepath.Join(openAPIPath, fmt.Sprintf("openapi-%s.json", ref.apiID))
+		if err := os.WriteFile(f, body, 0644); err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+		fmt.Printf("[%s][%s] Loot written to [%s]\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), f)
+	}
 }
 
 func (m *EndpointsModule) getLambdaFunctionsPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Endpoint) {
@@ -809,6 +1955,7 @@ func (m *EndpointsModule) getELBv2ListenersPerRegion(r string, wg *sync.WaitGrou
 				Port:       port,
 				Protocol:   protocol,
 				Public:     public,
+				ARN:        arn,
 			}
 		}
 
@@ -965,7 +2112,7 @@ func (m *EndpointsModule) getAPIGatewayVIPsPerRegion(r string, wg *sync.WaitGrou
 				if api.Id != nil && aws.ToString(api.Id) == aws.ToString(mapping.RestApiId) {
 					endpoints := m.getEndpointsPerAPIGateway(r, api)
 					for _, endpoint := range endpoints {
-						old := fmt.Sprintf("https://%s.execute-api.%s.amazonaws.com/%s/", aws.ToString(mapping.RestApiId), r, stage)
+						old := fmt.Sprintf("https://%s.execute-api.%s.%s/%s/", aws.ToString(mapping.RestApiId), r, m.dnsSuffix(), stage)
 
 						if strings.HasPrefix(endpoint.Endpoint, old) {
 							var new string
@@ -996,7 +2143,7 @@ func (m *EndpointsModule) getEndpointsPerAPIGateway(r string, api apigatewayType
 
 	name := aws.ToString(api.Name)
 	id := aws.ToString(api.Id)
-	rawEndpoint := fmt.Sprintf("https://%s.execute-api.%s.amazonaws.com", id, r)
+	rawEndpoint := fmt.Sprintf("https://%s.execute-api.%s.%s", id, r, m.dnsSuffix())
 	var port int32 = 443
 	protocol := "https"
 
@@ -1028,22 +2175,59 @@ func (m *EndpointsModule) getEndpointsPerAPIGateway(r string, api apigatewayType
 		m.CommandCounter.Error++
 	}
 
+	// authorizerNames maps an authorizer id (as returned by GetMethod's
+	// AuthorizerId) to its display name, which is stored in
+	// Endpoint.AuthorizerName. AuthorizerName is not written to
+	// apigw-unauth-routes.txt, the table or loot/endpoints.jsonl.
+	authorizerNames := map[string]string{}
+	GetAuthorizers, err := sdk.CachedApiGatewayGetAuthorizers(m.APIGatewayClient, aws.ToString(m.Caller.Account), r, id)
+	if err != nil {
+		m.modLog.Error(err.Error())
+	}
+	for _, authorizer := range GetAuthorizers {
+		authorizerNames[aws.ToString(authorizer.Id)] = aws.ToString(authorizer.Name)
+	}
+
+	resourcePolicy := aws.ToString(api.Policy)
+
 	for _, stage := range GetStages.Item {
 		stageName := aws.ToString(stage.StageName)
 		for _, resource := range GetResources {
-			if len(resource.ResourceMethods) != 0 {
-				path := aws.ToString(resource.Path)
-
-				endpoint := fmt.Sprintf("%s/%s%s", rawEndpoint, stageName, path)
+			if len(resource.ResourceMethods) == 0 {
+				continue
+			}
+			path := aws.ToString(resource.Path)
+			endpoint := fmt.Sprintf("%s/%s%s", rawEndpoint, stageName, path)
+
+			for httpMethod := range resource.ResourceMethods {
+				authType := ""
+				apiKeyRequired := false
+				authorizerName := ""
+
+				method, err := sdk.CachedApiGatewayGetMethod(m.APIGatewayClient, aws.ToString(m.Caller.Account), r, id, aws.ToString(resource.Id), httpMethod)
+				if err != nil {
+					m.modLog.Error(err.Error())
+				} else if method != nil {
+					authType = aws.ToString(method.AuthorizationType)
+					apiKeyRequired = aws.ToBool(method.ApiKeyRequired)
+					if authorizerID := aws.ToString(method.AuthorizerId); authorizerID != "" {
+						authorizerName = authorizerNames[authorizerID]
+					}
+				}
 
 				endpoints = append(endpoints, Endpoint{
-					AWSService: awsService,
-					Region:     r,
-					Name:       name,
-					Endpoint:   endpoint,
-					Port:       port,
-					Protocol:   protocol,
-					Public:     public,
+					AWSService:        awsService,
+					Region:            r,
+					Name:              name,
+					Endpoint:          endpoint,
+					Port:              port,
+					Protocol:          protocol,
+					Public:            public,
+					HTTPMethod:        httpMethod,
+					AuthorizationType: authType,
+					ApiKeyRequired:    apiKeyRequired,
+					AuthorizerName:    authorizerName,
+					ResourcePolicy:    resourcePolicy,
 				})
 			}
 		}
@@ -1143,9 +2327,9 @@ func (m *EndpointsModule) getAPIGatewayv2VIPsPerRegion(r string, wg *sync.WaitGr
 					for _, endpoint := range endpoints {
 						var old string
 						if stage == "" {
-							old = fmt.Sprintf("https://%s.execute-api.%s.amazonaws.com/", aws.ToString(mapping.ApiId), r)
+							old = fmt.Sprintf("https://%s.execute-api.%s.%s/", aws.ToString(mapping.ApiId), r, m.dnsSuffix())
 						} else {
-							old = fmt.Sprintf("https://%s.execute-api.%s.amazonaws.com/%s/", aws.ToString(mapping.ApiId), r, stage)
+							old = fmt.Sprintf("https://%s.execute-api.%s.%s/%s/", aws.ToString(mapping.ApiId), r, m.dnsSuffix(), stage)
 						}
 						if strings.HasPrefix(endpoint.Endpoint, old) {
 							var new string
@@ -1209,12 +2393,25 @@ func (m *EndpointsModule) getEndpointsPerAPIGatewayv2(r string, api apigatewayV2
 		m.CommandCounter.Error++
 	}
 
+	// authorizerNames maps an authorizer id to its display name, resolved
+	// once per API rather than once per route.
+	authorizerNames := map[string]string{}
+	GetAuthorizers, err := sdk.CachedAPIGatewayv2GetAuthorizers(m.APIGatewayv2Client, aws.ToString(m.Caller.Account), r, id)
+	if err != nil {
+		m.modLog.Error(err.Error())
+	}
+	for _, authorizer := range GetAuthorizers {
+		authorizerNames[aws.ToString(authorizer.AuthorizerId)] = aws.ToString(authorizer.Name)
+	}
+
 	for _, stage := range stages {
 		for _, route := range GetRoutes {
 			routeKey := route.RouteKey
-			var path string
-			if len(strings.Fields(*routeKey)) == 2 {
-				path = strings.Fields(*routeKey)[1]
+			fields := strings.Fields(aws.ToString(routeKey))
+			var httpMethod, path string
+			if len(fields) == 2 {
+				httpMethod = fields[0]
+				path = fields[1]
 			}
 			var endpoint string
 			if stage == "" {
@@ -1224,14 +2421,23 @@ func (m *EndpointsModule) getEndpointsPerAPIGatewayv2(r string, api apigatewayV2
 			}
 			public = "True"
 
+			authorizerName := ""
+			if authorizerID := aws.ToString(route.AuthorizerId); authorizerID != "" {
+				authorizerName = authorizerNames[authorizerID]
+			}
+
 			endpoints = append(endpoints, Endpoint{
-				AWSService: awsService,
-				Region:     r,
-				Name:       name,
-				Endpoint:   endpoint,
-				Port:       port,
-				Protocol:   protocol,
-				Public:     public,
+				AWSService:        awsService,
+				Region:            r,
+				Name:              name,
+				Endpoint:          endpoint,
+				Port:              port,
+				Protocol:          protocol,
+				Public:            public,
+				HTTPMethod:        httpMethod,
+				AuthorizationType: string(route.AuthorizationType),
+				ApiKeyRequired:    aws.ToBool(route.ApiKeyRequired),
+				AuthorizerName:    authorizerName,
 			})
 		}
 	}
@@ -1350,83 +2556,168 @@ func (m *EndpointsModule) getRedshiftEndPointsPerRegion(r string, wg *sync.WaitG
 
 }
 
-/*
-UNUSED CODE - PLEASE REVIEW AND DELETE IF IT DOESN'T APPLY
+// getS3EndpointsPerRegion is account-wide (S3 buckets aren't scoped to a
+// single region the way most services are), so it's registered as a global
+// provider the same as Cloudfront below rather than dispatched per-region.
+// For each bucket it emits the virtual-hosted REST endpoint, plus a website
+// endpoint when GetBucketWebsite returns a config and a transfer-acceleration
+// endpoint when GetBucketAccelerateConfiguration reports one enabled -
+// Public/AnonymousRead/AnonymousWrite/CorsAllowsAnyOrigin are computed once
+// per bucket and copied onto every endpoint variant it produces.
+func (m *EndpointsModule) getS3EndpointsPerRegion(wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Endpoint) {
+	defer func() {
+		m.CommandCounter.Executing--
+		m.CommandCounter.Complete++
+		wg.Done()
 
-	func (m *EndpointsModule) getS3EndpointsPerRegion(wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Endpoint) {
-		defer func() {
-			m.CommandCounter.Executing--
-			m.CommandCounter.Complete++
-			wg.Done()
+	}()
+	semaphore <- struct{}{}
+	defer func() {
+		<-semaphore
+	}()
+	// m.CommandCounter.Total++
+	m.CommandCounter.Pending--
+	m.CommandCounter.Executing++
 
-		}()
-		semaphore <- struct{}{}
-		defer func() {
-			<-semaphore
-		}()
-		// m.CommandCounter.Total++
-		m.CommandCounter.Pending--
-		m.CommandCounter.Executing++
+	awsService := "S3"
 
-		// This for loop exits at the end dependeding on whether the output hits its last page (see pagination control block at the end of the loop).
-		ListBuckets, _ := m.S3Client.ListBuckets(
-			context.TODO(),
-			&s3.ListBucketsInput{},
-		)
+	ListBuckets, err := m.S3Client.ListBuckets(
+		context.TODO(),
+		&s3.ListBucketsInput{},
+	)
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
 
-		var public string
-		for _, bucket := range ListBuckets.Buckets {
-			name := aws.ToString(bucket.Name)
-			endpoint := fmt.Sprintf("https://%s.s3.amazonaws.com", name)
-			awsService := "S3"
+	for _, bucket := range ListBuckets.Buckets {
+		name := aws.ToString(bucket.Name)
 
-			var port int32 = 443
-			protocol := "https"
-			var r string = "Global"
-			public = "False"
+		region := "us-east-1"
+		if GetBucketLocation, err := m.S3Client.GetBucketLocation(
+			context.TODO(),
+			&s3.GetBucketLocationInput{Bucket: &name},
+		); err == nil {
+			if loc := string(GetBucketLocation.LocationConstraint); loc != "" {
+				region = loc
+			}
+		}
 
-			GetBucketPolicyStatus, err := m.S3Client.GetBucketPolicyStatus(
-				context.TODO(),
-				&s3.GetBucketPolicyStatusInput{
-					Bucket: &name,
-				},
-			)
+		public, anonymousRead, anonymousWrite, corsAllowsAnyOrigin := m.s3BucketExposure(name)
+
+		base := Endpoint{
+			AWSService:          awsService,
+			Region:              region,
+			Name:                name,
+			Port:                443,
+			Protocol:            "https",
+			Public:              public,
+			AnonymousRead:       anonymousRead,
+			AnonymousWrite:      anonymousWrite,
+			CorsAllowsAnyOrigin: corsAllowsAnyOrigin,
+		}
 
-			if err == nil {
-				isPublic := GetBucketPolicyStatus.PolicyStatus.IsPublic
-				if isPublic {
-					public = "True"
-				}
-			}
+		restEndpoint := base
+		restEndpoint.Endpoint = fmt.Sprintf("https://%s.s3.%s.%s", name, region, m.dnsSuffix())
+		dataReceiver <- restEndpoint
 
-			// GetBucketWebsite, err := m.S3Client.GetBucketWebsite(
-			// 	context.TODO(),
-			// 	&s3.GetBucketWebsiteInput{
-			// 		Bucket: &name,
-			// 	},
-			// )
+		if GetBucketWebsite, err := m.S3Client.GetBucketWebsite(
+			context.TODO(),
+			&s3.GetBucketWebsiteInput{Bucket: &name},
+		); err == nil && GetBucketWebsite != nil {
+			websiteEndpoint := base
+			websiteEndpoint.AWSService = awsService + " [website]"
+			websiteEndpoint.Endpoint = fmt.Sprintf("http://%s.s3-website.%s.%s", name, region, m.dnsSuffix())
+			websiteEndpoint.Protocol = "http"
+			websiteEndpoint.Port = 80
+			dataReceiver <- websiteEndpoint
+		}
 
-			// if err != nil {
-			// 	index := *GetBucketWebsite.IndexDocument.Suffix
-			// 	if index != "" {
-			// 		public = "True"
-			// 	}
+		if GetBucketAccelerateConfiguration, err := m.S3Client.GetBucketAccelerateConfiguration(
+			context.TODO(),
+			&s3.GetBucketAccelerateConfigurationInput{Bucket: &name},
+		); err == nil && GetBucketAccelerateConfiguration.Status == s3Types.BucketAccelerateStatusEnabled {
+			accelerateEndpoint := base
+			accelerateEndpoint.AWSService = awsService + " [accelerate]"
+			accelerateEndpoint.Endpoint = fmt.Sprintf("https://%s.s3-accelerate.%s", name, m.dnsSuffix())
+			dataReceiver <- accelerateEndpoint
+		}
+	}
+}
 
-			// }
+// s3BucketExposure reports bucket, the same "Public" string every other
+// service's endpoints use ("True"/"False"), plus the three booleans the
+// request asked for: whether the bucket's ACL grants read/write to the
+// AllUsers group, and whether any CORS rule allows any origin ("*").
+// GetPublicAccessBlock is consulted so a bucket with all four block-public
+// settings on isn't flagged Public even if GetBucketPolicyStatus/ACL checks
+// below would otherwise say so - the block settings override those at
+// request time.
+func (m *EndpointsModule) s3BucketExposure(bucket string) (public string, anonymousRead bool, anonymousWrite bool, corsAllowsAnyOrigin bool) {
+	public = "False"
+
+	blockedPublicly := false
+	if GetPublicAccessBlock, err := m.S3Client.GetPublicAccessBlock(
+		context.TODO(),
+		&s3.GetPublicAccessBlockInput{Bucket: &bucket},
+	); err == nil && GetPublicAccessBlock.PublicAccessBlockConfiguration != nil {
+		c := GetPublicAccessBlock.PublicAccessBlockConfiguration
+		blockedPublicly = aws.ToBool(c.BlockPublicAcls) && aws.ToBool(c.BlockPublicPolicy) &&
+			aws.ToBool(c.IgnorePublicAcls) && aws.ToBool(c.RestrictPublicBuckets)
+	}
+
+	if GetBucketPolicyStatus, err := m.S3Client.GetBucketPolicyStatus(
+		context.TODO(),
+		&s3.GetBucketPolicyStatusInput{Bucket: &bucket},
+	); err == nil && GetBucketPolicyStatus.PolicyStatus != nil && aws.ToBool(GetBucketPolicyStatus.PolicyStatus.IsPublic) {
+		public = "True"
+	}
 
-			dataReceiver <- Endpoint{
-				AWSService: awsService,
-				Region:     r,
-				Name:       name,
-				Endpoint:   endpoint,
-				Port:       port,
-				Protocol:   protocol,
-				Public:     public,
+	if GetBucketAcl, err := m.S3Client.GetBucketAcl(
+		context.TODO(),
+		&s3.GetBucketAclInput{Bucket: &bucket},
+	); err == nil {
+		for _, grant := range GetBucketAcl.Grants {
+			if grant.Grantee == nil || aws.ToString(grant.Grantee.URI) != "http://acs.amazonaws.com/groups/global/AllUsers" {
+				continue
+			}
+			switch grant.Permission {
+			case s3Types.PermissionRead, s3Types.PermissionFullControl:
+				anonymousRead = true
+			}
+			switch grant.Permission {
+			case s3Types.PermissionWrite, s3Types.PermissionFullControl:
+				anonymousWrite = true
 			}
+		}
+	}
 
+	if anonymousRead || anonymousWrite {
+		public = "True"
+	}
+	if blockedPublicly {
+		public = "False"
+		anonymousRead = false
+		anonymousWrite = false
+	}
+
+	if GetBucketCors, err := m.S3Client.GetBucketCors(
+		context.TODO(),
+		&s3.GetBucketCorsInput{Bucket: &bucket},
+	); err == nil {
+		for _, rule := range GetBucketCors.CORSRules {
+			for _, origin := range rule.AllowedOrigins {
+				if origin == "*" {
+					corsAllowsAnyOrigin = true
+				}
+			}
 		}
 	}
-*/
+
+	return public, anonymousRead, anonymousWrite, corsAllowsAnyOrigin
+}
+
 func (m *EndpointsModule) getCloudfrontEndpoints(wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Endpoint) {
 	defer func() {
 		m.CommandCounter.Executing--
@@ -1441,44 +2732,58 @@ func (m *EndpointsModule) getCloudfrontEndpoints(wg *sync.WaitGroup, semaphore c
 	// m.CommandCounter.Total++
 	m.CommandCounter.Pending--
 	m.CommandCounter.Executing++
-	// "PaginationMarker" is a control variable used for output continuity, as AWS return the output in pages.
-	var PaginationControl *string
 	var awsService = "Cloudfront"
 	var protocol = "https"
 	var r = "Global"
 	var public = "True"
 
-	// This for loop exits at the end depending on whether the output hits its last page (see pagination control block at the end of the loop).
-	for {
-		ListDistributions, err := m.CloudfrontClient.ListDistributions(
-			context.TODO(),
-			&cloudfront.ListDistributionsInput{
-				Marker: PaginationControl,
-			},
-		)
-		if err != nil {
-			if errors.As(err, &oe) {
-				m.Errors = append(m.Errors, fmt.Sprintf(" Error: Region: %s, Service: %s, Operation: %s", r, oe.Service(), oe.Operation()))
+	distributions, err := sdk.Paginate(
+		context.TODO(),
+		func(ctx context.Context, marker *string) (*cloudfront.ListDistributionsOutput, error) {
+			return m.CloudfrontClient.ListDistributions(ctx, &cloudfront.ListDistributionsInput{Marker: marker})
+		},
+		func(out *cloudfront.ListDistributionsOutput) []cloudfrontTypes.DistributionSummary {
+			if out.DistributionList == nil {
+				return nil
 			}
-			m.modLog.Error(err.Error())
-			m.CommandCounter.Error++
-			break
+			return out.DistributionList.Items
+		},
+		func(out *cloudfront.ListDistributionsOutput) *string {
+			if out.DistributionList == nil {
+				return nil
+			}
+			return out.DistributionList.NextMarker
+		},
+	)
+	if err != nil {
+		if errors.As(err, &oe) {
+			m.Errors = append(m.Errors, fmt.Sprintf(" Error: Region: %s, Service: %s, Operation: %s", r, oe.Service(), oe.Operation()))
 		}
-		if ListDistributions.DistributionList.Quantity == nil {
-			break
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	for _, item := range distributions {
+		name := aws.ToString(item.DomainName)
+		public = "True"
+		var port int32 = 443
+		endpoint := fmt.Sprintf("https://%s", aws.ToString(item.DomainName))
+		dataReceiver <- Endpoint{
+			AWSService: awsService,
+			Region:     r,
+			Name:       name,
+			Endpoint:   endpoint,
+			Port:       port,
+			Protocol:   protocol,
+			Public:     public,
 		}
-		// var public string
-		// var hostnames []string
-		// var aliases []string
-		// var origins []string
 
-		for _, item := range ListDistributions.DistributionList.Items {
-			name := aws.ToString(item.DomainName)
-			public = "True"
-			var port int32 = 443
-			endpoint := fmt.Sprintf("https://%s", aws.ToString(item.DomainName))
+		for _, alias := range item.Aliases.Items {
+			endpoint := fmt.Sprintf("https://%s", alias)
+			awsServiceAlias := fmt.Sprintf("%s [alias]", awsService)
 			dataReceiver <- Endpoint{
-				AWSService: awsService,
+				AWSService: awsServiceAlias,
 				Region:     r,
 				Name:       name,
 				Endpoint:   endpoint,
@@ -1486,55 +2791,26 @@ func (m *EndpointsModule) getCloudfrontEndpoints(wg *sync.WaitGroup, semaphore c
 				Protocol:   protocol,
 				Public:     public,
 			}
-			//fmt.Println(*item.DomainName)
-			for _, alias := range item.Aliases.Items {
-				//aliases = append(aliases, alias)
-
-				endpoint := fmt.Sprintf("https://%s", alias)
-				awsServiceAlias := fmt.Sprintf("%s [alias]", awsService)
-				dataReceiver <- Endpoint{
-					AWSService: awsServiceAlias,
-					Region:     r,
-					Name:       name,
-					Endpoint:   endpoint,
-					Port:       port,
-					Protocol:   protocol,
-					Public:     public,
-				}
-			}
-
-			for _, origin := range item.Origins.Items {
-				//origins = append(origins, *origin.DomainName)
-				//fmt.Println(origin.DomainName)
-				public = "Unknown"
-				var port int32 = 443
-				path := aws.ToString(origin.OriginPath)
-				if !strings.HasPrefix(path, "/") {
-					path = "/" + path
-				}
-				endpoint := fmt.Sprintf("https://%s%s", aws.ToString(origin.DomainName), path)
-				awsServiceOrigin := fmt.Sprintf("%s [origin]", awsService)
-				dataReceiver <- Endpoint{
-					AWSService: awsServiceOrigin,
-					Region:     r,
-					Name:       name,
-					Endpoint:   endpoint,
-					Port:       port,
-					Protocol:   protocol,
-					Public:     public,
-				}
-			}
-
 		}
 
-		// port := cluster.Endpoint.Port
-
-		// Pagination control. After the last page of output, the for loop exits.
-		if ListDistributions.DistributionList.NextMarker != nil {
-			PaginationControl = ListDistributions.DistributionList.NextMarker
-		} else {
-			PaginationControl = nil
-			break
+		for _, origin := range item.Origins.Items {
+			public = "Unknown"
+			var port int32 = 443
+			path := aws.ToString(origin.OriginPath)
+			if !strings.HasPrefix(path, "/") {
+				path = "/" + path
+			}
+			endpoint := fmt.Sprintf("https://%s%s", aws.ToString(origin.DomainName), path)
+			awsServiceOrigin := fmt.Sprintf("%s [origin]", awsService)
+			dataReceiver <- Endpoint{
+				AWSService: awsServiceOrigin,
+				Region:     r,
+				Name:       name,
+				Endpoint:   endpoint,
+				Port:       port,
+				Protocol:   protocol,
+				Public:     public,
+			}
 		}
 	}
 }
@@ -1573,6 +2849,7 @@ func (m *EndpointsModule) getAppRunnerEndpointsPerRegion(r string, wg *sync.Wait
 		endpoint.Region = r
 
 		arn := aws.ToString(service.ServiceArn)
+		endpoint.ARN = arn
 
 		DescribeService, err := m.AppRunnerClient.DescribeService(
 			context.TODO(),
@@ -1626,38 +2903,27 @@ func (m *EndpointsModule) getAppRunnerEndpointsPerRegion(r string, wg *sync.Wait
 }
 
 func (m *EndpointsModule) appRunnerDescribeCustomDomain(r string, serviceArn string) ([]apprunnerTypes.CustomDomain, error) {
-	var PaginationControl *string
-	var domains []apprunnerTypes.CustomDomain
-	for {
-		ListDomains, err := m.AppRunnerClient.DescribeCustomDomains(
-			context.TODO(),
-			&(apprunner.DescribeCustomDomainsInput{
-				ServiceArn: &serviceArn,
-				NextToken:  PaginationControl,
-			}),
-			func(o *apprunner.Options) {
-				o.Region = r
-			},
-		)
-		if err != nil {
-			return domains, err
-		}
-		if len(ListDomains.CustomDomains) > 0 {
-			for _, domain := range ListDomains.CustomDomains {
-				domains = append(domains, domain)
-			}
-		}
-
-		// The "NextToken" value is nil when there's no more data to return.
-		if ListDomains.NextToken != nil {
-			PaginationControl = ListDomains.NextToken
-		} else {
-			PaginationControl = nil
-			break
-		}
-	}
-	return domains, nil
-
+	return sdk.Paginate(
+		context.TODO(),
+		func(ctx context.Context, marker *string) (*apprunner.DescribeCustomDomainsOutput, error) {
+			return m.AppRunnerClient.DescribeCustomDomains(
+				ctx,
+				&apprunner.DescribeCustomDomainsInput{
+					ServiceArn: &serviceArn,
+					NextToken:  marker,
+				},
+				func(o *apprunner.Options) {
+					o.Region = r
+				},
+			)
+		},
+		func(out *apprunner.DescribeCustomDomainsOutput) []apprunnerTypes.CustomDomain {
+			return out.CustomDomains
+		},
+		func(out *apprunner.DescribeCustomDomainsOutput) *string {
+			return out.NextToken
+		},
+	)
 }
 
 func (m *EndpointsModule) getLightsailContainerEndpointsPerRegion(r string, wg *sync.WaitGroup, semaphore chan struct{}, dataReceiver chan Endpoint) {
@@ -0,0 +1,20 @@
+package aws
+
+import (
+	"github.com/BishopFox/cloudfox/internal/notify"
+)
+
+// Notifier is where cli/aws.go's --notify-sns-topic/--notify-webhook-url
+// flags end up. all-checks and cape publish to it when they finish (or
+// error out) so an operator can kick off a multi-account scan and get
+// pinged instead of tailing logs. It defaults to doing nothing so a run
+// with neither flag set behaves exactly like it always did.
+var Notifier notify.Notifier = notify.NoopNotifier{}
+
+// NotifyScanComplete publishes event to Notifier, logging rather than
+// failing the run if the notification itself can't be delivered.
+func NotifyScanComplete(event notify.Event) {
+	if err := Notifier.Notify(event); err != nil {
+		sharedLogger.Errorf("%s - failed to send scan notification: %s", event.Module, err)
+	}
+}
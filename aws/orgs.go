@@ -0,0 +1,165 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/BishopFox/cloudfox/internal/credbroker"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	"github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// OrgModuleClient answers the two questions cli/aws.go's awsPreRun needs
+// about a profile before deciding whether to fan out into the rest of its
+// Organization: is this caller in an org at all, and if so, is it the
+// management account (the only place ListAccounts/AssumeRole into member
+// accounts is possible from).
+type OrgModuleClient struct {
+	OrganizationsClient *organizations.Client
+
+	Caller     sts.GetCallerIdentityOutput
+	AWSProfile string
+	Goroutines int
+	MFAToken   string
+
+	DescribeOrgOutput *organizations.DescribeOrganizationOutput
+}
+
+// InitOrgsClient builds an OrgModuleClient for profile, resolving its AWS
+// config the same way every other module does.
+func InitOrgsClient(caller sts.GetCallerIdentityOutput, profile string, version string, goroutines int, mfaToken string) OrgModuleClient {
+	cfg := internal.AWSConfigFileLoader(profile, version, mfaToken)
+	return OrgModuleClient{
+		OrganizationsClient: organizations.NewFromConfig(cfg),
+		Caller:              caller,
+		AWSProfile:          profile,
+		Goroutines:          goroutines,
+		MFAToken:            mfaToken,
+	}
+}
+
+// IsCallerAccountPartOfAnOrg calls organizations:DescribeOrganization and
+// caches the result on the client. A caller outside an org (or lacking the
+// permission to ask) is treated as "not part of an org" rather than a fatal
+// error, since most accounts cloudfox runs against won't be.
+func (m *OrgModuleClient) IsCallerAccountPartOfAnOrg() bool {
+	output, err := m.OrganizationsClient.DescribeOrganization(context.TODO(), &organizations.DescribeOrganizationInput{})
+	if err != nil {
+		return false
+	}
+	m.DescribeOrgOutput = output
+	return true
+}
+
+// IsManagementAccount reports whether accountID is the org's management
+// (master) account - the only account ListAccounts/AssumeRole into member
+// accounts works from.
+func (m *OrgModuleClient) IsManagementAccount(org *organizations.DescribeOrganizationOutput, accountID string) bool {
+	if org == nil || org.Organization == nil {
+		return false
+	}
+	return *org.Organization.MasterAccountId == accountID
+}
+
+// DiscoverMemberAccounts lists every ACTIVE account in the organization,
+// paginating through organizations:ListAccounts, then keeps only the ones
+// whose account ID or name matches accountFilter (a regular expression; an
+// empty filter keeps everything).
+func (m *OrgModuleClient) DiscoverMemberAccounts(accountFilter string) ([]types.Account, error) {
+	var filterRe *regexp.Regexp
+	if accountFilter != "" {
+		re, err := regexp.Compile(accountFilter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --org-account-filter %q: %s", accountFilter, err)
+		}
+		filterRe = re
+	}
+
+	var accounts []types.Account
+	var paginationControl *string
+	for {
+		listAccounts, err := m.OrganizationsClient.ListAccounts(context.TODO(), &organizations.ListAccountsInput{
+			NextToken: paginationControl,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("listing org accounts: %s", err)
+		}
+
+		for _, account := range listAccounts.Accounts {
+			if account.Status != types.AccountStatusActive {
+				continue
+			}
+			if filterRe != nil && !filterRe.MatchString(*account.Id) && !filterRe.MatchString(*account.Name) {
+				continue
+			}
+			accounts = append(accounts, account)
+		}
+
+		if listAccounts.NextToken != nil {
+			paginationControl = listAccounts.NextToken
+		} else {
+			break
+		}
+	}
+	return accounts, nil
+}
+
+// DiscoverMemberProfiles assumes roleName (e.g. OrganizationAccountAccessRole)
+// into every member account DiscoverMemberAccounts returns, using
+// credbroker's existing assume-role flow, writes the resulting temporary
+// credentials into the default credentials file under synthesized
+// cloudfox-<account>-<role> profile names, and returns those names so the
+// caller can inject them straight into AWSProfiles.
+func (m *OrgModuleClient) DiscoverMemberProfiles(roleName, accountFilter, externalID string) ([]string, error) {
+	accounts, err := m.DiscoverMemberAccounts(accountFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []credbroker.Target
+	for _, account := range accounts {
+		// The management account is already one of AWSProfiles; only the
+		// member accounts need a synthesized profile.
+		if *account.Id == *m.Caller.Account {
+			continue
+		}
+		targets = append(targets, credbroker.Target{AccountID: *account.Id, RoleName: roleName})
+	}
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	broker := &credbroker.Broker{
+		SourceProfile:   m.AWSProfile,
+		Targets:         targets,
+		SessionDuration: time.Hour,
+		MFAToken:        m.MFAToken,
+		ExternalID:      externalID,
+	}
+
+	manifest, err := broker.Run(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("assuming %s into org member accounts: %s", roleName, err)
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("resolving home directory: %s", err)
+	}
+	credentialsFile := filepath.Join(home, ".aws", "credentials")
+	if err := credbroker.WriteCredentialsFile(credentialsFile, manifest); err != nil {
+		return nil, fmt.Errorf("writing org member credentials: %s", err)
+	}
+
+	var profiles []string
+	for _, cred := range manifest.Profiles {
+		profiles = append(profiles, cred.ProfileName)
+	}
+	return profiles, nil
+}
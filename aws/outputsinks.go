@@ -0,0 +1,23 @@
+package aws
+
+import (
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/BishopFox/cloudfox/internal/outputs"
+)
+
+// OutputSinkConfig is the --output-sink/--output-postgres-dsn configuration
+// cli/aws.go resolves once per run. NewLootSink builds a LootSink from it
+// for a given profile/account, the same way internal.AWSConfigFileLoader is
+// called fresh per profile rather than resolved once up front.
+var OutputSinkConfig struct {
+	Sinks           []string
+	Cfg             awssdk.Config
+	OutputDirectory string
+	PostgresDSN     string
+}
+
+// NewLootSink builds a LootSink for profile/account from OutputSinkConfig.
+func NewLootSink(profile, account string) (outputs.LootSink, error) {
+	return outputs.ParseSinks(OutputSinkConfig.Sinks, OutputSinkConfig.Cfg, OutputSinkConfig.OutputDirectory, profile, account, OutputSinkConfig.PostgresDSN)
+}
@@ -0,0 +1,34 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/BishopFox/cloudfox/aws/sdk"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+)
+
+// SimulateCallerActions runs iam:SimulatePrincipalPolicy for callerArn
+// against the given actions and returns the subset that evaluated to
+// implicitDeny/explicitDeny - the set a preflight permission check should
+// warn about before a module burns API calls that will just 403.
+func SimulateCallerActions(IAMClient sdk.AWSIAMClientInterface, callerArn string, actions []string) ([]string, error) {
+	output, err := IAMClient.SimulatePrincipalPolicy(context.TODO(), &iam.SimulatePrincipalPolicyInput{
+		PolicySourceArn: aws.String(callerArn),
+		ActionNames:     actions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("simulating principal policy for %s: %s", callerArn, err)
+	}
+
+	var denied []string
+	for _, result := range output.EvaluationResults {
+		switch result.EvalDecision {
+		case iamTypes.PolicyEvaluationDecisionTypeImplicitDeny, iamTypes.PolicyEvaluationDecisionTypeExplicitDeny:
+			denied = append(denied, aws.ToString(result.EvalActionName))
+		}
+	}
+	return denied, nil
+}
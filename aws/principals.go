@@ -1,6 +1,7 @@
 package aws
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"strconv"
@@ -8,7 +9,12 @@ import (
 
 	"github.com/BishopFox/cloudfox/aws/sdk"
 	"github.com/BishopFox/cloudfox/internal"
+	"github.com/BishopFox/cloudfox/internal/outputsink"
+	"github.com/BishopFox/cloudfox/internal/partition"
+	"github.com/BishopFox/cloudfox/internal/securityhub"
+	"github.com/BishopFox/cloudfox/internal/tablecols"
 	"github.com/aws/aws-sdk-go-v2/aws"
+	securityhubsdk "github.com/aws/aws-sdk-go-v2/service/securityhub"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/sirupsen/logrus"
 )
@@ -17,10 +23,18 @@ type IamPrincipalsModule struct {
 	// General configuration data
 	IAMClient sdk.AWSIAMClientInterface
 
-	Caller        sts.GetCallerIdentityOutput
-	AWSRegions    []string
-	AWSOutputType string
-	AWSTableCols  string
+	Caller           sts.GetCallerIdentityOutput
+	AWSRegions       []string
+	AWSOutputType    string
+	AWSTableCols     string
+	AWSColumnsPreset string
+
+	// SecurityHubClient and SecurityHubRegion are set when AWSOutputType is
+	// "securityhub": SecurityHubRegion is required (ASFF findings are
+	// regional), SecurityHubClient is optional - when nil, ASFF findings
+	// are still written to disk but not imported via BatchImportFindings.
+	SecurityHubClient *securityhubsdk.Client
+	SecurityHubRegion string
 
 	Goroutines int
 	AWSProfile string
@@ -74,6 +88,26 @@ type Role struct {
 	CanPrivEsc       string
 }
 
+// init registers this module's --columns-preset choices with
+// internal/tablecols. "audit" and "privesc" pare the table down to the
+// columns most relevant to reviewing admin/privesc exposure; "minimal" is
+// the narrowest useful view.
+func init() {
+	tablecols.Register("principals", "minimal", []string{"Name", "Arn"})
+	tablecols.Register("principals", "default", []string{
+		"Type", "Name", "Arn", "IsAdminRole?", "CanPrivEscToAdmin?",
+	})
+	tablecols.Register("principals", "wide", []string{
+		"Account", "Type", "Name", "Arn", "IsAdminRole?", "CanPrivEscToAdmin?",
+	})
+	tablecols.Register("principals", "audit", []string{
+		"Account", "Type", "Name", "Arn", "AttachedPolicies", "InlinePolicies",
+	})
+	tablecols.Register("principals", "privesc", []string{
+		"Type", "Name", "Arn", "IsAdminRole?", "CanPrivEscToAdmin?",
+	})
+}
+
 func (m *IamPrincipalsModule) PrintIamPrincipals(outputDirectory string, verbosity int) {
 	// These struct values are used by the output module
 	m.output.Verbosity = verbosity
@@ -119,48 +153,38 @@ func (m *IamPrincipalsModule) PrintIamPrincipals(outputDirectory string, verbosi
 		"CanPrivEscToAdmin?",
 	}
 
-	// If the user specified table columns, use those.
-	// If the user specified -o wide, use the wide default cols for this module.
-	// Otherwise, use the hardcoded default cols for this module.
-	var tableCols []string
-	// If the user specified table columns, use those.
-	if m.AWSTableCols != "" {
-		// If the user specified wide as the output format, use these columns.
-		// remove any spaces between any commas and the first letter after the commas
-		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ", ", ",")
-		m.AWSTableCols = strings.ReplaceAll(m.AWSTableCols, ",  ", ",")
-		tableCols = strings.Split(m.AWSTableCols, ",")
-	} else if m.AWSOutputType == "wide" {
-		tableCols = []string{
-			"Account",
+	// Column selection: --cols wins, then --columns-preset, then -o wide,
+	// then this module's hardcoded default. See internal/tablecols.
+	tableCols := tablecols.Select(tablecols.Options{
+		Module:   m.output.CallingModule,
+		Explicit: m.AWSTableCols,
+		Preset:   m.AWSColumnsPreset,
+		Wide:     m.AWSOutputType == "wide",
+		Default: []string{
 			"Type",
 			"Name",
 			"Arn",
-			//"AttachedPolicies",
-			//"InlinePolicies",
 			"IsAdminRole?",
 			"CanPrivEscToAdmin?",
-		}
-
-		// Otherwise, use the default columns.
-	} else {
-		tableCols = []string{
+		},
+		WideCols: []string{
+			"Account",
 			"Type",
 			"Name",
 			"Arn",
-			// "AttachedPolicies",
-			// "InlinePolicies",
 			"IsAdminRole?",
 			"CanPrivEscToAdmin?",
-		}
-	}
+		},
+	})
 
 	// Remove the pmapper row if there is no pmapper data
 	if m.pmapperError != nil {
 		sharedLogger.Errorf("%s - %s - No pmapper data found for this account. Skipping the pmapper column in the output table.", m.output.CallingModule, m.AWSProfile)
-		tableCols = removeStringFromSlice(tableCols, "CanPrivEscToAdmin?")
+		tableCols = tablecols.Remove(tableCols, "CanPrivEscToAdmin?")
 	}
 
+	var securityHubFindings []securityhub.Finding
+
 	//Table rows
 	for i := range m.Users {
 		if m.pmapperError == nil {
@@ -169,20 +193,27 @@ func (m *IamPrincipalsModule) PrintIamPrincipals(outputDirectory string, verbosi
 			m.Users[i].Admin, m.Users[i].CanPrivEsc = GetIamSimResult(m.SkipAdminCheck, &m.Users[i].Arn, m.iamSimClient, localAdminMap)
 		}
 
-		m.output.Body = append(
-			m.output.Body,
-			[]string{
-				aws.ToString(m.Caller.Account),
-				m.Users[i].Type,
-				m.Users[i].Name,
-				m.Users[i].Arn,
-				strings.Join(m.Users[i].AttachedPolicies, " , "),
-				strings.Join(m.Users[i].InlinePolicies, " , "),
-				m.Users[i].Admin,
-				m.Users[i].CanPrivEsc,
-			},
-		)
-
+		row := []string{
+			aws.ToString(m.Caller.Account),
+			m.Users[i].Type,
+			m.Users[i].Name,
+			m.Users[i].Arn,
+			strings.Join(m.Users[i].AttachedPolicies, " , "),
+			strings.Join(m.Users[i].InlinePolicies, " , "),
+			m.Users[i].Admin,
+			m.Users[i].CanPrivEsc,
+		}
+		m.output.Body = append(m.output.Body, row)
+		emitPrincipalFinding(m.output.CallingModule, aws.ToString(m.Caller.Account), m.AWSProfile, m.Users[i].Name, m.Users[i].Admin, m.Users[i].CanPrivEsc, row)
+		if m.AWSOutputType == "securityhub" {
+			securityHubFindings = append(securityHubFindings, securityhub.Finding{
+				Module:     m.output.CallingModule,
+				Arn:        m.Users[i].Arn,
+				Title:      fmt.Sprintf("IAM user %s", m.Users[i].Name),
+				Admin:      m.Users[i].Admin,
+				CanPrivEsc: m.Users[i].CanPrivEsc,
+			})
+		}
 	}
 
 	for i := range m.Roles {
@@ -191,21 +222,33 @@ func (m *IamPrincipalsModule) PrintIamPrincipals(outputDirectory string, verbosi
 		} else {
 			m.Roles[i].Admin, m.Roles[i].CanPrivEsc = GetIamSimResult(m.SkipAdminCheck, &m.Roles[i].Arn, m.iamSimClient, localAdminMap)
 		}
-		m.output.Body = append(
-			m.output.Body,
-			[]string{
-				aws.ToString(m.Caller.Account),
-				m.Roles[i].Type,
-				m.Roles[i].Name,
-				m.Roles[i].Arn,
-				strings.Join(m.Roles[i].AttachedPolicies, " , "),
-				strings.Join(m.Roles[i].InlinePolicies, " , "),
-				m.Roles[i].Admin,
-				m.Roles[i].CanPrivEsc,
-			},
-		)
+		row := []string{
+			aws.ToString(m.Caller.Account),
+			m.Roles[i].Type,
+			m.Roles[i].Name,
+			m.Roles[i].Arn,
+			strings.Join(m.Roles[i].AttachedPolicies, " , "),
+			strings.Join(m.Roles[i].InlinePolicies, " , "),
+			m.Roles[i].Admin,
+			m.Roles[i].CanPrivEsc,
+		}
+		m.output.Body = append(m.output.Body, row)
+		emitPrincipalFinding(m.output.CallingModule, aws.ToString(m.Caller.Account), m.AWSProfile, m.Roles[i].Name, m.Roles[i].Admin, m.Roles[i].CanPrivEsc, row)
+		if m.AWSOutputType == "securityhub" {
+			securityHubFindings = append(securityHubFindings, securityhub.Finding{
+				Module:     m.output.CallingModule,
+				Arn:        m.Roles[i].Arn,
+				Title:      fmt.Sprintf("IAM role %s", m.Roles[i].Name),
+				Admin:      m.Roles[i].Admin,
+				CanPrivEsc: m.Roles[i].CanPrivEsc,
+			})
+		}
+	}
 
+	if m.AWSOutputType == "securityhub" {
+		m.exportSecurityHubFindings(outputDirectory, securityHubFindings)
 	}
+
 	if len(m.output.Body) > 0 {
 		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, aws.ToString(m.Caller.Account)))
 
@@ -234,6 +277,56 @@ func (m *IamPrincipalsModule) PrintIamPrincipals(outputDirectory string, verbosi
 	fmt.Printf("[%s][%s] For context and next steps: https://github.com/BishopFox/cloudfox/wiki/AWS-Commands#%s\n", cyan(m.output.CallingModule), cyan(m.AWSProfile), m.output.CallingModule)
 }
 
+// emitPrincipalFinding forwards an admin or admin-via-privesc principal to
+// the configured output sinks - the same signal CapeCommand's graph surfaces
+// via a full escalation path, here reported as soon as a single principal is
+// found to be admin or privesc-capable.
+func emitPrincipalFinding(module, account, profile, name, admin, canPrivEsc string, row []string) {
+	switch {
+	case admin == "YES":
+		EmitFinding(outputsink.Finding{
+			Module:   module,
+			Account:  account,
+			Profile:  profile,
+			Title:    fmt.Sprintf("%s is an admin principal", name),
+			Row:      row,
+			Severity: outputsink.Critical,
+		})
+	case canPrivEsc == "YES":
+		EmitFinding(outputsink.Finding{
+			Module:   module,
+			Account:  account,
+			Profile:  profile,
+			Title:    fmt.Sprintf("%s can privilege-escalate to admin", name),
+			Row:      row,
+			Severity: outputsink.High,
+		})
+	}
+}
+
+// exportSecurityHubFindings writes findings as ASFF under outputDirectory
+// and, if m.SecurityHubClient is set, imports them via BatchImportFindings.
+// Errors are logged rather than returned - a Security Hub hiccup shouldn't
+// stop the CSV/table output this module already produces.
+func (m *IamPrincipalsModule) exportSecurityHubFindings(outputDirectory string, findings []securityhub.Finding) {
+	accountID := aws.ToString(m.Caller.Account)
+	callerPartition := partition.FromCallerARN(aws.ToString(m.Caller.Arn))
+	dir := filepath.Join(outputDirectory, "cloudfox-output", "aws", fmt.Sprintf("%s-%s", m.AWSProfile, accountID))
+
+	if err := securityhub.Write(dir, findings, accountID, m.SecurityHubRegion, callerPartition); err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+
+	if m.SecurityHubClient == nil {
+		return
+	}
+	if err := securityhub.Import(context.Background(), m.SecurityHubClient, findings, accountID, m.SecurityHubRegion, callerPartition); err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+}
+
 /* UNUSED CODE BLOCK - PLEASE REVIEW AND DELETE IF APPLICABLE
 func (m *IamPrincipalsModule) executeChecks(wg *sync.WaitGroup) {
 	defer wg.Done()
@@ -253,6 +346,21 @@ func (m *IamPrincipalsModule) addIAMUsersToTable() {
 	var attachedPolicies []string
 	var inlinePolicies []string
 
+	// Dry-run mode previews the API surface instead of calling it - a
+	// placeholder row stands in for whatever ListUsers would have returned.
+	if sdk.DryRun {
+		sdk.RecordDryRunCall("iam", "ListUsers", "", aws.ToString(m.Caller.Account))
+		m.Users = append(m.Users, User{
+			AWSService:       AWSService,
+			Arn:              "<dry-run>",
+			Name:             "<dry-run>",
+			Type:             IAMtype,
+			AttachedPolicies: attachedPolicies,
+			InlinePolicies:   inlinePolicies,
+		})
+		return
+	}
+
 	ListUsers, err := sdk.CachedIamListUsers(m.IAMClient, aws.ToString(m.Caller.Account))
 	if err != nil {
 		m.modLog.Error(err.Error())
@@ -285,6 +393,19 @@ func (m *IamPrincipalsModule) addIAMRolesToTable() {
 	var attachedPolicies []string
 	var inlinePolicies []string
 
+	if sdk.DryRun {
+		sdk.RecordDryRunCall("iam", "ListRoles", "", aws.ToString(m.Caller.Account))
+		m.Roles = append(m.Roles, Role{
+			AWSService:       AWSService,
+			Arn:              "<dry-run>",
+			Name:             "<dry-run>",
+			Type:             IAMtype,
+			AttachedPolicies: attachedPolicies,
+			InlinePolicies:   inlinePolicies,
+		})
+		return
+	}
+
 	ListRoles, err := sdk.CachedIamListRoles(m.IAMClient, aws.ToString(m.Caller.Account))
 	if err != nil {
 		m.modLog.Error(err.Error())
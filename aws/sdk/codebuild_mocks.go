@@ -25,9 +25,120 @@ func (m *MockedCodeBuildClient) BatchGetProjects(ctx context.Context, input *cod
 		Projects: []codeBuildTypes.Project{
 			{
 				Name: aws.String("project1"),
+				Arn:  aws.String("arn:aws:codebuild:us-east-1:123456789012:project/project1"),
+				Source: &codeBuildTypes.ProjectSource{
+					Type:     codeBuildTypes.SourceTypeGithub,
+					Location: aws.String("https://github.com/example-org/project1.git"),
+					Auth: &codeBuildTypes.SourceAuth{
+						Type:     codeBuildTypes.SourceAuthTypeOauth,
+						Resource: aws.String("arn:aws:codebuild:us-east-1:123456789012:token/github"),
+					},
+				},
+				Webhook: &codeBuildTypes.Webhook{
+					Url:          aws.String("https://api.github.com/repos/example-org/project1/hooks/111"),
+					PayloadUrl:   aws.String("https://us-east-1.webhooks.aws/trigger/project1"),
+					BuildType:    codeBuildTypes.WebhookBuildTypeBuild,
+					FilterGroups: [][]codeBuildTypes.WebhookFilter{{{Type: codeBuildTypes.WebhookFilterTypeEvent, Pattern: aws.String("PUSH")}}},
+				},
+				VpcConfig: &codeBuildTypes.VpcConfig{
+					VpcId:            aws.String("vpc-0123456789abcdef0"),
+					Subnets:          []string{"subnet-0123456789abcdef0"},
+					SecurityGroupIds: []string{"sg-0123456789abcdef0"},
+				},
+				SecondarySources: []codeBuildTypes.ProjectSource{
+					{
+						Type:             codeBuildTypes.SourceTypeBitbucket,
+						Location:         aws.String("https://bitbucket.org/example-org/project1-assets.git"),
+						SourceIdentifier: aws.String("assets"),
+						Auth: &codeBuildTypes.SourceAuth{
+							Type:     codeBuildTypes.SourceAuthTypeOauth,
+							Resource: aws.String("arn:aws:codebuild:us-east-1:123456789012:token/bitbucket"),
+						},
+					},
+				},
+				Cache: &codeBuildTypes.ProjectCache{
+					Type:     codeBuildTypes.CacheTypeS3,
+					Location: aws.String("cloudfox-cache-bucket/project1"),
+				},
+				Artifacts: &codeBuildTypes.ProjectArtifacts{
+					Type:     codeBuildTypes.ArtifactsTypeS3,
+					Location: aws.String("cloudfox-artifacts-bucket"),
+					Path:     aws.String("project1"),
+				},
+				SecondaryArtifacts: []codeBuildTypes.ProjectArtifacts{
+					{
+						Type:               codeBuildTypes.ArtifactsTypeS3,
+						Location:           aws.String("cloudfox-artifacts-bucket"),
+						Path:               aws.String("project1/secondary"),
+						ArtifactIdentifier: aws.String("project1-secondary"),
+					},
+				},
+				ServiceRole: aws.String("arn:aws:iam::123456789012:role/codebuild-project1-service-role"),
 			},
 			{
 				Name: aws.String("project2"),
+				Arn:  aws.String("arn:aws:codebuild:us-east-1:123456789012:project/project2"),
+				Source: &codeBuildTypes.ProjectSource{
+					Type:     codeBuildTypes.SourceTypeS3,
+					Location: aws.String("cloudfox-source-bucket/project2.zip"),
+				},
+				Cache: &codeBuildTypes.ProjectCache{
+					Type: codeBuildTypes.CacheTypeNoCache,
+				},
+				Artifacts: &codeBuildTypes.ProjectArtifacts{
+					Type: codeBuildTypes.ArtifactsTypeNoArtifacts,
+				},
+				LogsConfig: &codeBuildTypes.LogsConfig{
+					S3Logs: &codeBuildTypes.S3LogsConfig{
+						Status:   codeBuildTypes.LogsConfigStatusTypeEnabled,
+						Location: aws.String("cloudfox-build-logs/project2"),
+					},
+				},
+				ServiceRole: aws.String("arn:aws:iam::123456789012:role/codebuild-project2-admin-role"),
+			},
+		},
+	}, nil
+}
+
+func (m *MockedCodeBuildClient) ListReportGroups(ctx context.Context, input *codebuild.ListReportGroupsInput, options ...func(*codebuild.Options)) (*codebuild.ListReportGroupsOutput, error) {
+	return &codebuild.ListReportGroupsOutput{
+		ReportGroups: []string{
+			"arn:aws:codebuild:us-east-1:123456789012:report-group/project1-tests",
+		},
+	}, nil
+}
+
+func (m *MockedCodeBuildClient) BatchGetReportGroups(ctx context.Context, input *codebuild.BatchGetReportGroupsInput, options ...func(*codebuild.Options)) (*codebuild.BatchGetReportGroupsOutput, error) {
+	return &codebuild.BatchGetReportGroupsOutput{
+		ReportGroups: []codeBuildTypes.ReportGroup{
+			{
+				Arn:  aws.String("arn:aws:codebuild:us-east-1:123456789012:report-group/project1-tests"),
+				Name: aws.String("project1-tests"),
+				Type: codeBuildTypes.ReportTypeTest,
+				ExportConfig: &codeBuildTypes.ReportExportConfig{
+					ExportConfigType: codeBuildTypes.ReportExportConfigTypeS3,
+					S3Destination: &codeBuildTypes.S3ReportExportConfig{
+						Bucket: aws.String("cloudfox-reports-bucket"),
+						Path:   aws.String("project1-tests"),
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func (m *MockedCodeBuildClient) ListSourceCredentials(ctx context.Context, input *codebuild.ListSourceCredentialsInput, options ...func(*codebuild.Options)) (*codebuild.ListSourceCredentialsOutput, error) {
+	return &codebuild.ListSourceCredentialsOutput{
+		SourceCredentialsInfos: []codeBuildTypes.SourceCredentialsInfo{
+			{
+				Arn:        aws.String("arn:aws:codebuild:us-east-1:123456789012:token/github"),
+				ServerType: codeBuildTypes.ServerTypeGithub,
+				AuthType:   codeBuildTypes.AuthTypeOauth,
+			},
+			{
+				Arn:        aws.String("arn:aws:codebuild:us-east-1:123456789012:token/bitbucket"),
+				ServerType: codeBuildTypes.ServerTypeBitbucket,
+				AuthType:   codeBuildTypes.AuthTypeBasicAuth,
 			},
 		},
 	}, nil
@@ -44,10 +155,136 @@ func (m *MockedCodeBuildClient) GetResourcePolicy(ctx context.Context, input *co
 				"Resource": "*",
 				"Principal": {
 					"AWS": "arn:aws:iam::123456789012:root"
+				}
+			  },
+			  {
+				"Effect": "Allow",
+				"Action": "codebuild:StartBuild",
+				"Resource": "*",
+				"Principal": {
+					"AWS": "*"
 				},
+				"Condition": {
+					"StringEquals": {
+						"aws:PrincipalOrgID": "o-abc123xyz"
+					}
+				}
+			  },
+			  {
+				"Effect": "Allow",
+				"Action": "codebuild:BatchGetBuilds",
+				"Resource": "*",
+				"Principal": {
+					"Service": "events.amazonaws.com"
+				}
+			  },
+			  {
+				"Effect": "Deny",
+				"NotAction": "codebuild:BatchGetProjects",
+				"Resource": "*",
+				"Principal": {
+					"AWS": "arn:aws:iam::123456789012:role/readonly"
+				}
+			  },
+			  {
+				"Effect": "Allow",
+				"Action": "codebuild:BatchGetProjects",
+				"Resource": "*",
+				"NotPrincipal": {
+					"AWS": "arn:aws:iam::123456789012:root"
+				}
 			  }
 			]
 		  }
 		`),
 	}, nil
 }
+
+func (m *MockedCodeBuildClient) ListBuilds(ctx context.Context, input *codebuild.ListBuildsInput, options ...func(*codebuild.Options)) (*codebuild.ListBuildsOutput, error) {
+	return &codebuild.ListBuildsOutput{
+		Ids: []string{
+			"project1:11111111-1111-1111-1111-111111111111",
+			"project2:22222222-2222-2222-2222-222222222222",
+		},
+	}, nil
+}
+
+func (m *MockedCodeBuildClient) ListBuildsForProject(ctx context.Context, input *codebuild.ListBuildsForProjectInput, options ...func(*codebuild.Options)) (*codebuild.ListBuildsForProjectOutput, error) {
+	return &codebuild.ListBuildsForProjectOutput{
+		Ids: []string{
+			aws.ToString(input.ProjectName) + ":11111111-1111-1111-1111-111111111111",
+		},
+	}, nil
+}
+
+func (m *MockedCodeBuildClient) BatchGetBuilds(ctx context.Context, input *codebuild.BatchGetBuildsInput, options ...func(*codebuild.Options)) (*codebuild.BatchGetBuildsOutput, error) {
+	return &codebuild.BatchGetBuildsOutput{
+		Builds: []codeBuildTypes.Build{
+			{
+				Id:            aws.String("project1:11111111-1111-1111-1111-111111111111"),
+				ProjectName:   aws.String("project1"),
+				Initiator:     aws.String("arn:aws:iam::123456789012:user/developer1"),
+				SourceVersion: aws.String("refs/heads/main"),
+				BuildStatus:   codeBuildTypes.StatusTypeSucceeded,
+				Environment: &codeBuildTypes.ProjectEnvironment{
+					EnvironmentVariables: []codeBuildTypes.EnvironmentVariable{
+						{
+							Name:  aws.String("DATABASE_PASSWORD"),
+							Value: aws.String("SuperSecretPassword123!"),
+							Type:  codeBuildTypes.EnvironmentVariableTypePlaintext,
+						},
+						{
+							Name:  aws.String("API_TOKEN"),
+							Value: aws.String("/prod/codebuild/api-token"),
+							Type:  codeBuildTypes.EnvironmentVariableTypeParameterStore,
+						},
+						{
+							Name:  aws.String("DOCKERHUB_CREDS"),
+							Value: aws.String("arn:aws:secretsmanager:us-east-1:123456789012:secret:dockerhub-creds-AbCdEf"),
+							Type:  codeBuildTypes.EnvironmentVariableTypeSecretsManager,
+						},
+					},
+				},
+				Logs: &codeBuildTypes.LogsLocation{
+					CloudWatchLogs: &codeBuildTypes.CloudWatchLogsConfig{
+						GroupName:  aws.String("/aws/codebuild/project1"),
+						StreamName: aws.String("11111111-1111-1111-1111-111111111111"),
+						Status:     codeBuildTypes.LogsConfigStatusTypeEnabled,
+					},
+					S3Logs: &codeBuildTypes.S3LogsConfig{
+						Location: aws.String("cloudfox-build-logs/project1/build.log"),
+						Status:   codeBuildTypes.LogsConfigStatusTypeDisabled,
+					},
+				},
+				ExportedEnvironmentVariables: []codeBuildTypes.ExportedEnvironmentVariable{
+					{
+						Name:  aws.String("IMAGE_TAG"),
+						Value: aws.String("sha-1234567"),
+					},
+				},
+			},
+			{
+				Id:            aws.String("project2:22222222-2222-2222-2222-222222222222"),
+				ProjectName:   aws.String("project2"),
+				Initiator:     aws.String("codebuild-cloudwatch-events-rule"),
+				SourceVersion: aws.String("arn:aws:s3:::source-bucket/source.zip"),
+				BuildStatus:   codeBuildTypes.StatusTypeFailed,
+				Environment: &codeBuildTypes.ProjectEnvironment{
+					EnvironmentVariables: []codeBuildTypes.EnvironmentVariable{
+						{
+							Name:  aws.String("AWS_DEFAULT_REGION"),
+							Value: aws.String("us-east-1"),
+							Type:  codeBuildTypes.EnvironmentVariableTypePlaintext,
+						},
+					},
+				},
+				Logs: &codeBuildTypes.LogsLocation{
+					CloudWatchLogs: &codeBuildTypes.CloudWatchLogsConfig{
+						GroupName: aws.String("/aws/codebuild/project2"),
+						Status:    codeBuildTypes.LogsConfigStatusTypeEnabled,
+					},
+				},
+			},
+		},
+	}, nil
+}
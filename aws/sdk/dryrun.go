@@ -0,0 +1,55 @@
+package sdk
+
+import (
+	"fmt"
+	"sync"
+)
+
+// DryRun is set from --dry-run. Only the principals module reads it: when
+// true, its ListUsers and ListRoles calls are recorded with RecordDryRunCall
+// and a <dry-run> placeholder row takes the place of the API result. The
+// Cached* functions in this package do not check it, so every other module
+// still calls the API.
+var DryRun bool
+
+// PlannedCall is one API call a dry run would have made, logged instead of
+// executed so --output json can emit the plan as structured data.
+type PlannedCall struct {
+	Service   string `json:"service"`
+	Operation string `json:"operation"`
+	Region    string `json:"region"`
+	Input     string `json:"input"`
+}
+
+var (
+	plannedCallsMu sync.Mutex
+	plannedCalls   []PlannedCall
+)
+
+// RecordDryRunCall logs a planned API call instead of making it. service and
+// operation identify the call (e.g. "iam", "ListUsers"), region is the
+// region it would have targeted ("" for global services), and input is a
+// short human-readable summary of the call's parameters.
+func RecordDryRunCall(service, operation, region, input string) PlannedCall {
+	call := PlannedCall{Service: service, Operation: operation, Region: region, Input: input}
+
+	plannedCallsMu.Lock()
+	plannedCalls = append(plannedCalls, call)
+	plannedCallsMu.Unlock()
+
+	label := service + ":" + operation
+	if region != "" {
+		label = fmt.Sprintf("%s (%s)", label, region)
+	}
+	fmt.Printf("[dry-run] %s %s\n", label, input)
+
+	return call
+}
+
+// PlannedCalls returns every call recorded so far by RecordDryRunCall, used
+// to emit the full API-surface preview once a dry run finishes.
+func PlannedCalls() []PlannedCall {
+	plannedCallsMu.Lock()
+	defer plannedCallsMu.Unlock()
+	return append([]PlannedCall(nil), plannedCalls...)
+}
@@ -0,0 +1,75 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Paginate drives a single AWS API's marker-based pagination to completion
+// and flattens every page into one slice, so callers don't each hand-roll
+// their own "var PaginationControl *string; for { ... }" loop. Page is the
+// SDK's page-shaped output struct (e.g. *cloudfront.ListDistributionsOutput)
+// and Item is the element type that page carries (e.g.
+// cloudfrontTypes.DistributionSummary).
+//
+// fetch makes one page request given the current marker (nil for the first
+// page). items extracts that page's elements, and nextMarker extracts the
+// token to pass to the next fetch call, or nil once there are no more pages.
+func Paginate[Page any, Item any](ctx context.Context, fetch func(ctx context.Context, marker *string) (Page, error), items func(Page) []Item, nextMarker func(Page) *string) ([]Item, error) {
+	var all []Item
+	var marker *string
+
+	for {
+		page, err := fetch(ctx, marker)
+		if err != nil {
+			return all, err
+		}
+
+		all = append(all, items(page)...)
+
+		marker = nextMarker(page)
+		if marker == nil {
+			break
+		}
+	}
+
+	return all, nil
+}
+
+// Acceptor is one condition WaitFor checks after each poll - modeled on the
+// acceptor lists the AWS SDK's generated waiters use internally, just
+// exposed here so a Cached* wrapper can build a one-off waiter without
+// depending on a service's (often unexported) generated waiter type. Match
+// returns done=true once it can tell WaitFor to stop polling, and the error
+// WaitFor should return when it does (nil for a successful match).
+type Acceptor[Output any] struct {
+	Match func(out Output, err error) (done bool, result error)
+}
+
+// WaitFor polls fetch every interval until one of acceptors matches or
+// maxAttempts is reached, returning the last output it saw alongside
+// whichever error the matching acceptor (or the attempt-limit fallback)
+// produced. maxAttempts <= 0 means retry forever until ctx is done.
+func WaitFor[Output any](ctx context.Context, fetch func(ctx context.Context) (Output, error), acceptors []Acceptor[Output], interval time.Duration, maxAttempts int) (Output, error) {
+	var out Output
+	var err error
+
+	for attempt := 1; maxAttempts <= 0 || attempt <= maxAttempts; attempt++ {
+		out, err = fetch(ctx)
+
+		for _, acceptor := range acceptors {
+			if done, result := acceptor.Match(out, err); done {
+				return out, result
+			}
+		}
+
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return out, ctx.Err()
+		}
+	}
+
+	return out, fmt.Errorf("giving up after %d attempts: %w", maxAttempts, err)
+}
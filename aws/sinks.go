@@ -0,0 +1,29 @@
+package aws
+
+import (
+	"github.com/BishopFox/cloudfox/internal/outputsink"
+)
+
+// Sinks is where cli/aws.go's --sink flag ends up - every module-level
+// finding (a public endpoint, an admin escalation path) is forwarded here
+// rather than each module owning its own notification logic. It defaults to
+// printing to stdout so a run with no --sink behaves like it always did.
+var Sinks outputsink.Sink = outputsink.StdoutSink{}
+
+// SinkSeverity holds the per-module minimum severity set by --sink-severity,
+// keyed by the module's output.CallingModule name (e.g. "endpoints",
+// "principals"). A module with no entry forwards every finding it raises.
+var SinkSeverity = map[string]outputsink.Severity{}
+
+// EmitFinding applies module's configured severity threshold, if any, and
+// forwards finding to Sinks. Modules call this instead of talking to Sinks
+// directly so the threshold check lives in one place.
+func EmitFinding(finding outputsink.Finding) {
+	sink := outputsink.Sink(Sinks)
+	if min, ok := SinkSeverity[finding.Module]; ok {
+		sink = outputsink.FilterSink{Min: min, Sink: sink}
+	}
+	if err := sink.Emit(finding); err != nil {
+		sharedLogger.Errorf("%s - failed to emit finding %q: %s", finding.Module, finding.Title, err)
+	}
+}
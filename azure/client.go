@@ -0,0 +1,143 @@
+// Package azure holds the subscription-scoped enumeration modules for
+// "cloudfox az ..." (the AWS/GCP analog would be the aws/gcp packages). Most
+// of cli/azure.go's call sites (AzWhoamiCommand, AzInventoryCommand,
+// AzRBACCommand, and the ARM client plumbing they'd need) aren't defined
+// anywhere in this tree - the files here add the IAM principals, Key Vault
+// and NSG modules and the client/credential plumbing they share, not a
+// reconstruction of the rest of the package.
+package azure
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/subscription/armsubscription"
+	"github.com/fatih/color"
+
+	"github.com/BishopFox/cloudfox/internal/azureenv"
+)
+
+var cyan = color.New(color.FgCyan).SprintFunc()
+
+// Client is the per-run Azure handle, the az analog of aws.PmapperModule's
+// Caller or gcpauth.GCPClient: built once, then threaded into each module.
+type Client struct {
+	TenantID    string
+	Credential  azcore.TokenCredential
+	Environment azureenv.Environment
+
+	subscriptionsClient *armsubscription.SubscriptionsClient
+}
+
+// azureCloudConfiguration translates an azureenv.Environment into the
+// azcore/cloud.Configuration every ARM/data-plane client constructor
+// accepts as a ClientOptions.Cloud, so --cloud only has to be resolved
+// once per Client instead of at every call site.
+func azureCloudConfiguration(env azureenv.Environment) cloud.Configuration {
+	if env.Name == azureenv.Public.Name {
+		return cloud.AzurePublic
+	}
+	return cloud.Configuration{
+		ActiveDirectoryAuthorityHost: env.ActiveDirectoryEndpoint,
+		Services: map[cloud.ServiceName]cloud.ServiceConfiguration{
+			cloud.ResourceManager: {
+				Endpoint: env.ResourceManagerEndpoint,
+				Audience: env.ResourceManagerEndpoint,
+			},
+		},
+	}
+}
+
+// NewClient resolves a credential via CredentialProvider (--auth-file/
+// AZURE_AUTH_LOCATION, AZURE_CLIENT_ID/SECRET/TENANT_ID,
+// AZURE_FEDERATED_TOKEN_FILE, then the az login session, in that order)
+// and builds the subscriptions
+// client every module needs. tenantID wins over whatever the credential
+// source itself resolved, so an explicit --tenant still overrides an
+// --auth-file written for a different tenant. cloudName is the --cloud flag
+// value ("" resolves to the public cloud); see internal/azureenv for the
+// supported sovereign/national clouds.
+func NewClient(tenantID, cloudName, authFilePath string) (*Client, error) {
+	env, err := azureenv.Lookup(cloudName)
+	if err != nil {
+		return nil, err
+	}
+	cloudCfg := azureCloudConfiguration(env)
+
+	resolved, err := CredentialProvider(authFilePath, azcore.ClientOptions{Cloud: cloudCfg})
+	if err != nil {
+		return nil, err
+	}
+	if tenantID == "" {
+		tenantID = resolved.TenantID
+	}
+
+	subsClient, err := armsubscription.NewSubscriptionsClient(resolved.Credential, &arm.ClientOptions{ClientOptions: azcore.ClientOptions{Cloud: cloudCfg}})
+	if err != nil {
+		return nil, fmt.Errorf("creating subscriptions client: %s", err)
+	}
+
+	return &Client{TenantID: tenantID, Credential: resolved.Credential, Environment: env, subscriptionsClient: subsClient}, nil
+}
+
+// ListSubscriptions returns every subscription the credential can see.
+// armsubscription.Subscription (what NewListPager returns) carries no
+// tenant ID of its own - the Subscriptions - List API is already
+// implicitly scoped to whatever tenant the credential authenticated
+// against, so there's nothing to filter against c.TenantID here.
+func (c *Client) ListSubscriptions(ctx context.Context) ([]string, error) {
+	var subscriptionIDs []string
+
+	pager := c.subscriptionsClient.NewListPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing subscriptions: %s", err)
+		}
+		for _, sub := range page.Value {
+			if sub.SubscriptionID != nil {
+				subscriptionIDs = append(subscriptionIDs, *sub.SubscriptionID)
+			}
+		}
+	}
+	return subscriptionIDs, nil
+}
+
+// ResolveSubscriptions is the az equivalent of the aws package's AWSProfiles
+// resolution: explicit takes priority, "all" enumerates every subscription
+// the credential can see, and it's an error to pass neither.
+func ResolveSubscriptions(ctx context.Context, client *Client, explicit []string, all bool) ([]string, error) {
+	if len(explicit) > 0 {
+		return explicit, nil
+	}
+	if !all {
+		return nil, fmt.Errorf("no subscription specified: pass --subscription, a --subscriptions-list file, or --all-subscriptions")
+	}
+	return client.ListSubscriptions(ctx)
+}
+
+// ReadSubscriptionsList reads newline-separated subscription IDs from path,
+// the az analog of internal/gcp.GetSelectedGCPProfiles for --subscriptions-list.
+func ReadSubscriptionsList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %s", path, err)
+	}
+	defer file.Close()
+
+	var subscriptionIDs []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		subscriptionID := strings.TrimSpace(scanner.Text())
+		if subscriptionID != "" {
+			subscriptionIDs = append(subscriptionIDs, subscriptionID)
+		}
+	}
+	return subscriptionIDs, scanner.Err()
+}
@@ -0,0 +1,110 @@
+package azure
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// sdkAuthFile is the subset of the JSON document emitted by
+// "az ad sp create-for-rbac --sdk-auth" (and accepted by the legacy
+// autorest "auth file" loaders) that CredentialProvider needs to build a
+// client secret credential without an interactive "az login" session.
+type sdkAuthFile struct {
+	ClientID                string `json:"clientId"`
+	ClientSecret            string `json:"clientSecret"`
+	TenantID                string `json:"tenantId"`
+	SubscriptionID          string `json:"subscriptionId"`
+	ActiveDirectoryEndpoint string `json:"activeDirectoryEndpointUrl"`
+	ResourceManagerEndpoint string `json:"resourceManagerEndpointUrl"`
+}
+
+// ResolvedCredential is what CredentialProvider hands back: the credential
+// itself plus whatever it learned about the tenant/subscription/cloud
+// along the way, so callers don't also have to pass --tenant when an
+// --auth-file or AZURE_AUTH_LOCATION already says which tenant it's for.
+type ResolvedCredential struct {
+	Credential     azcore.TokenCredential
+	TenantID       string
+	SubscriptionID string
+}
+
+// CredentialProvider resolves a single Azure credential for the whole
+// run, in the order a CI/red-team pipeline would want them tried:
+//
+//  1. authFilePath (or AZURE_AUTH_LOCATION) - the JSON file
+//     "az ad sp create-for-rbac --sdk-auth" emits.
+//  2. AZURE_CLIENT_ID/AZURE_CLIENT_SECRET/AZURE_TENANT_ID - a plain
+//     service-principal secret, as every other Azure SDK/Terraform/etc.
+//     already reads them.
+//  3. AZURE_FEDERATED_TOKEN_FILE (with AZURE_CLIENT_ID/AZURE_TENANT_ID) -
+//     workload identity federation, the no-secret option used by AKS
+//     pods and GitHub Actions OIDC.
+//  4. azidentity.NewDefaultAzureCredential - the interactive "az login"
+//     fallback every subcommand used before this change.
+//
+// Every cli/azure.go Run func should go through this instead of calling
+// NewClient's credential construction directly, so --auth-file behaves
+// identically for whoami, iam-principals, or any future subcommand.
+func CredentialProvider(authFilePath string, cloudCfg azcore.ClientOptions) (ResolvedCredential, error) {
+	if authFilePath == "" {
+		authFilePath = os.Getenv("AZURE_AUTH_LOCATION")
+	}
+	if authFilePath != "" {
+		return credentialFromAuthFile(authFilePath, cloudCfg)
+	}
+
+	if clientID, clientSecret, tenantID := os.Getenv("AZURE_CLIENT_ID"), os.Getenv("AZURE_CLIENT_SECRET"), os.Getenv("AZURE_TENANT_ID"); clientID != "" && clientSecret != "" && tenantID != "" {
+		cred, err := azidentity.NewClientSecretCredential(tenantID, clientID, clientSecret, &azidentity.ClientSecretCredentialOptions{ClientOptions: cloudCfg})
+		if err != nil {
+			return ResolvedCredential{}, fmt.Errorf("building credential from AZURE_CLIENT_ID/AZURE_CLIENT_SECRET/AZURE_TENANT_ID: %s", err)
+		}
+		return ResolvedCredential{Credential: cred, TenantID: tenantID}, nil
+	}
+
+	if federatedTokenFile := os.Getenv("AZURE_FEDERATED_TOKEN_FILE"); federatedTokenFile != "" {
+		clientID, tenantID := os.Getenv("AZURE_CLIENT_ID"), os.Getenv("AZURE_TENANT_ID")
+		cred, err := azidentity.NewWorkloadIdentityCredential(&azidentity.WorkloadIdentityCredentialOptions{
+			ClientOptions: cloudCfg,
+			ClientID:      clientID,
+			TenantID:      tenantID,
+			TokenFilePath: federatedTokenFile,
+		})
+		if err != nil {
+			return ResolvedCredential{}, fmt.Errorf("building workload identity credential from AZURE_FEDERATED_TOKEN_FILE: %s", err)
+		}
+		return ResolvedCredential{Credential: cred, TenantID: tenantID}, nil
+	}
+
+	cred, err := azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{ClientOptions: cloudCfg})
+	if err != nil {
+		return ResolvedCredential{}, fmt.Errorf("loading Azure credentials: %s", err)
+	}
+	return ResolvedCredential{Credential: cred}, nil
+}
+
+func credentialFromAuthFile(path string, cloudCfg azcore.ClientOptions) (ResolvedCredential, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return ResolvedCredential{}, fmt.Errorf("reading --auth-file %s: %s", path, err)
+	}
+
+	var authFile sdkAuthFile
+	if err := json.Unmarshal(raw, &authFile); err != nil {
+		return ResolvedCredential{}, fmt.Errorf("parsing --auth-file %s: %s", path, err)
+	}
+
+	cred, err := azidentity.NewClientSecretCredential(authFile.TenantID, authFile.ClientID, authFile.ClientSecret, &azidentity.ClientSecretCredentialOptions{ClientOptions: cloudCfg})
+	if err != nil {
+		return ResolvedCredential{}, fmt.Errorf("building credential from --auth-file %s: %s", path, err)
+	}
+
+	return ResolvedCredential{
+		Credential:     cred,
+		TenantID:       authFile.TenantID,
+		SubscriptionID: authFile.SubscriptionID,
+	}, nil
+}
@@ -0,0 +1,381 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/sirupsen/logrus"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/BishopFox/cloudfox/internal/azoutput"
+	"github.com/BishopFox/cloudfox/internal/privesc"
+)
+
+// IamPrincipalsModule enumerates role assignments, managed identities, and
+// custom role definitions across one or more subscriptions - the Azure
+// analog of aws.IamPrincipalsModule and gcp.PrincipalsModule. It does not
+// cover service principals: that needs the Microsoft Graph API
+// (github.com/microsoftgraph/msgraph-sdk-go), a large dependency this
+// change doesn't pull in just for one column's worth of rows. Role
+// assignments already cover the admin/priv-esc-relevant signal the other
+// providers' IAM principals modules exist for.
+type IamPrincipalsModule struct {
+	Client        *Client
+	Subscriptions []string
+	AzOutputType  string
+	WrapTable     bool
+
+	MappedPrincipals []MappedAzurePrincipal
+	CommandCounter   internal.CommandCounter
+
+	roleAssignments []privesc.AzureRoleAssignment
+
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+// MappedAzurePrincipal mirrors aws.IamPrincipalsModule's row shape
+// (Type/Name/Arn/IsAdmin?/CanPrivEscToAdmin?) with ResourceID standing in
+// for Arn, since Azure resources don't have ARNs.
+type MappedAzurePrincipal struct {
+	Subscription      string
+	Type              string
+	Name              string
+	ResourceID        string
+	IsAdmin           string
+	CanPrivEscToAdmin string
+}
+
+func (m *IamPrincipalsModule) PrintIamPrincipals(outputDirectory string, verbosity int) {
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "principals"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+
+	ctx := context.Background()
+	for _, subscriptionID := range m.Subscriptions {
+		fmt.Printf("[%s][%s] Enumerating IAM principals\n", cyan(m.output.CallingModule), cyan(subscriptionID))
+
+		m.CommandCounter.Total++
+		if err := m.getRoleAssignments(ctx, subscriptionID); err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		}
+		if err := m.getCustomRoleDefinitions(ctx, subscriptionID); err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		}
+		if err := m.getManagedIdentities(ctx, subscriptionID); err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		}
+	}
+
+	if err := m.setPrivescResults(); err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+
+	m.output.Headers = []string{
+		"Subscription",
+		"Type",
+		"Name",
+		"ResourceID",
+		"IsAdmin?",
+		"CanPrivEscToAdmin?",
+	}
+
+	for _, principal := range m.MappedPrincipals {
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				principal.Subscription,
+				principal.Type,
+				principal.Name,
+				principal.ResourceID,
+				principal.IsAdmin,
+				principal.CanPrivEscToAdmin,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "azure", m.Client.TenantID)
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: m.output.Headers,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.Client.TenantID
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "azure", m.Client.TenantID)
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s] %s IAM principals found.\n", cyan(m.output.CallingModule), strconv.Itoa(len(m.output.Body)))
+	} else {
+		fmt.Printf("[%s] No IAM principals found, skipping the creation of an output file.\n", cyan(m.output.CallingModule))
+	}
+
+	if err := m.writeJSON(outputDirectory); err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+}
+
+// writeJSON emits one azoutput.Document per principal when --output is
+// "json" or "jsonl", flagging admin and priv-esc-capable principals as
+// findings so a downstream graph loader doesn't have to re-derive them
+// from IsAdmin/CanPrivEscToAdmin string columns.
+func (m *IamPrincipalsModule) writeJSON(outputDirectory string) error {
+	writer, err := azoutput.NewWriter(m.AzOutputType, outputDirectory, m.Client.TenantID, m.output.CallingModule)
+	if err != nil || writer == nil {
+		return err
+	}
+
+	for _, principal := range m.MappedPrincipals {
+		doc := azoutput.Document{
+			Module:       m.output.CallingModule,
+			Subscription: principal.Subscription,
+			Resource: map[string]string{
+				"type":       principal.Type,
+				"name":       principal.Name,
+				"resourceId": principal.ResourceID,
+			},
+		}
+		if principal.IsAdmin == "YES" {
+			doc.Findings = append(doc.Findings, azoutput.Finding{
+				RuleID:   "azure-admin-principal",
+				Severity: "high",
+				Category: "iam",
+				Evidence: fmt.Sprintf("%s %s is assigned an Owner/Contributor-equivalent role", principal.Type, principal.Name),
+			})
+		}
+		if principal.CanPrivEscToAdmin == "YES" {
+			doc.Findings = append(doc.Findings, azoutput.Finding{
+				RuleID:   "azure-privesc-to-admin",
+				Severity: "critical",
+				Category: "privesc",
+				Evidence: fmt.Sprintf("%s %s can reach an admin-equivalent role through its assignments", principal.Type, principal.Name),
+			})
+		}
+		if err := writer.WriteDocument(doc); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+func (m *IamPrincipalsModule) getRoleAssignments(ctx context.Context, subscriptionID string) error {
+	client, err := armauthorization.NewRoleAssignmentsClient(subscriptionID, m.Client.Credential, nil)
+	if err != nil {
+		return fmt.Errorf("creating role assignments client for %s: %s", subscriptionID, err)
+	}
+	defClient, err := armauthorization.NewRoleDefinitionsClient(m.Client.Credential, nil)
+	if err != nil {
+		return fmt.Errorf("creating role definitions client for %s: %s", subscriptionID, err)
+	}
+
+	scope := "/subscriptions/" + subscriptionID
+	pager := client.NewListForScopePager(scope, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("listing role assignments for %s: %s", subscriptionID, err)
+		}
+		for _, assignment := range page.Value {
+			if assignment.Properties == nil {
+				continue
+			}
+
+			roleName := ptrString(assignment.Properties.RoleDefinitionID)
+			isAdmin := "No"
+			if assignment.Properties.RoleDefinitionID != nil {
+				roleDef, err := defClient.Get(ctx, scope, lastSegment(*assignment.Properties.RoleDefinitionID), nil)
+				if err == nil && roleDef.Properties != nil && roleDef.Properties.RoleName != nil {
+					roleName = *roleDef.Properties.RoleName
+					isAdmin = isAzureAdminRole(roleName)
+				}
+			}
+
+			principalID := ptrString(assignment.Properties.PrincipalID)
+			m.MappedPrincipals = append(m.MappedPrincipals, MappedAzurePrincipal{
+				Subscription:      subscriptionID,
+				Type:              "RoleAssignment",
+				Name:              roleName,
+				ResourceID:        principalID,
+				IsAdmin:           isAdmin,
+				CanPrivEscToAdmin: "Unknown",
+			})
+			m.roleAssignments = append(m.roleAssignments, privesc.AzureRoleAssignment{PrincipalID: principalID, RoleName: roleName})
+		}
+	}
+	return nil
+}
+
+// setPrivescResults builds an internal/privesc reachability graph from the
+// role assignments collected by getRoleAssignments and fills in
+// CanPrivEscToAdmin? for each RoleAssignment row.
+func (m *IamPrincipalsModule) setPrivescResults() error {
+	graph, err := privesc.BuildAzureGraph(m.roleAssignments)
+	if err != nil {
+		return fmt.Errorf("building privesc graph: %s", err)
+	}
+
+	for i := range m.MappedPrincipals {
+		if m.MappedPrincipals[i].Type != "RoleAssignment" {
+			continue
+		}
+		_, m.MappedPrincipals[i].CanPrivEscToAdmin = privesc.GetAzurePrivescResults(graph, m.MappedPrincipals[i].ResourceID)
+	}
+	return nil
+}
+
+func (m *IamPrincipalsModule) getCustomRoleDefinitions(ctx context.Context, subscriptionID string) error {
+	client, err := armauthorization.NewRoleDefinitionsClient(m.Client.Credential, nil)
+	if err != nil {
+		return fmt.Errorf("creating role definitions client for %s: %s", subscriptionID, err)
+	}
+
+	scope := "/subscriptions/" + subscriptionID
+	pager := client.NewListPager(scope, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("listing role definitions for %s: %s", subscriptionID, err)
+		}
+		for _, def := range page.Value {
+			if def.Properties == nil || def.Properties.RoleType == nil || *def.Properties.RoleType != "CustomRole" {
+				continue
+			}
+			m.MappedPrincipals = append(m.MappedPrincipals, MappedAzurePrincipal{
+				Subscription:      subscriptionID,
+				Type:              "CustomRoleDefinition",
+				Name:              ptrString(def.Properties.RoleName),
+				ResourceID:        ptrString(def.ID),
+				IsAdmin:           isAzureAdminPermissions(def.Properties.Permissions),
+				CanPrivEscToAdmin: "Unknown",
+			})
+		}
+	}
+	return nil
+}
+
+func (m *IamPrincipalsModule) getManagedIdentities(ctx context.Context, subscriptionID string) error {
+	client, err := armresources.NewClient(subscriptionID, m.Client.Credential, nil)
+	if err != nil {
+		return fmt.Errorf("creating resources client for %s: %s", subscriptionID, err)
+	}
+
+	filter := "resourceType eq 'Microsoft.ManagedIdentity/userAssignedIdentities'"
+	pager := client.NewListPager(&armresources.ClientListOptions{Filter: &filter})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("listing managed identities for %s: %s", subscriptionID, err)
+		}
+		for _, resource := range page.Value {
+			m.MappedPrincipals = append(m.MappedPrincipals, MappedAzurePrincipal{
+				Subscription:      subscriptionID,
+				Type:              "ManagedIdentity",
+				Name:              ptrString(resource.Name),
+				ResourceID:        ptrString(resource.ID),
+				IsAdmin:           "Unknown",
+				CanPrivEscToAdmin: "Unknown",
+			})
+		}
+	}
+	return nil
+}
+
+// isAzureAdminRole flags the two built-in roles with unrestricted control
+// plane access, the Azure equivalent of the AWS/GCP modules' "is this an
+// admin-equivalent role" check.
+func isAzureAdminRole(roleName string) string {
+	switch roleName {
+	case "Owner", "Contributor":
+		return "YES"
+	default:
+		return "No"
+	}
+}
+
+// isAzureAdminPermissions flags a custom role as admin-equivalent if any of
+// its permission blocks grants "*" with nothing excluded via NotActions.
+func isAzureAdminPermissions(permissions []*armauthorization.Permission) string {
+	for _, perm := range permissions {
+		if perm == nil || len(perm.NotActions) > 0 {
+			continue
+		}
+		for _, action := range perm.Actions {
+			if action != nil && *action == "*" {
+				return "YES"
+			}
+		}
+	}
+	return "No"
+}
+
+// AzIamPrincipalsCommand is the cli package's entrypoint for "cloudfox az
+// iam-principals", following the same free-function convention as
+// AzWhoamiCommand/AzInventoryCommand/AzRBACCommand. subscription and
+// subscriptionsListFile are combined into the explicit subscription list
+// when set; otherwise allSubscriptions must be true to enumerate every
+// subscription the credential can see.
+func AzIamPrincipalsCommand(tenantID, subscription, subscriptionsListFile, cloudName, authFilePath, outputFormat string, allSubscriptions bool, outputDirectory, version string, verbosity int, wrapTable bool) error {
+	client, err := NewClient(tenantID, cloudName, authFilePath)
+	if err != nil {
+		return err
+	}
+
+	var explicit []string
+	if subscription != "" {
+		explicit = append(explicit, subscription)
+	}
+	if subscriptionsListFile != "" {
+		fromFile, err := ReadSubscriptionsList(subscriptionsListFile)
+		if err != nil {
+			return err
+		}
+		explicit = append(explicit, fromFile...)
+	}
+
+	ctx := context.Background()
+	subscriptions, err := ResolveSubscriptions(ctx, client, explicit, allSubscriptions)
+	if err != nil {
+		return err
+	}
+
+	m := IamPrincipalsModule{
+		Client:        client,
+		Subscriptions: subscriptions,
+		AzOutputType:  outputFormat,
+		WrapTable:     wrapTable,
+	}
+	m.PrintIamPrincipals(outputDirectory, verbosity)
+	return nil
+}
+
+func ptrString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func lastSegment(id string) string {
+	parts := strings.Split(id, "/")
+	return parts[len(parts)-1]
+}
@@ -0,0 +1,469 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azcertificates"
+	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azkeys"
+	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azsecrets"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/keyvault/armkeyvault"
+	"github.com/sirupsen/logrus"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/BishopFox/cloudfox/internal/azoutput"
+)
+
+// KeyVaultModule enumerates Key Vaults per subscription, the az analog of
+// StorageModule for secret material instead of blobs: vault-level network
+// ACLs and soft-delete/purge-protection posture come straight off the
+// management-plane vault object, while the secret/key/certificate
+// inventory (names only - versions are not listed - unless DumpSecrets is
+// set) needs a second, data-plane call per vault that the caller's
+// credential may not have permission for - that failure is recorded as
+// "AccessDenied" in the row's cell rather than treated as fatal, since
+// "no data-plane access" is the common case for an
+// RBAC role that's Reader-only on the vault resource.
+type KeyVaultModule struct {
+	Client        *Client
+	Subscriptions []string
+	AzOutputType  string
+	WrapTable     bool
+	DumpSecrets   bool
+
+	MappedVaults   []MappedKeyVault
+	CommandCounter internal.CommandCounter
+
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+// MappedKeyVault is one vault row. AccessPolicies (the object IDs of the
+// vault's access policies) and SecretNames/KeyNames/CertificateNames are
+// each "|"-joined summaries; RBACAuthorization only records whether the
+// vault uses RBAC, and its role assignments are not listed. This is the
+// same flattening nsg.go's associationSummary uses for a one-to-many
+// relationship that doesn't deserve its own output table.
+type MappedKeyVault struct {
+	Subscription        string
+	Name                string
+	ResourceID          string
+	PublicNetworkAccess string
+	NetworkACLDefault   string
+	FirewallIPRules     string
+	VNetRules           string
+	SoftDeleteEnabled   string
+	PurgeProtection     string
+	AccessPolicies      string
+	RBACAuthorization   string
+	SecretNames         string
+	KeyNames            string
+	CertificateNames    string
+}
+
+func (m *KeyVaultModule) PrintKeyVaults(outputDirectory string, verbosity int) {
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "keyvault"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+
+	ctx := context.Background()
+	for _, subscriptionID := range m.Subscriptions {
+		fmt.Printf("[%s][%s] Enumerating Key Vaults\n", cyan(m.output.CallingModule), cyan(subscriptionID))
+
+		m.CommandCounter.Total++
+		if err := m.getVaults(ctx, subscriptionID); err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		}
+	}
+
+	m.output.Headers = []string{
+		"Subscription",
+		"Name",
+		"ResourceID",
+		"PublicNetworkAccess",
+		"NetworkACLDefaultAction",
+		"FirewallIPRules",
+		"VNetRules",
+		"SoftDeleteEnabled",
+		"PurgeProtection",
+		"AccessPolicies",
+		"RBACAuthorization",
+		"Secrets",
+		"Keys",
+		"Certificates",
+	}
+
+	for _, vault := range m.MappedVaults {
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				vault.Subscription,
+				vault.Name,
+				vault.ResourceID,
+				vault.PublicNetworkAccess,
+				vault.NetworkACLDefault,
+				vault.FirewallIPRules,
+				vault.VNetRules,
+				vault.SoftDeleteEnabled,
+				vault.PurgeProtection,
+				vault.AccessPolicies,
+				vault.RBACAuthorization,
+				vault.SecretNames,
+				vault.KeyNames,
+				vault.CertificateNames,
+			},
+		)
+	}
+
+	if m.DumpSecrets {
+		m.writeLoot(outputDirectory)
+	}
+
+	m.writeOutput(outputDirectory, verbosity)
+}
+
+func (m *KeyVaultModule) writeOutput(outputDirectory string, verbosity int) {
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "azure", m.Client.TenantID)
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: m.output.Headers,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.Client.TenantID
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "azure", m.Client.TenantID)
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s] %s vaults found.\n", cyan(m.output.CallingModule), strconv.Itoa(len(m.output.Body)))
+	} else {
+		fmt.Printf("[%s] No vaults found, skipping the creation of an output file.\n", cyan(m.output.CallingModule))
+	}
+
+	if err := m.writeJSON(outputDirectory); err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+}
+
+// writeJSON flags a vault as a finding when it accepts public network
+// access with no network ACL narrowing that down, and separately when
+// purge protection is off (a vault without it can have its soft-deleted
+// secrets permanently purged by anyone who can delete the vault).
+func (m *KeyVaultModule) writeJSON(outputDirectory string) error {
+	writer, err := azoutput.NewWriter(m.AzOutputType, outputDirectory, m.Client.TenantID, m.output.CallingModule)
+	if err != nil || writer == nil {
+		return err
+	}
+
+	for _, vault := range m.MappedVaults {
+		doc := azoutput.Document{
+			Module:       m.output.CallingModule,
+			Subscription: vault.Subscription,
+			Resource: map[string]string{
+				"name":                vault.Name,
+				"resourceId":          vault.ResourceID,
+				"publicNetworkAccess": vault.PublicNetworkAccess,
+				"networkACLDefault":   vault.NetworkACLDefault,
+				"softDeleteEnabled":   vault.SoftDeleteEnabled,
+				"purgeProtection":     vault.PurgeProtection,
+			},
+		}
+		if vault.PublicNetworkAccess == "Enabled" && vault.NetworkACLDefault == "Allow" {
+			doc.Findings = append(doc.Findings, azoutput.Finding{
+				RuleID:   "azure-keyvault-public-no-acl",
+				Severity: "high",
+				Category: "network",
+				Evidence: fmt.Sprintf("%s allows public network access with no network ACL restricting it", vault.Name),
+			})
+		}
+		if vault.PurgeProtection == "No" {
+			doc.Findings = append(doc.Findings, azoutput.Finding{
+				RuleID:   "azure-keyvault-no-purge-protection",
+				Severity: "medium",
+				Category: "data-protection",
+				Evidence: fmt.Sprintf("%s has purge protection disabled", vault.Name),
+			})
+		}
+		if err := writer.WriteDocument(doc); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// getVaults lists every vault in the subscription, fills in the
+// management-plane fields directly from the vault properties, then - for
+// each vault - calls the data-plane secret/key/certificate clients to
+// collect names (not versions). A data-plane failure (typically a 403, since
+// RBAC "Key Vault Reader" doesn't grant data-plane access by default) is
+// logged on the vault's row instead of aborting the whole vault.
+func (m *KeyVaultModule) getVaults(ctx context.Context, subscriptionID string) error {
+	client, err := armkeyvault.NewVaultsClient(subscriptionID, m.Client.Credential, nil)
+	if err != nil {
+		return fmt.Errorf("creating key vaults client for %s: %s", subscriptionID, err)
+	}
+
+	pager := client.NewListBySubscriptionPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("listing key vaults for %s: %s", subscriptionID, err)
+		}
+		for _, vault := range page.Value {
+			mapped := mapKeyVault(subscriptionID, vault)
+
+			vaultURI := ""
+			if vault.Properties != nil {
+				vaultURI = ptrString(vault.Properties.VaultURI)
+			}
+			if vaultURI != "" {
+				m.getSecretNames(ctx, &mapped, vaultURI)
+				m.getKeyNames(ctx, &mapped, vaultURI)
+				m.getCertificateNames(ctx, &mapped, vaultURI)
+			}
+
+			m.MappedVaults = append(m.MappedVaults, mapped)
+		}
+	}
+	return nil
+}
+
+// ptrID is ptrString for the distinct string-typed ID types the keyvault
+// data-plane SDKs (azsecrets.ID, azkeys.ID, azcertificates.ID) each define
+// instead of a plain *string.
+func ptrID[T ~string](id *T) string {
+	if id == nil {
+		return ""
+	}
+	return string(*id)
+}
+
+func (m *KeyVaultModule) getSecretNames(ctx context.Context, mapped *MappedKeyVault, vaultURI string) {
+	client, err := azsecrets.NewClient(vaultURI, m.Client.Credential, nil)
+	if err != nil {
+		mapped.SecretNames = "Error: " + err.Error()
+		return
+	}
+
+	var names []string
+	pager := client.NewListSecretsPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			mapped.SecretNames = "AccessDenied"
+			return
+		}
+		for _, secret := range page.Value {
+			names = append(names, lastSegment(ptrID(secret.ID)))
+		}
+	}
+	mapped.SecretNames = joinOrNone(names)
+}
+
+func (m *KeyVaultModule) getKeyNames(ctx context.Context, mapped *MappedKeyVault, vaultURI string) {
+	client, err := azkeys.NewClient(vaultURI, m.Client.Credential, nil)
+	if err != nil {
+		mapped.KeyNames = "Error: " + err.Error()
+		return
+	}
+
+	var names []string
+	pager := client.NewListKeysPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			mapped.KeyNames = "AccessDenied"
+			return
+		}
+		for _, key := range page.Value {
+			names = append(names, lastSegment(ptrID(key.KID)))
+		}
+	}
+	mapped.KeyNames = joinOrNone(names)
+}
+
+func (m *KeyVaultModule) getCertificateNames(ctx context.Context, mapped *MappedKeyVault, vaultURI string) {
+	client, err := azcertificates.NewClient(vaultURI, m.Client.Credential, nil)
+	if err != nil {
+		mapped.CertificateNames = "Error: " + err.Error()
+		return
+	}
+
+	var names []string
+	pager := client.NewListCertificatesPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			mapped.CertificateNames = "AccessDenied"
+			return
+		}
+		for _, cert := range page.Value {
+			names = append(names, lastSegment(ptrID(cert.ID)))
+		}
+	}
+	mapped.CertificateNames = joinOrNone(names)
+}
+
+// writeLoot retrieves the current value of every secret named in
+// m.MappedVaults and writes it to loot, one file per vault. It only runs
+// when --dump-secrets was passed: value retrieval is a much louder
+// action than listing names, and this module should never do it by
+// default. Keys and certificates aren't dumped here since their private
+// material generally isn't extractable through the same GetSecret-style
+// call even when the credential has data-plane access to it.
+func (m *KeyVaultModule) writeLoot(outputDirectory string) {
+	path := filepath.Join(outputDirectory, "loot", "keyvault-secrets")
+	if err := os.MkdirAll(path, os.ModePerm); err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+		return
+	}
+
+	ctx := context.Background()
+	for _, vault := range m.MappedVaults {
+		if vault.SecretNames == "" || vault.SecretNames == "None" {
+			continue
+		}
+
+		vaultURI := "https://" + vault.Name + "." + m.Client.Environment.KeyVaultDNSSuffix + "/"
+		client, err := azsecrets.NewClient(vaultURI, m.Client.Credential, nil)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+
+		var dumped string
+		for _, name := range strings.Split(vault.SecretNames, "|") {
+			secret, err := client.GetSecret(ctx, name, "", nil)
+			if err != nil {
+				m.modLog.Error(fmt.Sprintf("dumping secret %s/%s: %s", vault.Name, name, err))
+				m.CommandCounter.Error++
+				continue
+			}
+			dumped += fmt.Sprintf("%s: %s\n", name, ptrString(secret.Value))
+		}
+
+		if dumped == "" {
+			continue
+		}
+		filename := filepath.Join(path, vault.Name+".txt")
+		if err := os.WriteFile(filename, []byte(dumped), 0600); err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		}
+	}
+}
+
+func mapKeyVault(subscriptionID string, vault *armkeyvault.Vault) MappedKeyVault {
+	mapped := MappedKeyVault{
+		Subscription: subscriptionID,
+		Name:         ptrString(vault.Name),
+		ResourceID:   ptrString(vault.ID),
+	}
+
+	if vault.Properties == nil {
+		return mapped
+	}
+	props := vault.Properties
+
+	mapped.PublicNetworkAccess = ptrString(props.PublicNetworkAccess)
+	if mapped.PublicNetworkAccess == "" {
+		mapped.PublicNetworkAccess = "Enabled"
+	}
+	mapped.SoftDeleteEnabled = boolString(props.EnableSoftDelete)
+	mapped.PurgeProtection = boolString(props.EnablePurgeProtection)
+	mapped.RBACAuthorization = boolString(props.EnableRbacAuthorization)
+
+	if props.NetworkACLs != nil {
+		acl := props.NetworkACLs
+		if acl.DefaultAction != nil {
+			mapped.NetworkACLDefault = string(*acl.DefaultAction)
+		}
+
+		var ipRules []string
+		for _, rule := range acl.IPRules {
+			if rule != nil {
+				ipRules = append(ipRules, ptrString(rule.Value))
+			}
+		}
+		mapped.FirewallIPRules = joinOrNone(ipRules)
+
+		var vnetRules []string
+		for _, rule := range acl.VirtualNetworkRules {
+			if rule != nil {
+				vnetRules = append(vnetRules, ptrString(rule.ID))
+			}
+		}
+		mapped.VNetRules = joinOrNone(vnetRules)
+	} else {
+		mapped.NetworkACLDefault = "Allow"
+		mapped.FirewallIPRules = "None"
+		mapped.VNetRules = "None"
+	}
+
+	var policies []string
+	for _, policy := range props.AccessPolicies {
+		if policy == nil {
+			continue
+		}
+		policies = append(policies, ptrString(policy.ObjectID))
+	}
+	mapped.AccessPolicies = joinOrNone(policies)
+
+	return mapped
+}
+
+func boolString(b *bool) string {
+	if b == nil || !*b {
+		return "No"
+	}
+	return "YES"
+}
+
+func joinOrNone(values []string) string {
+	if len(values) == 0 {
+		return "None"
+	}
+	return strings.Join(values, "|")
+}
+
+// AzKeyVaultCommand is the cli package's entrypoint for
+// "cloudfox az keyvault", alongside AzStorageCommand.
+func AzKeyVaultCommand(tenantID, subscription, cloudName, outputFormat, outputDirectory, version string, verbosity int, wrapTable, mergedTable, dumpSecrets bool) error {
+	client, err := NewClient(tenantID, cloudName, "")
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	subscriptions, err := ResolveSubscriptions(ctx, client, subscriptionSlice(subscription), subscription == "")
+	if err != nil {
+		return err
+	}
+
+	m := KeyVaultModule{
+		Client:        client,
+		Subscriptions: subscriptions,
+		AzOutputType:  outputFormat,
+		WrapTable:     wrapTable,
+		DumpSecrets:   dumpSecrets,
+	}
+	m.PrintKeyVaults(outputDirectory, verbosity)
+	return nil
+}
@@ -0,0 +1,534 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
+	"github.com/sirupsen/logrus"
+
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/BishopFox/cloudfox/internal/azconcurrency"
+	"github.com/BishopFox/cloudfox/internal/azoutput"
+)
+
+// NSGLinksModule enumerates every Network Security Group per subscription
+// and the subnets/NICs each one is associated with - the az analog of a
+// "who references this security group" query. cli/azure.go referenced
+// AzNSGLinksCommand before this change but no package defined it; this is
+// that missing implementation, not a rework of an existing one.
+type NSGLinksModule struct {
+	Client         *Client
+	Subscriptions  []string
+	NSGResourceIDs []string
+	AzOutputType   string
+	WrapTable      bool
+	Concurrency    int
+	ArmQPS         float64
+
+	MappedNSGLinks []MappedNSGLink
+	CommandCounter internal.CommandCounter
+
+	mu     sync.Mutex
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+// MappedNSGLink is one NSG-to-association row: a subnet or NIC that has
+// the NSG attached, the way a resource's SecurityGroup is attached in the
+// other direction.
+type MappedNSGLink struct {
+	Subscription         string
+	NSGName              string
+	NSGResourceID        string
+	AssociatedType       string
+	AssociatedResourceID string
+}
+
+func (m *NSGLinksModule) PrintNSGLinks(outputDirectory string, verbosity int) {
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "nsg-links"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+
+	ctx := context.Background()
+	m.CommandCounter.Total += len(m.Subscriptions)
+	limiter := azconcurrency.NewLimiter(m.ArmQPS)
+	errs := azconcurrency.RunPerSubscription(ctx, m.Subscriptions, m.Concurrency, limiter, func(ctx context.Context, subscriptionID string) error {
+		fmt.Printf("[%s][%s] Enumerating NSG links\n", cyan(m.output.CallingModule), cyan(subscriptionID))
+		return m.getNSGLinks(ctx, subscriptionID)
+	})
+	for _, err := range errs {
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		}
+	}
+
+	m.output.Headers = []string{
+		"Subscription",
+		"NSG",
+		"NSGResourceID",
+		"AssociatedType",
+		"AssociatedResourceID",
+	}
+
+	for _, link := range m.MappedNSGLinks {
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				link.Subscription,
+				link.NSGName,
+				link.NSGResourceID,
+				link.AssociatedType,
+				link.AssociatedResourceID,
+			},
+		)
+	}
+
+	m.writeOutput(outputDirectory, verbosity)
+}
+
+func (m *NSGLinksModule) writeOutput(outputDirectory string, verbosity int) {
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "azure", m.Client.TenantID)
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: m.output.Headers,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.Client.TenantID
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "azure", m.Client.TenantID)
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s] %s rows found.\n", cyan(m.output.CallingModule), strconv.Itoa(len(m.output.Body)))
+	} else {
+		fmt.Printf("[%s] No rows found, skipping the creation of an output file.\n", cyan(m.output.CallingModule))
+	}
+
+	if err := m.writeJSON(outputDirectory); err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+}
+
+func (m *NSGLinksModule) writeJSON(outputDirectory string) error {
+	writer, err := azoutput.NewWriter(m.AzOutputType, outputDirectory, m.Client.TenantID, m.output.CallingModule)
+	if err != nil || writer == nil {
+		return err
+	}
+
+	for _, link := range m.MappedNSGLinks {
+		doc := azoutput.Document{
+			Module:       m.output.CallingModule,
+			Subscription: link.Subscription,
+			Resource: map[string]string{
+				"nsgName":              link.NSGName,
+				"nsgResourceId":        link.NSGResourceID,
+				"associatedType":       link.AssociatedType,
+				"associatedResourceId": link.AssociatedResourceID,
+			},
+		}
+		if err := writer.WriteDocument(doc); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// getNSGLinks lists every NSG in the subscription (optionally filtered to
+// m.NSGResourceIDs) and flattens its Subnets/NetworkInterfaces association
+// lists - the data the ARM API already returns on the NSG object itself,
+// so no per-subnet/per-NIC lookup is needed to resolve the link.
+func (m *NSGLinksModule) getNSGLinks(ctx context.Context, subscriptionID string) error {
+	client, err := armnetwork.NewSecurityGroupsClient(subscriptionID, m.Client.Credential, nil)
+	if err != nil {
+		return fmt.Errorf("creating security groups client for %s: %s", subscriptionID, err)
+	}
+
+	var links []MappedNSGLink
+	pager := client.NewListAllPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("listing network security groups for %s: %s", subscriptionID, err)
+		}
+		for _, nsg := range page.Value {
+			if len(m.NSGResourceIDs) > 0 && !containsResourceID(m.NSGResourceIDs, ptrString(nsg.ID)) {
+				continue
+			}
+			nsgName := ptrString(nsg.Name)
+			nsgID := ptrString(nsg.ID)
+
+			if nsg.Properties == nil {
+				continue
+			}
+			for _, subnet := range nsg.Properties.Subnets {
+				links = append(links, MappedNSGLink{
+					Subscription:         subscriptionID,
+					NSGName:              nsgName,
+					NSGResourceID:        nsgID,
+					AssociatedType:       "Subnet",
+					AssociatedResourceID: ptrString(subnet.ID),
+				})
+			}
+			for _, nic := range nsg.Properties.NetworkInterfaces {
+				links = append(links, MappedNSGLink{
+					Subscription:         subscriptionID,
+					NSGName:              nsgName,
+					NSGResourceID:        nsgID,
+					AssociatedType:       "NetworkInterface",
+					AssociatedResourceID: ptrString(nic.ID),
+				})
+			}
+		}
+	}
+
+	m.mu.Lock()
+	m.MappedNSGLinks = append(m.MappedNSGLinks, links...)
+	m.mu.Unlock()
+	return nil
+}
+
+// AzNSGLinksCommand is the cli package's entrypoint for
+// "cloudfox az nsg-links".
+func AzNSGLinksCommand(tenantID, subscription string, resourceIDs []string, cloudName, outputFormat, outputDirectory, version string, verbosity, concurrency int, armQPS float64, wrapTable, mergedTable bool) error {
+	client, err := NewClient(tenantID, cloudName, "")
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	subscriptions, err := ResolveSubscriptions(ctx, client, subscriptionSlice(subscription), subscription == "")
+	if err != nil {
+		return err
+	}
+
+	m := NSGLinksModule{
+		Client:         client,
+		Subscriptions:  subscriptions,
+		NSGResourceIDs: resourceIDs,
+		AzOutputType:   outputFormat,
+		WrapTable:      wrapTable,
+		Concurrency:    concurrency,
+		ArmQPS:         armQPS,
+	}
+	m.PrintNSGLinks(outputDirectory, verbosity)
+	return nil
+}
+
+// NSGModule enumerates inbound/outbound security rules per NSG, with each
+// rule row naming the subnets/NICs the NSG is attached to in AssociatedTo
+// (read off the NSG object itself, not through NSGLinksModule). It does not
+// compute effective rules per NIC/subnet: rules from a NIC's NSG and its
+// subnet's NSG are not combined.
+type NSGModule struct {
+	Client         *Client
+	Subscriptions  []string
+	NSGResourceIDs []string
+	OpenToInternet bool
+	AzOutputType   string
+	WrapTable      bool
+
+	MappedRules    []MappedNSGRule
+	CommandCounter internal.CommandCounter
+
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+// MappedNSGRule is one security rule row, covering both the rules a user
+// wrote and the ones Azure adds implicitly (AllowVnetInBound,
+// DenyAllInBound, etc.) via DefaultSecurityRules.
+type MappedNSGRule struct {
+	Subscription    string
+	NSGName         string
+	RuleName        string
+	Direction       string
+	Priority        string
+	Protocol        string
+	SourcePrefix    string
+	SourcePortRange string
+	DestPrefix      string
+	DestPortRange   string
+	Access          string
+	OpenToInternet  string
+	AssociatedTo    string
+}
+
+func (m *NSGModule) PrintNSGRules(outputDirectory string, verbosity int) {
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "nsg"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+
+	ctx := context.Background()
+	for _, subscriptionID := range m.Subscriptions {
+		fmt.Printf("[%s][%s] Enumerating NSG rules\n", cyan(m.output.CallingModule), cyan(subscriptionID))
+
+		m.CommandCounter.Total++
+		if err := m.getNSGRules(ctx, subscriptionID); err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+		}
+	}
+
+	m.output.Headers = []string{
+		"Subscription",
+		"NSG",
+		"Rule",
+		"Direction",
+		"Priority",
+		"Protocol",
+		"Source",
+		"SourcePort",
+		"Destination",
+		"DestPort",
+		"Access",
+		"OpenToInternet?",
+		"AssociatedTo",
+	}
+
+	for _, rule := range m.MappedRules {
+		if m.OpenToInternet && rule.OpenToInternet != "YES" {
+			continue
+		}
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				rule.Subscription,
+				rule.NSGName,
+				rule.RuleName,
+				rule.Direction,
+				rule.Priority,
+				rule.Protocol,
+				rule.SourcePrefix,
+				rule.SourcePortRange,
+				rule.DestPrefix,
+				rule.DestPortRange,
+				rule.Access,
+				rule.OpenToInternet,
+				rule.AssociatedTo,
+			},
+		)
+	}
+
+	m.writeOutput(outputDirectory, verbosity)
+}
+
+func (m *NSGModule) writeOutput(outputDirectory string, verbosity int) {
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "azure", m.Client.TenantID)
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: m.output.Headers,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.Client.TenantID
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "azure", m.Client.TenantID)
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s] %s rules found.\n", cyan(m.output.CallingModule), strconv.Itoa(len(m.output.Body)))
+	} else {
+		fmt.Printf("[%s] No rules found, skipping the creation of an output file.\n", cyan(m.output.CallingModule))
+	}
+
+	if err := m.writeJSON(outputDirectory); err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+}
+
+// writeJSON flags every rule open to the internet as a finding, the same
+// signal --open-to-internet already filters the table on.
+func (m *NSGModule) writeJSON(outputDirectory string) error {
+	writer, err := azoutput.NewWriter(m.AzOutputType, outputDirectory, m.Client.TenantID, m.output.CallingModule)
+	if err != nil || writer == nil {
+		return err
+	}
+
+	for _, rule := range m.MappedRules {
+		if m.OpenToInternet && rule.OpenToInternet != "YES" {
+			continue
+		}
+		doc := azoutput.Document{
+			Module:       m.output.CallingModule,
+			Subscription: rule.Subscription,
+			Resource: map[string]string{
+				"nsgName":      rule.NSGName,
+				"ruleName":     rule.RuleName,
+				"direction":    rule.Direction,
+				"protocol":     rule.Protocol,
+				"source":       rule.SourcePrefix,
+				"destination":  rule.DestPrefix,
+				"access":       rule.Access,
+				"associatedTo": rule.AssociatedTo,
+			},
+		}
+		if rule.OpenToInternet == "YES" {
+			doc.Findings = append(doc.Findings, azoutput.Finding{
+				RuleID:   "azure-nsg-open-to-internet",
+				Severity: "high",
+				Category: "network",
+				Evidence: fmt.Sprintf("%s allows %s traffic from %s", rule.NSGName, rule.Protocol, rule.SourcePrefix),
+			})
+		}
+		if err := writer.WriteDocument(doc); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+func (m *NSGModule) getNSGRules(ctx context.Context, subscriptionID string) error {
+	client, err := armnetwork.NewSecurityGroupsClient(subscriptionID, m.Client.Credential, nil)
+	if err != nil {
+		return fmt.Errorf("creating security groups client for %s: %s", subscriptionID, err)
+	}
+
+	pager := client.NewListAllPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("listing network security groups for %s: %s", subscriptionID, err)
+		}
+		for _, nsg := range page.Value {
+			if len(m.NSGResourceIDs) > 0 && !containsResourceID(m.NSGResourceIDs, ptrString(nsg.ID)) {
+				continue
+			}
+			if nsg.Properties == nil {
+				continue
+			}
+			nsgName := ptrString(nsg.Name)
+
+			associatedTo := associationSummary(nsg)
+
+			for _, rule := range nsg.Properties.SecurityRules {
+				m.MappedRules = append(m.MappedRules, mapNSGRule(subscriptionID, nsgName, rule, associatedTo))
+			}
+			for _, rule := range nsg.Properties.DefaultSecurityRules {
+				m.MappedRules = append(m.MappedRules, mapNSGRule(subscriptionID, nsgName, rule, associatedTo))
+			}
+		}
+	}
+	return nil
+}
+
+// associationSummary renders the subnets/NICs an NSG is linked to as a
+// single "|"-joined cell, the same flattening getContainerNamesFromECSTask
+// uses for an ECS task's container names.
+func associationSummary(nsg *armnetwork.SecurityGroup) string {
+	var associations []string
+	for _, subnet := range nsg.Properties.Subnets {
+		associations = append(associations, ptrString(subnet.ID))
+	}
+	for _, nic := range nsg.Properties.NetworkInterfaces {
+		associations = append(associations, ptrString(nic.ID))
+	}
+	if len(associations) == 0 {
+		return "Unassociated"
+	}
+	return strings.Join(associations, "|")
+}
+
+func mapNSGRule(subscriptionID, nsgName string, rule *armnetwork.SecurityRule, associatedTo string) MappedNSGRule {
+	if rule.Properties == nil {
+		return MappedNSGRule{Subscription: subscriptionID, NSGName: nsgName, RuleName: ptrString(rule.Name), AssociatedTo: associatedTo}
+	}
+	props := rule.Properties
+
+	sourcePrefix := ptrString(props.SourceAddressPrefix)
+	destPrefix := ptrString(props.DestinationAddressPrefix)
+	access := string(*props.Access)
+	direction := string(*props.Direction)
+
+	openToInternet := "No"
+	if direction == "Inbound" && access == "Allow" && (sourcePrefix == "*" || sourcePrefix == "0.0.0.0/0" || sourcePrefix == "Internet" || sourcePrefix == "Any") {
+		openToInternet = "YES"
+	}
+
+	var priority string
+	if props.Priority != nil {
+		priority = strconv.Itoa(int(*props.Priority))
+	}
+
+	return MappedNSGRule{
+		Subscription:    subscriptionID,
+		NSGName:         nsgName,
+		RuleName:        ptrString(rule.Name),
+		Direction:       direction,
+		Priority:        priority,
+		Protocol:        string(*props.Protocol),
+		SourcePrefix:    sourcePrefix,
+		SourcePortRange: ptrString(props.SourcePortRange),
+		DestPrefix:      destPrefix,
+		DestPortRange:   ptrString(props.DestinationPortRange),
+		Access:          access,
+		OpenToInternet:  openToInternet,
+		AssociatedTo:    associatedTo,
+	}
+}
+
+// AzNSGCommand is the cli package's entrypoint for "cloudfox az nsg",
+// re-enabled after being commented out pending this implementation.
+func AzNSGCommand(tenantID, subscription string, resourceIDs []string, openToInternet bool, cloudName, outputFormat, outputDirectory, version string, verbosity int, wrapTable, mergedTable bool) error {
+	client, err := NewClient(tenantID, cloudName, "")
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	subscriptions, err := ResolveSubscriptions(ctx, client, subscriptionSlice(subscription), subscription == "")
+	if err != nil {
+		return err
+	}
+
+	m := NSGModule{
+		Client:         client,
+		Subscriptions:  subscriptions,
+		NSGResourceIDs: resourceIDs,
+		OpenToInternet: openToInternet,
+		AzOutputType:   outputFormat,
+		WrapTable:      wrapTable,
+	}
+	m.PrintNSGRules(outputDirectory, verbosity)
+	return nil
+}
+
+func containsResourceID(resourceIDs []string, id string) bool {
+	for _, candidate := range resourceIDs {
+		if strings.EqualFold(candidate, id) {
+			return true
+		}
+	}
+	return false
+}
+
+func subscriptionSlice(subscription string) []string {
+	if subscription == "" {
+		return nil
+	}
+	return []string{subscription}
+}
@@ -4,6 +4,7 @@ import (
 	"log"
 
 	"github.com/BishopFox/cloudfox/azure"
+	"github.com/BishopFox/cloudfox/internal/azconcurrency"
 	"github.com/spf13/cobra"
 )
 
@@ -11,13 +12,20 @@ var (
 	AzTenantID        string
 	AzSubscription    string
 	AzRGName          string
+	AzCloud           string
+	AzAuthFile        string
 	AzOutputFormat    string
 	AzOutputDirectory string
 	AzVerbosity       int
 	AzWrapTable       bool
 	AzMergedTable     bool
 
-	AzResourceIDs      []string
+	AzResourceIDs         []string
+	AzAllSubscriptions    bool
+	AzSubscriptionsList   string
+	AzKeyVaultDumpSecrets bool
+	AzConcurrency         int
+	AzArmQPS              float64
 
 	AzCommands = &cobra.Command{
 		Use:     "azure",
@@ -115,7 +123,6 @@ Enumerate storage accounts for a specific subscription:
 			}
 		},
 	}
-/*
 	AzNSGCommand = &cobra.Command{
 		Use:     "nsg",
 		Aliases: []string{},
@@ -128,17 +135,20 @@ Enumerate Network Security Groups rules for a specific subscription:
 ./cloudfox az nsg --subscription SUBSCRIPTION_ID
 
 Enumerate rules for a specific Network Security Group:
-./cloudfox az nsg --nsg NSG_ID
+./cloudfox az nsg --resource-id NSG_ID
+
+Only show inbound rules that allow traffic from the internet:
+./cloudfox az nsg --subscription SUBSCRIPTION_ID --open-to-internet
 `,
 		Run: func(cmd *cobra.Command, args []string) {
-			err := azure.AzNSGCommand(AzTenantID, AzSubscription, AzOutputFormat, AzOutputDirectory, cmd.Root().Version, AzVerbosity, AzWrapTable, AzMergedTable)
+			err := azure.AzNSGCommand(AzTenantID, AzSubscription, AzResourceIDs, AzNSGOpenToInternet, AzCloud, AzOutputFormat, AzOutputDirectory, cmd.Root().Version, AzVerbosity, AzWrapTable, AzMergedTable)
 			if err != nil {
 				log.Fatal(err)
 			}
 		},
 	}
-*/
-	AzNSGLinksCommand = &cobra.Command{
+	AzNSGOpenToInternet bool
+	AzNSGLinksCommand   = &cobra.Command{
 		Use:     "nsg-links",
 		Aliases: []string{},
 		Short:   "Enumerates azure Network Securiy Groups links",
@@ -153,7 +163,53 @@ Enumerate links for a specific Network Security Group:
 ./cloudfox az nsg-links --nsg NSG_ID
 `,
 		Run: func(cmd *cobra.Command, args []string) {
-			err := azure.AzNSGLinksCommand(AzTenantID, AzSubscription, AzResourceIDs, AzOutputFormat, AzOutputDirectory, cmd.Root().Version, AzVerbosity, AzWrapTable, AzMergedTable)
+			err := azure.AzNSGLinksCommand(AzTenantID, AzSubscription, AzResourceIDs, AzCloud, AzOutputFormat, AzOutputDirectory, cmd.Root().Version, AzVerbosity, AzConcurrency, AzArmQPS, AzWrapTable, AzMergedTable)
+			if err != nil {
+				log.Fatal(err)
+			}
+		},
+	}
+	AzKeyVaultCommand = &cobra.Command{
+		Use:     "keyvault",
+		Aliases: []string{"kv"},
+		Short:   "Enumerates Azure Key Vaults, their access controls, and secret/key/certificate inventory",
+		Long: `
+Enumerate Key Vaults for a specific tenant:
+./cloudfox az keyvault --tenant TENANT_ID
+
+Enumerate Key Vaults for a specific subscription:
+./cloudfox az keyvault --subscription SUBSCRIPTION_ID
+
+Also retrieve the current value of every listed secret and write it to loot
+(off by default - this is much louder than listing names):
+./cloudfox az keyvault --subscription SUBSCRIPTION_ID --dump-secrets
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := azure.AzKeyVaultCommand(AzTenantID, AzSubscription, AzCloud, AzOutputFormat, AzOutputDirectory, cmd.Root().Version, AzVerbosity, AzWrapTable, AzMergedTable, AzKeyVaultDumpSecrets)
+			if err != nil {
+				log.Fatal(err)
+			}
+		},
+	}
+	AzIamPrincipalsCommand = &cobra.Command{
+		Use:     "iam-principals",
+		Aliases: []string{"principals"},
+		Short:   "Enumerates Azure RBAC role assignments, custom role definitions, and managed identities",
+		Long: `
+Enumerate IAM principals for a specific subscription:
+./cloudfox az iam-principals --subscription SUBSCRIPTION_ID
+
+Enumerate IAM principals across every subscription the credential can see:
+./cloudfox az iam-principals --all-subscriptions
+
+Enumerate IAM principals for a file of subscription IDs:
+./cloudfox az iam-principals --subscriptions-list ./subscriptions.txt
+
+Service principals aren't included: that enumeration needs the Microsoft
+Graph API, which this module doesn't call.
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			err := azure.AzIamPrincipalsCommand(AzTenantID, AzSubscription, AzSubscriptionsList, AzCloud, AzAuthFile, AzOutputFormat, AzAllSubscriptions, AzOutputDirectory, cmd.Root().Version, AzVerbosity, AzWrapTable)
 			if err != nil {
 				log.Fatal(err)
 			}
@@ -166,23 +222,36 @@ func init() {
 	AzWhoamiCommand.Flags().BoolVarP(&AzWhoamiListRGsAlso, "list-rgs", "l", false, "Drill down to the resource group level")
 
 	// Global flags
-	AzCommands.PersistentFlags().StringVarP(&AzOutputFormat, "output", "o", "all", "[\"table\" | \"csv\" | \"all\" ]")
+	AzCommands.PersistentFlags().StringVarP(&AzOutputFormat, "output", "o", "all", "[\"table\" | \"csv\" | \"all\" | \"json\" | \"jsonl\" ]\njson/jsonl also write a structured document per resource (with a findings array) under cloudfox-output/azure/<tenant>/json/, alongside the usual table/csv")
 	AzCommands.PersistentFlags().StringVar(&AzOutputDirectory, "outdir", defaultOutputDir, "Output Directory ")
 	AzCommands.PersistentFlags().IntVarP(&AzVerbosity, "verbosity", "v", 2, "1 = Print control messages only\n2 = Print control messages, module output\n3 = Print control messages, module output, and loot file output\n")
 	AzCommands.PersistentFlags().StringVarP(&AzTenantID, "tenant", "t", "", "Tenant name")
+	AzCommands.PersistentFlags().StringVar(&AzCloud, "cloud", "", "[\"AzurePublicCloud\" | \"AzureUSGovernmentCloud\" | \"AzureChinaCloud\" | \"AzureGermanCloud\"] (defaults to AzurePublicCloud)")
+	AzCommands.PersistentFlags().StringVar(&AzAuthFile, "auth-file", "", "Path to an 'az ad sp create-for-rbac --sdk-auth' JSON file (falls back to $AZURE_AUTH_LOCATION), for headless/CI auth instead of 'az login'")
 	AzCommands.PersistentFlags().StringVarP(&AzSubscription, "subscription", "s", "", "Subscription ID or Name")
 	AzCommands.PersistentFlags().StringVarP(&AzRGName, "resource-group", "g", "", "Resource Group name")
 	AzCommands.PersistentFlags().StringSliceVarP(&AzResourceIDs, "resource-id", "r", []string{}, "Resource ID (/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/{resourceProviderNamespace}/{resourceType}/{resourceName})")
 	AzCommands.PersistentFlags().BoolVarP(&AzWrapTable, "wrap", "w", false, "Wrap table to fit in terminal (complicates grepping)")
 	AzCommands.PersistentFlags().BoolVarP(&AzMergedTable, "merged-table", "m", false, "Writes a single table for all subscriptions in the tenant. Default writes a table per subscription.")
+	AzCommands.PersistentFlags().IntVar(&AzConcurrency, "concurrency", 1, "Number of subscriptions to enumerate in parallel when --tenant covers more than one (all workers share the --arm-qps limiter, which spaces out subscription starts; the ARM calls inside each enumeration are not rate-limited)")
+	AzCommands.PersistentFlags().Float64Var(&AzArmQPS, "arm-qps", azconcurrency.DefaultQPS, "Max subscription enumerations (including 429 retries) started per second, shared across every --concurrency worker; individual ARM requests within an enumeration are not rate-limited")
+
+	AzIamPrincipalsCommand.Flags().BoolVar(&AzAllSubscriptions, "all-subscriptions", false, "Enumerate every subscription the credential can see, instead of just --subscription")
+	AzIamPrincipalsCommand.Flags().StringVar(&AzSubscriptionsList, "subscriptions-list", "", "File containing subscription IDs separated by newlines")
+
+	AzNSGCommand.Flags().BoolVar(&AzNSGOpenToInternet, "open-to-internet", false, "Only show inbound rules that allow traffic from the internet")
+
+	AzKeyVaultCommand.Flags().BoolVar(&AzKeyVaultDumpSecrets, "dump-secrets", false, "Retrieve and loot the current value of every secret this credential can read (never done by default)")
 
 	AzCommands.AddCommand(
 		AzWhoamiCommand,
 		AzRBACCommand,
 		AzVMsCommand,
 		AzStorageCommand,
-//		AzNSGCommand,
+		AzNSGCommand,
 		AzNSGLinksCommand,
-		AzInventoryCommand)
+		AzKeyVaultCommand,
+		AzInventoryCommand,
+		AzIamPrincipalsCommand)
 
 }
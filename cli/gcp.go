@@ -0,0 +1,306 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/BishopFox/cloudfox/gcp"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/BishopFox/cloudfox/internal/credprovider"
+	gcpauth "github.com/BishopFox/cloudfox/internal/gcp"
+	vaultapi "github.com/hashicorp/vault/api"
+	"github.com/kyokomi/emoji"
+	"github.com/spf13/cobra"
+)
+
+var (
+	GCPProject         string
+	GCPProfilesList    string
+	GCPAllProfiles     bool
+	GCPProfiles        []string
+	GCPOutputType      string
+	GCPOutputDirectory string
+	GCPWrapTable       bool
+	GCPUseCache        bool
+
+	GCPPermissionsMember string
+
+	// GCPVaultAddr, GCPVaultMount, and GCPVaultRoleSet switch providerPreRun
+	// from a local gcloud profile to a Vault-backed GCP token: set together,
+	// gcpClient authenticates with a token credprovider.VaultGCPSource reads
+	// from GCPVaultMount+"/token/"+GCPVaultRoleSet, the same Vault GCP
+	// secrets engine layout the "aws" side's --vault-aws-role uses for STS
+	// credentials.
+	GCPVaultAddr    string
+	GCPVaultMount   string
+	GCPVaultRoleSet string
+
+	gcpClient *gcpauth.GCPClient
+
+	GCPCommands = &cobra.Command{
+		Use:     "gcp",
+		Aliases: []string{"g"},
+		Short:   "See \"Available Commands\" for GCP Modules below",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	GCPInventoryCommand = &cobra.Command{
+		Use:     "inventory",
+		Aliases: []string{"inv"},
+		Short:   "Display an inventory table of all resources in a project",
+		Long: "\nUse case examples:\n" +
+			"./cloudfox gcp inventory --project my-project",
+		PreRun:  providerPreRun,
+		Run:     runGCPInventoryCommand,
+		PostRun: providerPostRun,
+	}
+
+	GCPBucketsCommand = &cobra.Command{
+		Use:     "buckets",
+		Aliases: []string{"bucket", "gcs"},
+		Short:   "Enumerate Cloud Storage buckets",
+		Long: "\nUse case examples:\n" +
+			"./cloudfox gcp buckets --project my-project",
+		PreRun:  providerPreRun,
+		Run:     runGCPBucketsCommand,
+		PostRun: providerPostRun,
+	}
+
+	GCPInstancesCommand = &cobra.Command{
+		Use:     "instances",
+		Aliases: []string{"instance", "vms"},
+		Short:   "Enumerate Compute Engine instances",
+		Long: "\nUse case examples:\n" +
+			"./cloudfox gcp instances --project my-project",
+		PreRun:  providerPreRun,
+		Run:     runGCPInstancesCommand,
+		PostRun: providerPostRun,
+	}
+
+	GCPSecretsCommand = &cobra.Command{
+		Use:   "secrets",
+		Short: "Enumerate Secret Manager secrets",
+		Long: "\nUse case examples:\n" +
+			"./cloudfox gcp secrets --project my-project",
+		PreRun:  providerPreRun,
+		Run:     runGCPSecretsCommand,
+		PostRun: providerPostRun,
+	}
+
+	GCPPermissionsCommand = &cobra.Command{
+		Use:   "permissions",
+		Short: "Test which IAM permissions the calling principal has on a project",
+		Long: "\nUse case examples:\n" +
+			"./cloudfox gcp permissions --project my-project",
+		PreRun:  providerPreRun,
+		Run:     runGCPPermissionsCommand,
+		PostRun: providerPostRun,
+	}
+
+	GCPPrincipalsCommand = &cobra.Command{
+		Use:     "principals",
+		Aliases: []string{"iam"},
+		Short:   "Enumerate IAM policy bindings for a project",
+		Long: "\nUse case examples:\n" +
+			"./cloudfox gcp principals --project my-project",
+		PreRun:  providerPreRun,
+		Run:     runGCPPrincipalsCommand,
+		PostRun: providerPostRun,
+	}
+
+	GCPEndpointsCommand = &cobra.Command{
+		Use:   "endpoints",
+		Short: "Enumerate resources that are likely to expose a network endpoint",
+		Long: "\nUse case examples:\n" +
+			"./cloudfox gcp endpoints --project my-project",
+		PreRun:  providerPreRun,
+		Run:     runGCPEndpointsCommand,
+		PostRun: providerPostRun,
+	}
+
+	GCPAllChecksCommand = &cobra.Command{
+		Use:   "all-checks",
+		Short: "Run all of the enumeration modules against a project",
+		Long: "\nUse case examples:\n" +
+			"./cloudfox gcp all-checks --project my-project",
+		PreRun:  providerPreRun,
+		Run:     runGCPAllChecksCommand,
+		PostRun: providerPostRun,
+	}
+)
+
+func init() {
+	GCPCommands.PersistentFlags().StringVarP(&GCPProject, "project", "p", "", "GCP Project ID")
+	GCPCommands.PersistentFlags().StringVarP(&GCPProfilesList, "profiles-list", "l", "", "File containing GCP identities separated by newlines")
+	GCPCommands.PersistentFlags().BoolVarP(&GCPAllProfiles, "all-profiles", "a", false, "Use all available GCP identities")
+	GCPCommands.PersistentFlags().StringVarP(&GCPOutputType, "output", "o", "brief", "[\"brief\" | \"wide\" ]")
+	GCPCommands.PersistentFlags().StringVar(&GCPOutputDirectory, "outdir", defaultOutputDir, "Output Directory ")
+	GCPCommands.PersistentFlags().IntVarP(&Verbosity, "verbosity", "v", 2, "1 = Print control messages only\n2 = Print control messages, module output\n3 = Print control messages, module output, and loot file output\n")
+	GCPCommands.PersistentFlags().BoolVarP(&GCPWrapTable, "wrap", "w", false, "Wrap table to fit in terminal (complicates grepping)")
+	GCPCommands.PersistentFlags().BoolVar(&GCPUseCache, "use-cache", false, "Use cached data from a previous run for this command")
+	GCPCommands.PersistentFlags().StringVar(&GCPVaultAddr, "vault-addr", "", "Vault server address to fetch a GCP access token from instead of the local gcloud profile (falls back to $VAULT_ADDR if unset)")
+	GCPCommands.PersistentFlags().StringVar(&GCPVaultMount, "vault-gcp-mount", "gcp", "Vault GCP secrets engine mount path")
+	GCPCommands.PersistentFlags().StringVar(&GCPVaultRoleSet, "vault-gcp-roleset", "", "Vault GCP roleset to read a token for (enables Vault-backed auth when set)")
+
+	GCPPermissionsCommand.Flags().StringVar(&GCPPermissionsMember, "principal", "", "Not used: permissions are always tested for the authenticated caller, whatever member is given here")
+
+	GCPCommands.AddCommand(
+		GCPInventoryCommand,
+		GCPBucketsCommand,
+		GCPInstancesCommand,
+		GCPSecretsCommand,
+		GCPPermissionsCommand,
+		GCPPrincipalsCommand,
+		GCPEndpointsCommand,
+		GCPAllChecksCommand,
+	)
+}
+
+// providerPreRun generalizes awsPreRun for non-AWS providers: print the
+// caller identity and, if requested, load any cached data from a previous
+// run of this command. Cache is namespaced per provider/account so running
+// `cloudfox aws ...` and `cloudfox gcp ...` side by side never collide.
+func providerPreRun(cmd *cobra.Command, args []string) {
+	if GCPVaultRoleSet != "" {
+		client, err := newGCPVaultClient()
+		if err != nil {
+			log.Fatalf("failed to build Vault-backed GCP client: %v", err)
+		}
+		gcpClient = client
+	} else {
+		gcpClient = gcpauth.NewGCPClient()
+	}
+
+	fmt.Printf("[%s][%s] GCP Caller: %s\n", cyan(emoji.Sprintf(":fox:cloudfox v%s :fox:", cmd.Root().Version)), cyan(GCPProject), gcpClient.TokenInfo.Email)
+
+	if GCPUseCache {
+		cacheDirectory := filepath.Join(GCPOutputDirectory, "cached-data", "gcp", GCPProject)
+		if err := internal.LoadCacheFromGobFiles(cacheDirectory); err != nil {
+			log.Fatalf("failed to load cache: %v", err)
+		}
+	}
+}
+
+// providerPostRun is the GCP-side counterpart to awsPostRun: it saves
+// whatever got cached during this run under cached-data/gcp/<project>.
+func providerPostRun(cmd *cobra.Command, args []string) {
+	outputDirectory := filepath.Join(GCPOutputDirectory, "cached-data", "gcp", GCPProject)
+	if err := internal.SaveCacheToGobFiles(outputDirectory, GCPProject); err != nil {
+		log.Fatalf("failed to save cache: %v", err)
+	}
+
+	fmt.Printf("[%s][%s] Cached GCP data written to %s\n", cyan(emoji.Sprintf(":fox:cloudfox v%s :fox:", cmd.Root().Version)), cyan(GCPProject), outputDirectory)
+}
+
+func runGCPInventoryCommand(cmd *cobra.Command, args []string) {
+	m := gcp.InventoryModule{
+		GCPClient:     gcpClient,
+		Project:       GCPProject,
+		GCPOutputType: GCPOutputType,
+		WrapTable:     GCPWrapTable,
+	}
+	m.PrintInventory(GCPOutputDirectory, Verbosity)
+}
+
+func runGCPBucketsCommand(cmd *cobra.Command, args []string) {
+	m := gcp.BucketsModule{
+		GCPClient:     gcpClient,
+		Project:       GCPProject,
+		GCPOutputType: GCPOutputType,
+		WrapTable:     GCPWrapTable,
+	}
+	m.PrintBuckets(GCPOutputDirectory, Verbosity)
+}
+
+func runGCPInstancesCommand(cmd *cobra.Command, args []string) {
+	m := gcp.InstancesModule{
+		GCPClient:     gcpClient,
+		Project:       GCPProject,
+		GCPOutputType: GCPOutputType,
+		WrapTable:     GCPWrapTable,
+	}
+	m.PrintInstances(GCPOutputDirectory, Verbosity)
+}
+
+func runGCPSecretsCommand(cmd *cobra.Command, args []string) {
+	m := gcp.SecretsModule{
+		GCPClient:     gcpClient,
+		Project:       GCPProject,
+		GCPOutputType: GCPOutputType,
+		WrapTable:     GCPWrapTable,
+	}
+	m.PrintSecrets(GCPOutputDirectory, Verbosity)
+}
+
+func runGCPPermissionsCommand(cmd *cobra.Command, args []string) {
+	m := gcp.PermissionsModule{
+		GCPClient:         gcpClient,
+		Project:           GCPProject,
+		PermissionsMember: GCPPermissionsMember,
+		GCPOutputType:     GCPOutputType,
+		WrapTable:         GCPWrapTable,
+	}
+	m.PrintPermissions(GCPOutputDirectory, Verbosity)
+}
+
+func runGCPPrincipalsCommand(cmd *cobra.Command, args []string) {
+	m := gcp.PrincipalsModule{
+		GCPClient:     gcpClient,
+		Project:       GCPProject,
+		GCPOutputType: GCPOutputType,
+		WrapTable:     GCPWrapTable,
+	}
+	m.PrintPrincipals(GCPOutputDirectory, Verbosity)
+}
+
+func runGCPEndpointsCommand(cmd *cobra.Command, args []string) {
+	m := gcp.EndpointsModule{
+		GCPClient:     gcpClient,
+		Project:       GCPProject,
+		GCPOutputType: GCPOutputType,
+		WrapTable:     GCPWrapTable,
+	}
+	m.PrintEndpoints(GCPOutputDirectory, Verbosity)
+}
+
+func runGCPAllChecksCommand(cmd *cobra.Command, args []string) {
+	m := gcp.AllChecksModule{
+		GCPClient:     gcpClient,
+		Project:       GCPProject,
+		GCPOutputType: GCPOutputType,
+		WrapTable:     GCPWrapTable,
+	}
+	m.PrintAllChecks(GCPOutputDirectory, Verbosity)
+}
+
+// newGCPVaultClient builds a vaultauth client from a Vault GCP secrets
+// engine roleset instead of the local gcloud profile chain, for running
+// unattended against a token Vault mints and leases rather than something
+// written to ~/.config/gcloud ahead of time.
+func newGCPVaultClient() (*gcpauth.GCPClient, error) {
+	vaultCfg := vaultapi.DefaultConfig()
+	if GCPVaultAddr != "" {
+		vaultCfg.Address = GCPVaultAddr
+	}
+	vaultClient, err := vaultapi.NewClient(vaultCfg)
+	if err != nil {
+		return nil, fmt.Errorf("creating vault client: %s", err)
+	}
+
+	source := credprovider.VaultGCPSource{
+		Client:    vaultClient,
+		MountPath: GCPVaultMount,
+		RoleSet:   GCPVaultRoleSet,
+	}
+	ts := credprovider.GCPTokenSource{
+		Ctx:   context.Background(),
+		Cache: credprovider.NewCache[string](source, 2*time.Minute),
+	}
+
+	return gcpauth.NewGCPClientFromTokenSource(ts), nil
+}
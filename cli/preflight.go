@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/BishopFox/cloudfox/aws"
+	"github.com/BishopFox/cloudfox/internal"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	yellow                   = color.New(color.FgYellow).SprintFunc()
+	AWSIgnorePermissionCheck bool
+)
+
+// commandRequiredActions is a static map of command name -> the IAM
+// actions that command's module needs at minimum. It's intentionally
+// conservative (one or two actions per command) - the goal is catching
+// "this profile obviously can't run this" up front, rather than
+// simulating every API call a module might end up making.
+var commandRequiredActions = map[string][]string{
+	"buckets":              {"s3:ListAllMyBuckets", "s3:GetBucketPolicy"},
+	"secrets":              {"secretsmanager:ListSecrets", "ssm:DescribeParameters"},
+	"instances":            {"ec2:DescribeInstances"},
+	"inventory":            {"resourcegroupstaggingapi:GetResources"},
+	"principals":           {"iam:ListUsers", "iam:ListRoles"},
+	"databases":            {"rds:DescribeDBInstances", "redshift:DescribeClusters"},
+	"ecs-tasks":            {"ecs:ListClusters", "ecs:ListTasks"},
+	"endpoints":            {"lambda:ListFunctions", "eks:ListClusters"},
+	"codebuild":            {"codebuild:ListProjects"},
+	"codebuild-builds":     {"codebuild:ListBuilds", "codebuild:BatchGetBuilds"},
+	"codebuild-policies":   {"codebuild:ListProjects", "codebuild:GetResourcePolicy"},
+	"codebuild-pivot":      {"codebuild:ListProjects", "codebuild:ListSourceCredentials"},
+	"codebuild-artifacts":  {"codebuild:ListProjects", "codebuild:ListReportGroups"},
+	"codebuild-escalation": {"codebuild:ListProjects", "iam:SimulatePrincipalPolicy"},
+}
+
+// filterRunnableProfiles simulates each profile's access to the current
+// command's required actions via iam:SimulatePrincipalPolicy and drops any
+// profile whose required actions all come back denied, printing a warning
+// instead of letting the module discover that the hard way per-region and
+// per-call. It mutates AWSProfiles in place since every run* function reads
+// that package-level slice. --ignore-permission-check bypasses this
+// entirely.
+func filterRunnableProfiles(cmd *cobra.Command) {
+	actions, ok := commandRequiredActions[cmd.Name()]
+	if !ok || AWSIgnorePermissionCheck {
+		return
+	}
+
+	var runnable []string
+	for _, profile := range AWSProfiles {
+		caller, err := internal.AWSWhoami(profile, cmd.Root().Version, AWSMFAToken)
+		if err != nil {
+			continue
+		}
+
+		AWSConfig := internal.AWSConfigFileLoader(profile, cmd.Root().Version, AWSMFAToken)
+		denied, err := aws.SimulateCallerActions(iam.NewFromConfig(AWSConfig), *caller.Arn, actions)
+		if err != nil {
+			// Can't simulate (e.g. the caller also lacks
+			// iam:SimulatePrincipalPolicy) - don't block the module, let
+			// it run and hit AccessDenied naturally.
+			runnable = append(runnable, profile)
+			continue
+		}
+
+		if len(denied) < len(actions) {
+			runnable = append(runnable, profile)
+			continue
+		}
+
+		printPermissionSkipWarning(cmd, profile, caller, denied)
+	}
+
+	AWSProfiles = runnable
+
+	if len(AWSProfiles) == 0 {
+		fmt.Printf("[%s] %s\n", cyan(cmd.Name()), yellow("No profile has the permissions required to run this command. Skipping."))
+		cmd.Run = func(cmd *cobra.Command, args []string) {}
+	}
+}
+
+func printPermissionSkipWarning(cmd *cobra.Command, profile string, caller *sts.GetCallerIdentityOutput, denied []string) {
+	fmt.Printf("[%s][%s] %s\n", cyan(cmd.Name()), yellow(profile), yellow(fmt.Sprintf(
+		"Skipping - %s is missing the permissions this command needs: %v", *caller.Arn, denied,
+	)))
+}
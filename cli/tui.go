@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"log"
+
+	"github.com/spf13/cobra"
+
+	"github.com/BishopFox/cloudfox/internal/tui"
+)
+
+// TuiOutputDirectory is the cloudfox-output root the TUI browses; there's no
+// root cobra.Command in this checkout to attach TuiCommand to (see the
+// comment below), so it doesn't inherit AWSOutputDirectory/GCPOutputDirectory/
+// AzOutputDirectory and gets its own --outdir instead.
+var TuiOutputDirectory string
+
+// TuiCommand is "cloudfox tui": an interactive browser over whatever
+// cloudfox-output a prior aws/gcp/azure run left behind. Like AWSCommands,
+// GCPCommands, and AzCommands, it isn't wired into a root cobra.Command
+// anywhere in this tree - there's no cmd/cloudfox/main.go here to do that
+// wiring in, just these provider command trees sitting unattached.
+var TuiCommand = &cobra.Command{
+	Use:   "tui",
+	Short: "Interactively browse cloudfox-output across providers",
+	Long: `
+Browse whatever aws/gcp/azure runs have already written to an output
+directory: provider -> account/project/subscription -> module -> row, with
+"/" to filter rows, "c" to toggle columns, and a detail pane for the
+selected row.
+
+./cloudfox tui --outdir ./cloudfox-output-parent-dir`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := tui.NewApp(TuiOutputDirectory).Run(); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	TuiCommand.Flags().StringVar(&TuiOutputDirectory, "outdir", defaultOutputDir, "Output directory to browse")
+}
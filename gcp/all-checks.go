@@ -0,0 +1,40 @@
+package gcp
+
+import (
+	"fmt"
+
+	gcpauth "github.com/BishopFox/cloudfox/internal/gcp"
+)
+
+// AllChecksModule runs the inventory, buckets, instances, secrets,
+// principals and endpoints modules against a single project, the GCP analog
+// of the aws "all-checks" command. The permissions module is not run.
+type AllChecksModule struct {
+	GCPClient *gcpauth.GCPClient
+
+	Project       string
+	GCPOutputType string
+	WrapTable     bool
+}
+
+func (m *AllChecksModule) PrintAllChecks(outputDirectory string, verbosity int) {
+	fmt.Printf("[%s][%s] Running all checks for project %s\n", cyan("all-checks"), cyan(m.Project), m.Project)
+
+	inventory := InventoryModule{GCPClient: m.GCPClient, Project: m.Project, GCPOutputType: m.GCPOutputType, WrapTable: m.WrapTable}
+	inventory.PrintInventory(outputDirectory, verbosity)
+
+	buckets := BucketsModule{GCPClient: m.GCPClient, Project: m.Project, GCPOutputType: m.GCPOutputType, WrapTable: m.WrapTable}
+	buckets.PrintBuckets(outputDirectory, verbosity)
+
+	instances := InstancesModule{GCPClient: m.GCPClient, Project: m.Project, GCPOutputType: m.GCPOutputType, WrapTable: m.WrapTable}
+	instances.PrintInstances(outputDirectory, verbosity)
+
+	secrets := SecretsModule{GCPClient: m.GCPClient, Project: m.Project, GCPOutputType: m.GCPOutputType, WrapTable: m.WrapTable}
+	secrets.PrintSecrets(outputDirectory, verbosity)
+
+	principals := PrincipalsModule{GCPClient: m.GCPClient, Project: m.Project, GCPOutputType: m.GCPOutputType, WrapTable: m.WrapTable}
+	principals.PrintPrincipals(outputDirectory, verbosity)
+
+	endpoints := EndpointsModule{GCPClient: m.GCPClient, Project: m.Project, GCPOutputType: m.GCPOutputType, WrapTable: m.WrapTable}
+	endpoints.PrintEndpoints(outputDirectory, verbosity)
+}
@@ -0,0 +1,98 @@
+package gcp
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/BishopFox/cloudfox/internal"
+	gcpauth "github.com/BishopFox/cloudfox/internal/gcp"
+	"github.com/sirupsen/logrus"
+)
+
+// BucketsModule enumerates Cloud Storage buckets, the GCP analog of the aws
+// "buckets" command.
+type BucketsModule struct {
+	GCPClient *gcpauth.GCPClient
+
+	Project       string
+	GCPOutputType string
+	WrapTable     bool
+
+	MappedBuckets  []MappedGCPBucket
+	CommandCounter internal.CommandCounter
+
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type MappedGCPBucket struct {
+	Project  string
+	Name     string
+	Location string
+}
+
+func (m *BucketsModule) PrintBuckets(outputDirectory string, verbosity int) {
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "buckets"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+
+	fmt.Printf("[%s][%s] Enumerating Cloud Storage buckets for project %s\n", cyan(m.output.CallingModule), cyan(m.Project), m.Project)
+
+	m.CommandCounter.Total++
+	results, err := SearchAllAssets(m.GCPClient, m.Project, "storage.googleapis.com/Bucket")
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+	for _, result := range results {
+		m.MappedBuckets = append(m.MappedBuckets, MappedGCPBucket{
+			Project:  m.Project,
+			Name:     result.DisplayName,
+			Location: result.Location,
+		})
+	}
+
+	m.output.Headers = []string{
+		"Project",
+		"Name",
+		"Location",
+	}
+
+	for _, bucket := range m.MappedBuckets {
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				bucket.Project,
+				bucket.Name,
+				bucket.Location,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "gcp", m.Project)
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: m.output.Headers,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.Project
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "gcp", m.Project)
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s][%s] %s buckets found.\n", cyan(m.output.CallingModule), cyan(m.Project), strconv.Itoa(len(m.output.Body)))
+	} else {
+		fmt.Printf("[%s][%s] No buckets found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.Project))
+	}
+}
@@ -0,0 +1,116 @@
+package gcp
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/BishopFox/cloudfox/internal"
+	gcpauth "github.com/BishopFox/cloudfox/internal/gcp"
+	"github.com/sirupsen/logrus"
+)
+
+// endpointAssetTypes are the cloudasset types that typically expose a
+// network-reachable endpoint, mirroring the service list the aws
+// "endpoints" command pulls from (compute, serverless, managed DBs).
+var endpointAssetTypes = []string{
+	"compute.googleapis.com/Instance",
+	"compute.googleapis.com/ForwardingRule",
+	"run.googleapis.com/Service",
+	"appengine.googleapis.com/Application",
+	"sqladmin.googleapis.com/Instance",
+}
+
+// EndpointsModule enumerates resources that are likely to expose a
+// network endpoint, the GCP analog of the aws "endpoints" command.
+type EndpointsModule struct {
+	GCPClient *gcpauth.GCPClient
+
+	Project       string
+	GCPOutputType string
+	WrapTable     bool
+
+	MappedEndpoints []MappedGCPEndpoint
+	CommandCounter  internal.CommandCounter
+
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type MappedGCPEndpoint struct {
+	Project   string
+	AssetType string
+	Name      string
+	Location  string
+}
+
+func (m *EndpointsModule) PrintEndpoints(outputDirectory string, verbosity int) {
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "endpoints"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+
+	fmt.Printf("[%s][%s] Enumerating potential network endpoints for project %s\n", cyan(m.output.CallingModule), cyan(m.Project), m.Project)
+
+	for _, assetType := range endpointAssetTypes {
+		m.CommandCounter.Total++
+		results, err := SearchAllAssets(m.GCPClient, m.Project, assetType)
+		if err != nil {
+			m.modLog.Error(err.Error())
+			m.CommandCounter.Error++
+			continue
+		}
+		for _, result := range results {
+			m.MappedEndpoints = append(m.MappedEndpoints, MappedGCPEndpoint{
+				Project:   m.Project,
+				AssetType: result.AssetType,
+				Name:      result.DisplayName,
+				Location:  result.Location,
+			})
+		}
+	}
+
+	m.output.Headers = []string{
+		"Project",
+		"AssetType",
+		"Name",
+		"Location",
+	}
+
+	for _, endpoint := range m.MappedEndpoints {
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				endpoint.Project,
+				endpoint.AssetType,
+				endpoint.Name,
+				endpoint.Location,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "gcp", m.Project)
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: m.output.Headers,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.Project
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "gcp", m.Project)
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s][%s] %s potential endpoints found.\n", cyan(m.output.CallingModule), cyan(m.Project), strconv.Itoa(len(m.output.Body)))
+	} else {
+		fmt.Printf("[%s][%s] No potential endpoints found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.Project))
+	}
+}
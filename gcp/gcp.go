@@ -0,0 +1,36 @@
+package gcp
+
+import (
+	"fmt"
+
+	gcpauth "github.com/BishopFox/cloudfox/internal/gcp"
+	"github.com/fatih/color"
+	"google.golang.org/api/cloudasset/v1p1beta1"
+)
+
+var (
+	cyan = color.New(color.FgCyan).SprintFunc()
+)
+
+// SearchAllAssets wraps the cloudasset v1p1beta1 SearchAllResources call
+// that every gcp module filters down to the asset type(s) it cares about.
+// Passing an empty assetType returns every resource type in the project,
+// which is what the inventory module wants.
+func SearchAllAssets(client *gcpauth.GCPClient, project string, assetType string) ([]*cloudasset.StandardResourceMetadata, error) {
+	scope := fmt.Sprintf("projects/%s", project)
+	call := client.ResourcesService.SearchAll(scope)
+	if assetType != "" {
+		call = call.AssetTypes(assetType)
+	}
+
+	var results []*cloudasset.StandardResourceMetadata
+	err := call.Pages(nil, func(page *cloudasset.SearchAllResourcesResponse) error {
+		results = append(results, page.Results...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("searching assets for project %s: %s", project, err)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,98 @@
+package gcp
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/BishopFox/cloudfox/internal"
+	gcpauth "github.com/BishopFox/cloudfox/internal/gcp"
+	"github.com/sirupsen/logrus"
+)
+
+// InstancesModule enumerates Compute Engine instances, the GCP analog of
+// the aws "instances" command.
+type InstancesModule struct {
+	GCPClient *gcpauth.GCPClient
+
+	Project       string
+	GCPOutputType string
+	WrapTable     bool
+
+	MappedInstances []MappedGCPInstance
+	CommandCounter  internal.CommandCounter
+
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type MappedGCPInstance struct {
+	Project  string
+	Name     string
+	Location string
+}
+
+func (m *InstancesModule) PrintInstances(outputDirectory string, verbosity int) {
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "instances"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+
+	fmt.Printf("[%s][%s] Enumerating Compute Engine instances for project %s\n", cyan(m.output.CallingModule), cyan(m.Project), m.Project)
+
+	m.CommandCounter.Total++
+	results, err := SearchAllAssets(m.GCPClient, m.Project, "compute.googleapis.com/Instance")
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+	for _, result := range results {
+		m.MappedInstances = append(m.MappedInstances, MappedGCPInstance{
+			Project:  m.Project,
+			Name:     result.DisplayName,
+			Location: result.Location,
+		})
+	}
+
+	m.output.Headers = []string{
+		"Project",
+		"Name",
+		"Location",
+	}
+
+	for _, instance := range m.MappedInstances {
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				instance.Project,
+				instance.Name,
+				instance.Location,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "gcp", m.Project)
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: m.output.Headers,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.Project
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "gcp", m.Project)
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s][%s] %s instances found.\n", cyan(m.output.CallingModule), cyan(m.Project), strconv.Itoa(len(m.output.Body)))
+	} else {
+		fmt.Printf("[%s][%s] No instances found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.Project))
+	}
+}
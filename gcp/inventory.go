@@ -0,0 +1,113 @@
+package gcp
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/BishopFox/cloudfox/internal"
+	gcpauth "github.com/BishopFox/cloudfox/internal/gcp"
+	"github.com/sirupsen/logrus"
+)
+
+// InventoryModule enumerates every resource cloudasset knows about for a
+// project and prints a single table, one row per resource in the order
+// cloudasset returns them (rows are not grouped or sorted by asset type),
+// mirroring the role the aws "inventory" command plays for an AWS account.
+type InventoryModule struct {
+	GCPClient *gcpauth.GCPClient
+
+	Project       string
+	GCPOutputType string
+	GCPTableCols  string
+	WrapTable     bool
+
+	MappedResources []MappedGCPResource
+	CommandCounter  internal.CommandCounter
+
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type MappedGCPResource struct {
+	Project   string
+	AssetType string
+	Name      string
+	Location  string
+}
+
+func (m *InventoryModule) PrintInventory(outputDirectory string, verbosity int) {
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "inventory"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+
+	fmt.Printf("[%s][%s] Enumerating resources for project %s\n", cyan(m.output.CallingModule), cyan(m.Project), m.Project)
+
+	m.CommandCounter.Total++
+	if err := m.getResources(); err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+
+	m.output.Headers = []string{
+		"Project",
+		"AssetType",
+		"Name",
+		"Location",
+	}
+
+	for _, resource := range m.MappedResources {
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				resource.Project,
+				resource.AssetType,
+				resource.Name,
+				resource.Location,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "gcp", m.Project)
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: m.output.Headers,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.Project
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "gcp", m.Project)
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s][%s] %s resources found.\n", cyan(m.output.CallingModule), cyan(m.Project), strconv.Itoa(len(m.output.Body)))
+	} else {
+		fmt.Printf("[%s][%s] No resources found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.Project))
+	}
+}
+
+func (m *InventoryModule) getResources() error {
+	results, err := SearchAllAssets(m.GCPClient, m.Project, "")
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		m.MappedResources = append(m.MappedResources, MappedGCPResource{
+			Project:   m.Project,
+			AssetType: result.AssetType,
+			Name:      result.DisplayName,
+			Location:  result.Location,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package gcp
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/BishopFox/cloudfox/internal"
+	gcpauth "github.com/BishopFox/cloudfox/internal/gcp"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/api/cloudresourcemanager/v3"
+)
+
+// PermissionsModule answers "what can this principal actually do on this
+// project" by calling TestIamPermissions with the full set of IAM
+// permissions cloudfox knows about, the GCP analog of the aws
+// "permissions" / iam-simulator command.
+type PermissionsModule struct {
+	GCPClient *gcpauth.GCPClient
+
+	Project string
+	// PermissionsMember is accepted for parity with the aws command's
+	// --principal flag, but TestIamPermissions only ever evaluates the
+	// credential cloudfox is authenticated as - GCP has no unauthenticated
+	// "simulate as" API like IAM policy simulator on the AWS side.
+	PermissionsMember string
+	GCPOutputType     string
+	WrapTable         bool
+
+	GrantedPermissions []string
+	CommandCounter     internal.CommandCounter
+
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+func (m *PermissionsModule) PrintPermissions(outputDirectory string, verbosity int) {
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "permissions"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+
+	fmt.Printf("[%s][%s] Testing IAM permissions for project %s\n", cyan(m.output.CallingModule), cyan(m.Project), m.Project)
+
+	m.CommandCounter.Total++
+	if err := m.testPermissions(); err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+
+	m.output.Headers = []string{
+		"Project",
+		"Permission",
+	}
+
+	for _, permission := range m.GrantedPermissions {
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				m.Project,
+				permission,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "gcp", m.Project)
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: m.output.Headers,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.Project
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "gcp", m.Project)
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s][%s] %s granted permissions found.\n", cyan(m.output.CallingModule), cyan(m.Project), strconv.Itoa(len(m.output.Body)))
+	} else {
+		fmt.Printf("[%s][%s] No granted permissions found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.Project))
+	}
+}
+
+func (m *PermissionsModule) testPermissions() error {
+	request := &cloudresourcemanager.TestIamPermissionsRequest{
+		Permissions: commonGCPPermissions,
+	}
+
+	response, err := m.GCPClient.ProjectsService.TestIamPermissions(m.Project, request).Do()
+	if err != nil {
+		return fmt.Errorf("testing IAM permissions for project %s: %s", m.Project, err)
+	}
+
+	m.GrantedPermissions = response.Permissions
+	return nil
+}
+
+// commonGCPPermissions is the set of high-signal permissions cloudfox
+// checks for when no narrower scope is given - storage/compute/secret
+// read-write and IAM-impersonation permissions that matter most during an
+// assessment.
+var commonGCPPermissions = []string{
+	"resourcemanager.projects.getIamPolicy",
+	"resourcemanager.projects.setIamPolicy",
+	"iam.serviceAccounts.getAccessToken",
+	"iam.serviceAccounts.actAs",
+	"storage.buckets.list",
+	"storage.objects.list",
+	"storage.objects.get",
+	"compute.instances.list",
+	"compute.instances.get",
+	"secretmanager.secrets.list",
+	"secretmanager.versions.access",
+}
@@ -0,0 +1,166 @@
+package gcp
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BishopFox/cloudfox/internal"
+	gcpauth "github.com/BishopFox/cloudfox/internal/gcp"
+	"github.com/BishopFox/cloudfox/internal/privesc"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/api/cloudasset/v1p1beta1"
+)
+
+// PrincipalsModule enumerates the members bound to IAM policies in a
+// project, the GCP analog of the aws "principals" command.
+type PrincipalsModule struct {
+	GCPClient *gcpauth.GCPClient
+
+	Project       string
+	GCPOutputType string
+	WrapTable     bool
+
+	MappedPrincipals []MappedGCPPrincipal
+	CommandCounter   internal.CommandCounter
+
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type MappedGCPPrincipal struct {
+	Project           string
+	Member            string
+	Role              string
+	Resource          string
+	Admin             string
+	CanPrivEscToAdmin string
+}
+
+func (m *PrincipalsModule) PrintPrincipals(outputDirectory string, verbosity int) {
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "principals"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+
+	fmt.Printf("[%s][%s] Enumerating IAM principals for project %s\n", cyan(m.output.CallingModule), cyan(m.Project), m.Project)
+
+	m.CommandCounter.Total++
+	if err := m.getPrincipals(); err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+	if err := m.setPrivescResults(); err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+
+	m.output.Headers = []string{
+		"Project",
+		"Member",
+		"Role",
+		"Resource",
+		"Admin",
+		"CanPrivEscToAdmin?",
+	}
+
+	for _, principal := range m.MappedPrincipals {
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				principal.Project,
+				principal.Member,
+				principal.Role,
+				principal.Resource,
+				principal.Admin,
+				principal.CanPrivEscToAdmin,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "gcp", m.Project)
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: m.output.Headers,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.Project
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "gcp", m.Project)
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s][%s] %s IAM bindings found.\n", cyan(m.output.CallingModule), cyan(m.Project), strconv.Itoa(len(m.output.Body)))
+	} else {
+		fmt.Printf("[%s][%s] No IAM bindings found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.Project))
+	}
+}
+
+func (m *PrincipalsModule) getPrincipals() error {
+	scope := fmt.Sprintf("projects/%s", m.Project)
+	call := m.GCPClient.IamPoliciesService.SearchAll(scope)
+
+	var err error
+	pageErr := call.Pages(nil, func(page *cloudasset.SearchAllIamPoliciesResponse) error {
+		for _, result := range page.Results {
+			if result.Policy == nil {
+				continue
+			}
+			for _, binding := range result.Policy.Bindings {
+				for _, member := range binding.Members {
+					m.MappedPrincipals = append(m.MappedPrincipals, MappedGCPPrincipal{
+						Project:  m.Project,
+						Member:   member,
+						Role:     binding.Role,
+						Resource: result.Resource,
+						Admin:    isGCPAdminRole(binding.Role),
+					})
+				}
+			}
+		}
+		return nil
+	})
+	if pageErr != nil {
+		err = fmt.Errorf("searching IAM policies for project %s: %s", m.Project, pageErr)
+	}
+	return err
+}
+
+// setPrivescResults builds an internal/privesc reachability graph from the
+// bindings already collected by getPrincipals and fills in
+// CanPrivEscToAdmin? for each row - the GCP analog of aws.IamPrincipalsModule
+// calling GetPmapperResults, since GCP has no pmapper equivalent to lean on.
+func (m *PrincipalsModule) setPrivescResults() error {
+	bindings := make([]privesc.GCPBinding, 0, len(m.MappedPrincipals))
+	for _, p := range m.MappedPrincipals {
+		bindings = append(bindings, privesc.GCPBinding{Member: p.Member, Role: p.Role, Resource: p.Resource})
+	}
+
+	graph, err := privesc.BuildGCPGraph(bindings)
+	if err != nil {
+		return fmt.Errorf("building privesc graph for project %s: %s", m.Project, err)
+	}
+
+	for i := range m.MappedPrincipals {
+		_, m.MappedPrincipals[i].CanPrivEscToAdmin = privesc.GetGcpPrivescResults(graph, m.MappedPrincipals[i].Member)
+	}
+	return nil
+}
+
+func isGCPAdminRole(role string) string {
+	switch {
+	case strings.HasSuffix(role, "/owner"), strings.HasSuffix(role, "/editor"):
+		return "YES"
+	default:
+		return "No"
+	}
+}
@@ -0,0 +1,95 @@
+package gcp
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/BishopFox/cloudfox/internal"
+	gcpauth "github.com/BishopFox/cloudfox/internal/gcp"
+	"github.com/sirupsen/logrus"
+)
+
+// SecretsModule enumerates Secret Manager secrets, the GCP analog of the
+// aws "secrets" command. It does not read secret values - like the aws
+// module, it's meant to point an operator at what's worth pulling by hand.
+type SecretsModule struct {
+	GCPClient *gcpauth.GCPClient
+
+	Project       string
+	GCPOutputType string
+	WrapTable     bool
+
+	MappedSecrets  []MappedGCPSecret
+	CommandCounter internal.CommandCounter
+
+	output internal.OutputData2
+	modLog *logrus.Entry
+}
+
+type MappedGCPSecret struct {
+	Project string
+	Name    string
+}
+
+func (m *SecretsModule) PrintSecrets(outputDirectory string, verbosity int) {
+	m.output.Verbosity = verbosity
+	m.output.Directory = outputDirectory
+	m.output.CallingModule = "secrets"
+	m.modLog = internal.TxtLog.WithFields(logrus.Fields{
+		"module": m.output.CallingModule,
+	})
+
+	fmt.Printf("[%s][%s] Enumerating Secret Manager secrets for project %s\n", cyan(m.output.CallingModule), cyan(m.Project), m.Project)
+
+	m.CommandCounter.Total++
+	results, err := SearchAllAssets(m.GCPClient, m.Project, "secretmanager.googleapis.com/Secret")
+	if err != nil {
+		m.modLog.Error(err.Error())
+		m.CommandCounter.Error++
+	}
+	for _, result := range results {
+		m.MappedSecrets = append(m.MappedSecrets, MappedGCPSecret{
+			Project: m.Project,
+			Name:    result.DisplayName,
+		})
+	}
+
+	m.output.Headers = []string{
+		"Project",
+		"Name",
+	}
+
+	for _, secret := range m.MappedSecrets {
+		m.output.Body = append(
+			m.output.Body,
+			[]string{
+				secret.Project,
+				secret.Name,
+			},
+		)
+	}
+
+	if len(m.output.Body) > 0 {
+		m.output.FilePath = filepath.Join(outputDirectory, "cloudfox-output", "gcp", m.Project)
+		o := internal.OutputClient{
+			Verbosity:     verbosity,
+			CallingModule: m.output.CallingModule,
+			Table: internal.TableClient{
+				Wrap: m.WrapTable,
+			},
+		}
+		o.Table.TableFiles = append(o.Table.TableFiles, internal.TableFile{
+			Header:    m.output.Headers,
+			Body:      m.output.Body,
+			TableCols: m.output.Headers,
+			Name:      m.output.CallingModule,
+		})
+		o.PrefixIdentifier = m.Project
+		o.Table.DirectoryName = filepath.Join(outputDirectory, "cloudfox-output", "gcp", m.Project)
+		o.WriteFullOutput(o.Table.TableFiles, nil)
+		fmt.Printf("[%s][%s] %s secrets found.\n", cyan(m.output.CallingModule), cyan(m.Project), strconv.Itoa(len(m.output.Body)))
+	} else {
+		fmt.Printf("[%s][%s] No secrets found, skipping the creation of an output file.\n", cyan(m.output.CallingModule), cyan(m.Project))
+	}
+}
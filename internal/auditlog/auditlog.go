@@ -0,0 +1,96 @@
+package auditlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entry is one line of the audit log - one SDK call cloudfox made, with
+// enough context (who, from where, against what) to reconstruct a
+// CloudTrail-style record of the engagement after the fact.
+type Entry struct {
+	Timestamp      time.Time `json:"timestamp"`
+	Profile        string    `json:"profile"`
+	CallerArn      string    `json:"caller_arn"`
+	Account        string    `json:"account"`
+	Region         string    `json:"region"`
+	Service        string    `json:"service"`
+	Operation      string    `json:"operation"`
+	RequestID      string    `json:"request_id,omitempty"`
+	HTTPStatusCode int       `json:"http_status_code,omitempty"`
+	LatencyMS      int64     `json:"latency_ms"`
+	ErrorClass     string    `json:"error_class,omitempty"`
+}
+
+// Logger appends Entry records as newline-delimited JSON to a single file.
+// cli/aws.go opens one Logger per profile, by default at a per-account path.
+type Logger struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// Open creates (or appends to) the audit log file at path, creating parent
+// directories as needed.
+func Open(path string) (*Logger, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("creating directory for %s: %s", path, err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("opening audit log %s: %s", path, err)
+	}
+
+	return &Logger{file: file}, nil
+}
+
+// Log appends one entry as a single JSON line.
+func (l *Logger) Log(entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("marshalling audit log entry: %s", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.file.Write(append(data, '\n'))
+	return err
+}
+
+// Close closes the underlying file.
+func (l *Logger) Close() error {
+	return l.file.Close()
+}
+
+// DefaultPath returns the default audit log location for an account:
+// <outputDirectory>/audit/<account>.log.json.
+func DefaultPath(outputDirectory, account string) string {
+	return filepath.Join(outputDirectory, "audit", account+".log.json")
+}
+
+// ReadEntries reads back every Entry previously written by Logger.Log from
+// path, for summarization or engagement-to-engagement diffing.
+func ReadEntries(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading audit log %s: %s", path, err)
+	}
+
+	var entries []Entry
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, fmt.Errorf("parsing audit log line: %s", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
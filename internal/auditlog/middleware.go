@@ -0,0 +1,67 @@
+package auditlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	smithy "github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// Middleware returns an aws.Config APIOptions entry that logs one Entry per
+// SDK call made through an aws.Config it is installed on, mirroring what
+// CloudTrail would show for the same principal. credbroker.Broker installs
+// it when its AuditLogger is set; the Init*Client constructors do not, so
+// calls made by modules are not logged.
+func Middleware(logger *Logger, profile, callerArn, account string) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("AuditLog", func(
+			ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+		) (middleware.FinalizeOutput, middleware.Metadata, error) {
+			start := time.Now()
+			out, metadata, err := next.HandleFinalize(ctx, in)
+
+			entry := Entry{
+				Timestamp: start,
+				Profile:   profile,
+				CallerArn: callerArn,
+				Account:   account,
+				Region:    awsmiddleware.GetRegion(ctx),
+				Service:   awsmiddleware.GetServiceID(ctx),
+				Operation: awsmiddleware.GetOperationName(ctx),
+				LatencyMS: time.Since(start).Milliseconds(),
+			}
+
+			if requestID, ok := awsmiddleware.GetRequestIDMetadata(metadata); ok {
+				entry.RequestID = requestID
+			}
+			if response, ok := awsmiddleware.GetRawResponse(metadata).(*smithyhttp.Response); ok && response != nil {
+				entry.HTTPStatusCode = response.StatusCode
+			}
+			if err != nil {
+				entry.ErrorClass = errorClass(err)
+			}
+
+			if logErr := logger.Log(entry); logErr != nil {
+				fmt.Printf("[-] Failed to write audit log entry: %s\n", logErr)
+			}
+
+			return out, metadata, err
+		}), middleware.After)
+	}
+}
+
+// errorClass reduces an SDK error down to its API error code when it's a
+// smithy API error, falling back to the raw error string for anything else
+// (network errors, context cancellation, etc).
+func errorClass(err error) string {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode()
+	}
+	return err.Error()
+}
@@ -0,0 +1,347 @@
+package internal
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BishopFox/cloudfox/internal/credmgr"
+	"github.com/BishopFox/cloudfox/internal/credprovider"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+	ssooidcTypes "github.com/aws/aws-sdk-go-v2/service/ssooidc/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go/logging"
+	"github.com/sirupsen/logrus"
+)
+
+// RefreshWindow and WriteBack* are set from the --refresh-window and
+// --write-back-profile CLI flags. When RefreshWindow is non-zero, every
+// aws.Config AWSConfigFileLoader hands out transparently refreshes its
+// credentials once they're within RefreshWindow of expiring, instead of
+// holding onto a snapshot for the life of a long cape/graph run - see
+// internal/credmgr.
+var (
+	RefreshWindow    time.Duration
+	WriteBackFile    string
+	WriteBackProfile string
+)
+
+// VaultAWSCache, set from --vault-addr/--vault-aws-mount/--vault-aws-role,
+// switches AWSConfigFileLoader from shared-config profiles to a Vault-minted
+// credential: every call to AWSConfigFileLoader resolves against the same
+// Vault secret path regardless of the profile name given, the same way
+// RefreshWindow transparently changes what AWSConfigFileLoader hands back
+// without every call site needing to know about it.
+var VaultAWSCache *credprovider.Cache[credprovider.AWSCredentials]
+
+// AWSSDKLogLevel and AWSSDKLogFile are set from the --aws-sdk-log-level and
+// --aws-sdk-log-file flags. AWSSDKLogLevel selects which aws.ClientLogMode
+// bits loadAWSConfig turns on for every client it builds (every
+// NewFromConfig call in cli/aws.go and its siblings goes through
+// AWSConfigFileLoader, so this one switch covers all of them). AWSSDKLogFile,
+// if set, redirects that logging to a file instead of through TxtLog, so a
+// single --aws-sdk-log-level=debug run against dozens of profiles doesn't
+// interleave every profile's wire traffic into the shared terminal log.
+var (
+	AWSSDKLogLevel string
+	AWSSDKLogFile  string
+)
+
+// awsSDKLogModes maps --aws-sdk-log-level to the aws.ClientLogMode bits it
+// turns on, mirroring aws-sdk-go v1's LogDebug/LogDebugWithSigning/
+// LogDebugWithHTTPBody levels. "off" (the default) matches the SDK's own
+// default: no request logging at all.
+var awsSDKLogModes = map[string]aws.ClientLogMode{
+	"off":     0,
+	"retries": aws.LogRetries,
+	"signing": aws.LogRetries | aws.LogSigning,
+	"debug":   aws.LogRetries | aws.LogRequest | aws.LogResponse,
+	"body":    aws.LogRetries | aws.LogRequestWithBody | aws.LogResponseWithBody,
+}
+
+// ValidateAWSSDKLogLevel reports whether level is a value awsSDKLogModes
+// recognizes, so awsPreRun can fail fast on a typo'd --aws-sdk-log-level
+// instead of silently running with no SDK logging.
+func ValidateAWSSDKLogLevel(level string) bool {
+	_, ok := awsSDKLogModes[level]
+	return ok
+}
+
+// AWSConfigFileLoader loads an aws.Config for profile the way the AWS CLI
+// would: static keys, MFA-protected assume-role, SSO (sso_session or the
+// legacy sso_start_url fields), role_arn+source_profile chains, and
+// credential_process are all resolved by the SDK's own shared-config
+// credential chain - cloudfox only needs to name the profile and, when it's
+// MFA-protected, supply a token code. If the profile is SSO-backed and its
+// cached token has expired, this runs the device-code login flow once and
+// retries before giving up.
+func AWSConfigFileLoader(profile string, version string, mfaToken string) aws.Config {
+	if VaultAWSCache != nil {
+		return vaultAWSConfig(profile)
+	}
+
+	cfg := rawAWSConfigFileLoader(profile, version, mfaToken)
+
+	if RefreshWindow > 0 {
+		cfg = credmgr.NewRefreshingConfig(profile, func(p string) aws.Config {
+			return rawAWSConfigFileLoader(p, version, mfaToken)
+		}, RefreshWindow, WriteBackFile, WriteBackProfile)
+	}
+
+	return cfg
+}
+
+// vaultAWSConfig builds an aws.Config whose credentials come from
+// VaultAWSCache instead of profile's shared-config entry - profile is kept
+// only so any errors can still be attributed to the -p/-l entry that
+// selected it.
+func vaultAWSConfig(profile string) aws.Config {
+	cfg, err := awsconfig.LoadDefaultConfig(context.Background(),
+		awsconfig.WithCredentialsProvider(credprovider.AWSCredentialsProvider{Cache: VaultAWSCache}))
+	if err != nil {
+		fmt.Printf("[-] Error building Vault-backed AWS config for profile %s: %s\n", profile, err)
+	}
+	return cfg
+}
+
+// rawAWSConfigFileLoader does the actual shared-config resolution (and
+// SSO-relogin retry) for a single snapshot of profile's credentials.
+// AWSConfigFileLoader wraps this in a RefreshingProvider when RefreshWindow
+// is set, rather than handing the snapshot straight to callers.
+func rawAWSConfigFileLoader(profile string, version string, mfaToken string) aws.Config {
+	ctx := context.Background()
+
+	cfg, err := loadAWSConfig(ctx, profile, mfaToken)
+	if err != nil && isExpiredSSOTokenError(err) {
+		if loginErr := ssoDeviceLogin(ctx, profile); loginErr != nil {
+			fmt.Printf("[-] Error refreshing SSO token for profile %s: %s\n", profile, loginErr)
+		} else {
+			cfg, err = loadAWSConfig(ctx, profile, mfaToken)
+		}
+	}
+	if err != nil {
+		fmt.Printf("[-] Error loading AWS config for profile %s: %s\n", profile, err)
+	}
+
+	return cfg
+}
+
+// AWSWhoami returns the caller identity for profile, resolving credentials
+// through the same chain AWSConfigFileLoader uses.
+func AWSWhoami(profile string, version string, mfaToken string) (*sts.GetCallerIdentityOutput, error) {
+	cfg := AWSConfigFileLoader(profile, version, mfaToken)
+
+	identity, err := sts.NewFromConfig(cfg).GetCallerIdentity(context.Background(), &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, fmt.Errorf("getting caller identity for profile %s: %s", profile, err)
+	}
+
+	return identity, nil
+}
+
+func loadAWSConfig(ctx context.Context, profile string, mfaToken string) (aws.Config, error) {
+	opts := []func(*awsconfig.LoadOptions) error{
+		awsconfig.WithSharedConfigProfile(profile),
+	}
+
+	if mfaToken != "" {
+		opts = append(opts, awsconfig.WithAssumeRoleCredentialOptions(func(o *stscreds.AssumeRoleOptions) {
+			o.TokenProvider = func() (string, error) {
+				return mfaToken, nil
+			}
+		}))
+	}
+
+	// --aws-sdk-log-level turns on SDK request/response/retry/signing
+	// logging for every client built through this loader, so a user can see
+	// exactly what the SDK sent and got back (throttling, 403s) without
+	// recompiling or reaching for tcpdump.
+	if mode, ok := awsSDKLogModes[AWSSDKLogLevel]; ok && mode != 0 {
+		opts = append(opts,
+			awsconfig.WithClientLogMode(mode),
+			awsconfig.WithLogger(newSDKLogAdapter()),
+		)
+	}
+
+	return awsconfig.LoadDefaultConfig(ctx, opts...)
+}
+
+// newSDKLogAdapter returns the logging.Logger loadAWSConfig hands the SDK
+// when --aws-sdk-log-level is set. With --aws-sdk-log-file unset it routes
+// through TxtLog (subject to the usual --log-format); with it set, it opens
+// (creating if needed) that file once per process and appends to it, so
+// dozens of profiles' wire traffic doesn't interleave into the shared
+// terminal log.
+func newSDKLogAdapter() logging.Logger {
+	if AWSSDKLogFile == "" {
+		return sdkLogAdapter{}
+	}
+
+	sdkLogFileOnce.Do(func() {
+		f, err := os.OpenFile(AWSSDKLogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+		if err != nil {
+			TxtLog.Errorf("opening --aws-sdk-log-file %s: %s", AWSSDKLogFile, err)
+			return
+		}
+		sdkFileLogger = logrus.New()
+		sdkFileLogger.SetOutput(f)
+		sdkFileLogger.SetFormatter(&logrus.TextFormatter{})
+	})
+	if sdkFileLogger == nil {
+		return sdkLogAdapter{}
+	}
+	return fileSDKLogAdapter{log: sdkFileLogger}
+}
+
+var (
+	sdkLogFileOnce sync.Once
+	sdkFileLogger  *logrus.Logger
+)
+
+// sdkLogAdapter routes the AWS SDK's own request/response/retry logging
+// through TxtLog.
+type sdkLogAdapter struct{}
+
+func (sdkLogAdapter) Logf(classification logging.Classification, format string, v ...interface{}) {
+	TxtLog.WithField("sdk_classification", classification).Debugf(format, v...)
+}
+
+// fileSDKLogAdapter is the --aws-sdk-log-file variant of sdkLogAdapter,
+// writing to log instead of TxtLog.
+type fileSDKLogAdapter struct {
+	log *logrus.Logger
+}
+
+func (a fileSDKLogAdapter) Logf(classification logging.Classification, format string, v ...interface{}) {
+	a.log.WithField("sdk_classification", classification).Infof(format, v...)
+}
+
+// isExpiredSSOTokenError reports whether err looks like an expired/missing
+// SSO token - the one failure mode worth auto-retrying after a fresh
+// device-code login, as opposed to a genuine misconfiguration.
+func isExpiredSSOTokenError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "SSO session")
+}
+
+// ssoDeviceLogin runs the SSO OIDC device-authorization flow for profile's
+// sso_start_url (or sso_session) and caches the resulting token under
+// ~/.aws/sso/cache, the same location and shape the SDK's own SSO
+// credential provider reads from - so the retried LoadDefaultConfig call
+// above picks it straight back up.
+func ssoDeviceLogin(ctx context.Context, profile string) error {
+	sharedCfg, err := awsconfig.LoadSharedConfigProfile(ctx, profile)
+	if err != nil {
+		return fmt.Errorf("reading shared config for profile %s: %s", profile, err)
+	}
+
+	startURL, region := sharedCfg.SSOStartURL, sharedCfg.SSORegion
+	if sharedCfg.SSOSession != nil {
+		startURL, region = sharedCfg.SSOSession.SSOStartURL, sharedCfg.SSOSession.SSORegion
+	}
+	if startURL == "" {
+		return fmt.Errorf("profile %s has no sso_start_url or sso_session to log in with", profile)
+	}
+
+	oidcCfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(region))
+	if err != nil {
+		return fmt.Errorf("loading AWS config for SSO region %s: %s", region, err)
+	}
+
+	client := ssooidc.NewFromConfig(oidcCfg)
+	register, err := client.RegisterClient(ctx, &ssooidc.RegisterClientInput{
+		ClientName: aws.String("cloudfox"),
+		ClientType: aws.String("public"),
+	})
+	if err != nil {
+		return fmt.Errorf("registering OIDC client: %s", err)
+	}
+
+	deviceAuth, err := client.StartDeviceAuthorization(ctx, &ssooidc.StartDeviceAuthorizationInput{
+		ClientId:     register.ClientId,
+		ClientSecret: register.ClientSecret,
+		StartUrl:     aws.String(startURL),
+	})
+	if err != nil {
+		return fmt.Errorf("starting device authorization: %s", err)
+	}
+
+	fmt.Printf("[*] SSO session for profile %s has expired - approve a new one at %s (code: %s)\n",
+		profile, aws.ToString(deviceAuth.VerificationUriComplete), aws.ToString(deviceAuth.UserCode))
+
+	interval := time.Duration(deviceAuth.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	deadline := time.Now().Add(time.Duration(deviceAuth.ExpiresIn) * time.Second)
+
+	for time.Now().Before(deadline) {
+		token, err := client.CreateToken(ctx, &ssooidc.CreateTokenInput{
+			ClientId:     register.ClientId,
+			ClientSecret: register.ClientSecret,
+			DeviceCode:   deviceAuth.DeviceCode,
+			GrantType:    aws.String("urn:ietf:params:oauth:grant-type:device_code"),
+		})
+		if err == nil {
+			return cacheSSOToken(startURL, region, token)
+		}
+
+		var pending *ssooidcTypes.AuthorizationPendingException
+		if errors.As(err, &pending) {
+			time.Sleep(interval)
+			continue
+		}
+		return fmt.Errorf("polling for SSO token: %s", err)
+	}
+
+	return fmt.Errorf("timed out waiting for SSO login approval")
+}
+
+// ssoCacheEntry mirrors the JSON shape the AWS CLI and SDK SSO credential
+// providers write/read under ~/.aws/sso/cache.
+type ssoCacheEntry struct {
+	StartURL    string `json:"startUrl"`
+	Region      string `json:"region"`
+	AccessToken string `json:"accessToken"`
+	ExpiresAt   string `json:"expiresAt"`
+}
+
+// cacheSSOToken writes token to ~/.aws/sso/cache/<sha1(startURL)>.json,
+// keyed the same way the AWS CLI keys its cache so tools sharing the same
+// home directory can reuse the session.
+func cacheSSOToken(startURL, region string, token *ssooidc.CreateTokenOutput) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("resolving home directory: %s", err)
+	}
+
+	hash := sha1.Sum([]byte(startURL))
+	cacheFile := filepath.Join(home, ".aws", "sso", "cache", hex.EncodeToString(hash[:])+".json")
+
+	entry := ssoCacheEntry{
+		StartURL:    startURL,
+		Region:      region,
+		AccessToken: aws.ToString(token.AccessToken),
+		ExpiresAt:   time.Now().Add(time.Duration(token.ExpiresIn) * time.Second).UTC().Format("2006-01-02T15:04:05UTC"),
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling SSO token cache: %s", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cacheFile), 0700); err != nil {
+		return fmt.Errorf("creating SSO cache directory: %s", err)
+	}
+	return os.WriteFile(cacheFile, data, 0600)
+}
@@ -0,0 +1,145 @@
+// Package azconcurrency fans an az module's per-subscription work out over
+// a bounded worker pool instead of walking m.Subscriptions one at a time,
+// with a single token-bucket limiter shared by every worker. A token is
+// taken once before each call of a subscription's fn (and before each
+// retry of it), not per ARM request fn makes, so the limiter caps how often
+// subscription enumerations start; the ARM calls inside one enumeration are
+// not rate-limited. --tenant scans with hundreds of subscriptions hit ARM's
+// ~12000/hour per-tenant throttle long before they'd hit a per-subscription
+// one, so the limiter is shared across workers rather than one per
+// goroutine.
+package azconcurrency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"golang.org/x/time/rate"
+)
+
+// DefaultQPS is used when a caller passes qps <= 0 to NewLimiter, matching
+// the ~10 req/s per ARM endpoint this package's callers default to.
+const DefaultQPS = 10
+
+// maxRetries is how many times withRetry will retry a single call after a
+// 429 before giving up and returning the last error it saw.
+const maxRetries = 5
+
+// Limiter is a token-bucket rate limiter shared by every goroutine
+// RunPerSubscription spawns for one module invocation.
+type Limiter struct {
+	limiter *rate.Limiter
+}
+
+// NewLimiter builds a Limiter allowing qps requests/second with a burst of
+// 1, so a burst of concurrent workers still gets smoothed out to qps
+// instead of all firing at once.
+func NewLimiter(qps float64) *Limiter {
+	if qps <= 0 {
+		qps = DefaultQPS
+	}
+	return &Limiter{limiter: rate.NewLimiter(rate.Limit(qps), 1)}
+}
+
+// Wait blocks until the limiter allows another request, or ctx is done.
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	return l.limiter.Wait(ctx)
+}
+
+// RunPerSubscription runs fn once per subscription using up to concurrency
+// goroutines, all sharing limiter. It returns one error per subscription,
+// in the same order as subscriptions (nil for a subscription that
+// succeeded), so a caller can aggregate results into its existing
+// merged-table path exactly as it would for a sequential loop.
+func RunPerSubscription(ctx context.Context, subscriptions []string, concurrency int, limiter *Limiter, fn func(ctx context.Context, subscriptionID string) error) []error {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	errs := make([]error, len(subscriptions))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, subscriptionID := range subscriptions {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, subscriptionID string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = withRetry(ctx, limiter, func() error { return fn(ctx, subscriptionID) })
+		}(i, subscriptionID)
+	}
+	wg.Wait()
+
+	return errs
+}
+
+// withRetry calls fn, retrying with exponential backoff (honoring
+// Retry-After when ARM sends one) whenever fn's error is a 429 from ARM,
+// and giving up after maxRetries.
+func withRetry(ctx context.Context, limiter *Limiter, fn func() error) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		if waitErr := limiter.Wait(ctx); waitErr != nil {
+			return waitErr
+		}
+
+		err = fn()
+		if err == nil {
+			return nil
+		}
+
+		delay, throttled := throttleDelay(err)
+		if !throttled || attempt == maxRetries {
+			if throttled {
+				return fmt.Errorf("giving up after %d retries: %s", maxRetries, err)
+			}
+			return err
+		}
+		if delay <= 0 {
+			delay = backoff(attempt)
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// throttleDelay reports whether err is ARM's 429, and the Retry-After
+// duration it sent, if any.
+func throttleDelay(err error) (time.Duration, bool) {
+	var respErr *azcore.ResponseError
+	if !errors.As(err, &respErr) || respErr.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if respErr.RawResponse != nil {
+		if retryAfter := respErr.RawResponse.Header.Get("Retry-After"); retryAfter != "" {
+			if seconds, parseErr := strconv.Atoi(retryAfter); parseErr == nil {
+				return time.Duration(seconds) * time.Second, true
+			}
+		}
+	}
+	return 0, true
+}
+
+// backoff is an exponential backoff with added jitter: 2^attempt seconds
+// plus a random 0-500ms, capped implicitly by maxRetries rather than a hard
+// ceiling.
+func backoff(attempt int) time.Duration {
+	base := time.Duration(math.Pow(2, float64(attempt))) * time.Second
+	return base + time.Duration(rand.Int63n(int64(500*time.Millisecond)))
+}
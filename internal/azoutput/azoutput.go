@@ -0,0 +1,126 @@
+// Package azoutput gives the az modules a structured, machine-readable
+// output option - internal.OutputClient/internal.TableClient only ever
+// write a table/CSV pair, so there's nowhere for a module to also hand a
+// caller a JSON document per resource (with its findings attached) without
+// every module reinventing that on its own. A module builds one Document
+// per row it already produces and calls Writer.WriteDocument; cli/azure.go
+// is the only place that decides, via --output, whether that's a no-op, a
+// single pretty-printed JSON array, or a streaming JSON-lines file.
+//
+// This mirrors internal/outputsink's Finding/Sink split for the AWS side,
+// but a Document carries the resource's own fields alongside its findings
+// instead of being a standalone notification, since downstream consumers
+// (BloodHound-style graph loaders, Splunk/ELK) want the whole resource, not
+// just the alert.
+package azoutput
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Finding is one notable fact about a Document's resource - an admin role
+// assignment, a publicly reachable network ACL, and so on - surfaced
+// alongside the resource's own fields instead of as a separate table.
+type Finding struct {
+	RuleID   string `json:"ruleId"`
+	Severity string `json:"severity"`
+	Category string `json:"category"`
+	Evidence string `json:"evidence"`
+}
+
+// Document is one resource's structured record: the same fields a module's
+// table row already has, plus whatever Findings apply to it.
+type Document struct {
+	Module       string            `json:"module"`
+	Subscription string            `json:"subscription"`
+	Resource     map[string]string `json:"resource"`
+	Findings     []Finding         `json:"findings"`
+}
+
+// Writer accepts one Document at a time and decides how/when to flush them.
+type Writer interface {
+	WriteDocument(doc Document) error
+	Close() error
+}
+
+// NewWriter returns the Writer format selects, or a nil Writer (with a nil
+// error) for any format that isn't "json" or "jsonl" - table/csv/all keep
+// going through internal.OutputClient exactly as before, so every existing
+// call site only needs an "if writer != nil" guard to adopt this.
+func NewWriter(format, outputDirectory, tenantID, module string) (Writer, error) {
+	switch format {
+	case "json":
+		return &jsonWriter{path: documentPath(outputDirectory, tenantID, module, "json")}, nil
+	case "jsonl":
+		path := documentPath(outputDirectory, tenantID, module, "jsonl")
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			return nil, fmt.Errorf("creating json output directory for %s: %s", module, err)
+		}
+		file, err := os.Create(path)
+		if err != nil {
+			return nil, fmt.Errorf("creating %s: %s", path, err)
+		}
+		return &jsonlWriter{file: file}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func documentPath(outputDirectory, tenantID, module, extension string) string {
+	return filepath.Join(outputDirectory, "cloudfox-output", "azure", tenantID, "json", module+"."+extension)
+}
+
+// jsonWriter buffers every Document and writes them out as a single
+// pretty-printed JSON array on Close, the shape a one-shot "load the whole
+// module's results" consumer expects.
+type jsonWriter struct {
+	path      string
+	documents []Document
+}
+
+func (w *jsonWriter) WriteDocument(doc Document) error {
+	w.documents = append(w.documents, doc)
+	return nil
+}
+
+func (w *jsonWriter) Close() error {
+	if len(w.documents) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(w.path), os.ModePerm); err != nil {
+		return fmt.Errorf("creating json output directory: %s", err)
+	}
+	data, err := json.MarshalIndent(w.documents, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling json output: %s", err)
+	}
+	if err := os.WriteFile(w.path, data, 0644); err != nil {
+		return fmt.Errorf("writing %s: %s", w.path, err)
+	}
+	return nil
+}
+
+// jsonlWriter appends one Document per line as it's written, so a tailing
+// consumer (Splunk/ELK forwarder, a piped "jq" triage script) can start
+// processing before the module finishes enumerating.
+type jsonlWriter struct {
+	file *os.File
+}
+
+func (w *jsonlWriter) WriteDocument(doc Document) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("marshaling json output: %s", err)
+	}
+	if _, err := w.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("writing %s: %s", w.file.Name(), err)
+	}
+	return nil
+}
+
+func (w *jsonlWriter) Close() error {
+	return w.file.Close()
+}
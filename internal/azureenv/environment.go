@@ -0,0 +1,77 @@
+// Package azureenv resolves the "--cloud" flag into the set of endpoint
+// URLs an Azure sovereign/national cloud needs, the az analog of
+// internal.PartitionFromCallerARN for AWS GovCloud/China/ISO.
+package azureenv
+
+import "fmt"
+
+// Environment is the subset of an AzureEnvironment (as az cli and the
+// Azure Go SDKs call it) that this tree's azure package needs to build
+// ARM, Microsoft Graph, Key Vault, and Storage clients against a cloud
+// other than the commercial one.
+type Environment struct {
+	Name                    string
+	ActiveDirectoryEndpoint string
+	ResourceManagerEndpoint string
+	StorageEndpointSuffix   string
+	KeyVaultDNSSuffix       string
+	GraphResourceID         string
+}
+
+// Public is the default environment used when --cloud isn't set, matching
+// azidentity.NewDefaultAzureCredential's own default audience.
+var Public = Environment{
+	Name:                    "AzurePublicCloud",
+	ActiveDirectoryEndpoint: "https://login.microsoftonline.com/",
+	ResourceManagerEndpoint: "https://management.azure.com/",
+	StorageEndpointSuffix:   "core.windows.net",
+	KeyVaultDNSSuffix:       "vault.azure.net",
+	GraphResourceID:         "https://graph.microsoft.com/",
+}
+
+var usGovernment = Environment{
+	Name:                    "AzureUSGovernmentCloud",
+	ActiveDirectoryEndpoint: "https://login.microsoftonline.us/",
+	ResourceManagerEndpoint: "https://management.usgovcloudapi.net/",
+	StorageEndpointSuffix:   "core.usgovcloudapi.net",
+	KeyVaultDNSSuffix:       "vault.usgovcloudapi.net",
+	GraphResourceID:         "https://graph.microsoft.us/",
+}
+
+var china = Environment{
+	Name:                    "AzureChinaCloud",
+	ActiveDirectoryEndpoint: "https://login.chinacloudapi.cn/",
+	ResourceManagerEndpoint: "https://management.chinacloudapi.cn/",
+	StorageEndpointSuffix:   "core.chinacloudapi.cn",
+	KeyVaultDNSSuffix:       "vault.azure.cn",
+	GraphResourceID:         "https://microsoftgraph.chinacloudapi.cn/",
+}
+
+var germany = Environment{
+	Name:                    "AzureGermanCloud",
+	ActiveDirectoryEndpoint: "https://login.microsoftonline.de/",
+	ResourceManagerEndpoint: "https://management.microsoftazure.de/",
+	StorageEndpointSuffix:   "core.cloudapi.de",
+	KeyVaultDNSSuffix:       "vault.microsoftazure.de",
+	GraphResourceID:         "https://graph.cloudapi.de/",
+}
+
+var byName = map[string]Environment{
+	Public.Name:       Public,
+	usGovernment.Name: usGovernment,
+	china.Name:        china,
+	germany.Name:      germany,
+}
+
+// Lookup resolves a --cloud flag value to its Environment. An empty name
+// resolves to Public so callers don't have to special-case the unset flag.
+func Lookup(name string) (Environment, error) {
+	if name == "" {
+		return Public, nil
+	}
+	env, ok := byName[name]
+	if !ok {
+		return Environment{}, fmt.Errorf("unknown --cloud %q: must be one of AzurePublicCloud, AzureUSGovernmentCloud, AzureChinaCloud, AzureGermanCloud", name)
+	}
+	return env, nil
+}
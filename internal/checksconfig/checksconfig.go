@@ -0,0 +1,77 @@
+// Package checksconfig loads the --checks-config file that lets a user skip
+// specific all-checks modules and override a couple of their existing
+// flag-driven filters without retyping them on every invocation.
+//
+// This intentionally stops short of a full reflective module registry: every
+// module in this codebase is a plain exported struct with its own field
+// names (EC2Client, IAMClient, SkipAdminCheck, ...), populated from the SDK
+// clients and CLI flags already in scope at its call site in
+// runAllChecksCommand. Reflectively walking an arbitrary YAML document onto
+// ~40 unrelated struct shapes would mean either a parallel field-name schema
+// per module (as much bookkeeping as the literal construction it replaces)
+// or untyped map[string]interface{} fields threaded through every module's
+// real API - neither fits how this codebase builds a module today. Skipping
+// a module by name and overriding the couple of filters that already exist
+// as their own CLI flags covers the common case without that rewrite.
+package checksconfig
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Config is the shape of a --checks-config file.
+type Config struct {
+	// Skip lists all-checks module names (the same names surfaced in the
+	// scheduler run manifest, e.g. "lambdas", "workloads") to leave out of
+	// the run entirely.
+	Skip []string `yaml:"skip"`
+
+	// Filters overrides an already-existing per-module filter flag, keyed
+	// by module name (e.g. "instances" for the instances command's --filter,
+	// "access-keys" for the access-keys command's --filter). A module with no
+	// entry here keeps whatever the CLI flag was set to; an entry replaces
+	// the flag value even when the flag was given explicitly.
+	Filters map[string]string `yaml:"filters"`
+}
+
+// Load reads and parses path. An empty path returns a zero-value Config
+// (skip nothing, override nothing) rather than an error, so --checks-config
+// can be left unset.
+func Load(path string) (Config, error) {
+	if path == "" {
+		return Config{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("reading --checks-config %s: %s", path, err)
+	}
+
+	var cfg Config
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("parsing --checks-config %s: %s", path, err)
+	}
+
+	return cfg, nil
+}
+
+// Skips reports whether module appears in cfg.Skip.
+func (c Config) Skips(module string) bool {
+	for _, skip := range c.Skip {
+		if skip == module {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns cfg.Filters[module] and whether it was set, so a caller can
+// tell "override to empty string" apart from "not configured, keep the flag
+// value".
+func (c Config) Filter(module string) (string, bool) {
+	value, ok := c.Filters[module]
+	return value, ok
+}
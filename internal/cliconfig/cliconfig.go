@@ -0,0 +1,75 @@
+// Package cliconfig loads shared defaults for AWSCommands' persistent flags
+// from a YAML file (--config, or $HOME/.cloudfox/config.yaml if --config
+// isn't given), so a team can commit one file alongside their pmapper data
+// instead of repeating the same 6-8 flags on every invocation.
+//
+// It deliberately only supplies defaults: cli/aws.go applies a File value to
+// a flag's global var only when that flag wasn't explicitly set on the
+// command line, so the precedence is flag default -> config file -> CLI
+// flag, same as the CLOUDFOX_* env var layer cli/aws.go applies on top of
+// this one. --checks-config filters (internal/checksconfig) differ: they
+// replace the flag value even when the flag was given.
+package cliconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+// File is the shape of a --config file. A pointer field left nil means "not
+// set in the file" as distinct from "set to the zero value" (e.g. an
+// explicit `wrap: false`), so cli/aws.go can tell the two apart before
+// overriding a flag's default.
+type File struct {
+	Output              *string `yaml:"output"`
+	Verbosity           *int    `yaml:"verbosity"`
+	OutDir              *string `yaml:"outdir"`
+	MaxGoroutines       *int    `yaml:"max-goroutines"`
+	SkipAdminCheck      *bool   `yaml:"skip-admin-check"`
+	Wrap                *bool   `yaml:"wrap"`
+	Cached              *bool   `yaml:"cached"`
+	Cols                *string `yaml:"cols"`
+	PmapperDataBasePath *string `yaml:"pmapper-data-basepath"`
+}
+
+// DefaultPath returns $HOME/.cloudfox/config.yaml, the location Load falls
+// back to when --config isn't given.
+func DefaultPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".cloudfox", "config.yaml")
+}
+
+// Load reads and parses path. If path is empty, it falls back to
+// DefaultPath(); if that default doesn't exist, Load returns a zero-value
+// File and no error rather than failing, since most invocations won't have
+// one. An explicitly named --config path that doesn't exist is an error.
+func Load(path string) (File, error) {
+	explicit := path != ""
+	if path == "" {
+		path = DefaultPath()
+		if path == "" {
+			return File{}, nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !explicit {
+			return File{}, nil
+		}
+		return File{}, fmt.Errorf("reading config file %s: %s", path, err)
+	}
+
+	var cfg File
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return File{}, fmt.Errorf("parsing config file %s: %s", path, err)
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,427 @@
+package credbroker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BishopFox/cloudfox/internal/auditlog"
+	"github.com/BishopFox/cloudfox/internal/partition"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sso"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+	ssooidcTypes "github.com/aws/aws-sdk-go-v2/service/ssooidc/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// Target is one account/role pair the broker should mint credentials for.
+type Target struct {
+	AccountID string
+	RoleName  string
+}
+
+// Broker mints short-lived credentials for a batch of accounts, either via
+// AWS SSO (sso:GetRoleCredentials) or by chaining sts:AssumeRole off of a
+// single jumping-off profile, and writes them out as named profiles so a
+// command like `all-checks -l` can fan out across every target without the
+// operator hand-maintaining a profile per account.
+type Broker struct {
+	// SSOStartURL and SSORegion select SSO mode. Leave both empty to use
+	// SourceProfile/AssumeRole mode instead.
+	SSOStartURL string
+	SSORegion   string
+
+	// SourceProfile is the jumping-off profile AssumeRole mode calls
+	// sts:AssumeRole from.
+	SourceProfile string
+
+	Targets         []Target
+	SessionDuration time.Duration
+	MFAToken        string
+
+	// ExternalID is passed as sts:AssumeRole's ExternalId, for org member
+	// roles that require one. Ignored in SSO mode.
+	ExternalID string
+
+	// AuditLogger, when set, records every sso:GetRoleCredentials or
+	// sts:AssumeRole call the broker makes to the engagement's audit log.
+	AuditLogger *auditlog.Logger
+}
+
+// configOptions returns the APIOptions needed to route every call an
+// aws.Config makes through the broker's AuditLogger, if one is set.
+func (b *Broker) configOptions(profile string) []func(*middleware.Stack) error {
+	if b.AuditLogger == nil {
+		return nil
+	}
+	return []func(*middleware.Stack) error{auditlog.Middleware(b.AuditLogger, profile, "", "")}
+}
+
+// ProfileCredential is one minted profile: the name written into the shared
+// credentials file plus the account/role it corresponds to, so a Manifest
+// can be handed straight to -l without the operator re-deriving profile
+// names.
+type ProfileCredential struct {
+	ProfileName     string    `json:"profile_name"`
+	AccountID       string    `json:"account_id"`
+	RoleName        string    `json:"role_name"`
+	AccessKeyID     string    `json:"-"`
+	SecretAccessKey string    `json:"-"`
+	SessionToken    string    `json:"-"`
+	Expiration      time.Time `json:"expiration"`
+}
+
+// Manifest is what `cloudfox aws login` writes alongside the credentials
+// file - the JSON shape initAWSProfiles understands when -l points at a
+// manifest rather than a plain newline-delimited profile list.
+type Manifest struct {
+	GeneratedAt time.Time           `json:"generated_at"`
+	Profiles    []ProfileCredential `json:"profiles"`
+}
+
+// Run mints credentials for every target, using SSO mode if SSOStartURL is
+// set and falling back to AssumeRole mode otherwise. Targets that fail are
+// skipped with a warning rather than aborting the whole batch - with
+// hundreds of accounts in play, a handful of stale role names shouldn't
+// stop the rest from getting minted.
+func (b *Broker) Run(ctx context.Context) (Manifest, error) {
+	if b.SSOStartURL != "" {
+		return b.runSSO(ctx)
+	}
+	return b.runAssumeRole(ctx)
+}
+
+func (b *Broker) runSSO(ctx context.Context) (Manifest, error) {
+	ssoCfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(b.SSORegion), awsconfig.WithAPIOptions(b.configOptions("sso")))
+	if err != nil {
+		return Manifest{}, fmt.Errorf("loading AWS config for SSO region %s: %s", b.SSORegion, err)
+	}
+
+	accessToken, err := getSSOAccessToken(ctx, ssooidc.NewFromConfig(ssoCfg), b.SSOStartURL)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("authenticating with AWS SSO: %s", err)
+	}
+
+	ssoClient := sso.NewFromConfig(ssoCfg)
+	manifest := Manifest{GeneratedAt: time.Now()}
+
+	for _, target := range b.Targets {
+		output, err := ssoClient.GetRoleCredentials(ctx, &sso.GetRoleCredentialsInput{
+			AccessToken: aws.String(accessToken),
+			AccountId:   aws.String(target.AccountID),
+			RoleName:    aws.String(target.RoleName),
+		})
+		if err != nil {
+			fmt.Printf("[-] Skipping %s/%s: %s\n", target.AccountID, target.RoleName, err)
+			continue
+		}
+
+		manifest.Profiles = append(manifest.Profiles, ProfileCredential{
+			ProfileName:     profileName(target),
+			AccountID:       target.AccountID,
+			RoleName:        target.RoleName,
+			AccessKeyID:     aws.ToString(output.RoleCredentials.AccessKeyId),
+			SecretAccessKey: aws.ToString(output.RoleCredentials.SecretAccessKey),
+			SessionToken:    aws.ToString(output.RoleCredentials.SessionToken),
+			Expiration:      time.UnixMilli(output.RoleCredentials.Expiration),
+		})
+	}
+
+	return manifest, nil
+}
+
+func (b *Broker) runAssumeRole(ctx context.Context) (Manifest, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithSharedConfigProfile(b.SourceProfile), awsconfig.WithAPIOptions(b.configOptions(b.SourceProfile)))
+	if err != nil {
+		return Manifest{}, fmt.Errorf("loading source profile %s: %s", b.SourceProfile, err)
+	}
+
+	stsClient := sts.NewFromConfig(cfg)
+	manifest := Manifest{GeneratedAt: time.Now()}
+	partition := callerPartition(ctx, stsClient)
+
+	for _, target := range b.Targets {
+		roleArn := arn.ARN{Partition: partition, Service: "iam", AccountID: target.AccountID, Resource: "role/" + target.RoleName}.String()
+
+		input := &sts.AssumeRoleInput{
+			RoleArn:         aws.String(roleArn),
+			RoleSessionName: aws.String("cloudfox-login"),
+			DurationSeconds: aws.Int32(int32(b.SessionDuration.Seconds())),
+		}
+		if b.MFAToken != "" {
+			input.SerialNumber = aws.String(mfaSerial(ctx, stsClient))
+			input.TokenCode = aws.String(b.MFAToken)
+		}
+		if b.ExternalID != "" {
+			input.ExternalId = aws.String(b.ExternalID)
+		}
+
+		output, err := stsClient.AssumeRole(ctx, input)
+		if err != nil {
+			fmt.Printf("[-] Skipping %s: %s\n", roleArn, err)
+			continue
+		}
+
+		manifest.Profiles = append(manifest.Profiles, ProfileCredential{
+			ProfileName:     profileName(target),
+			AccountID:       target.AccountID,
+			RoleName:        target.RoleName,
+			AccessKeyID:     aws.ToString(output.Credentials.AccessKeyId),
+			SecretAccessKey: aws.ToString(output.Credentials.SecretAccessKey),
+			SessionToken:    aws.ToString(output.Credentials.SessionToken),
+			Expiration:      aws.ToTime(output.Credentials.Expiration),
+		})
+	}
+
+	return manifest, nil
+}
+
+// mfaSerial derives the calling user's virtual MFA device ARN from the
+// IAM convention of naming it after the user itself - good enough for the
+// common case, and the only thing available without an extra
+// iam:ListMFADevices call the caller may not have permission for.
+func mfaSerial(ctx context.Context, stsClient *sts.Client) string {
+	identity, err := stsClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return ""
+	}
+	callerArn := aws.ToString(identity.Arn)
+	userName := callerArn[strings.LastIndex(callerArn, "/")+1:]
+	return arn.ARN{Partition: partition.FromCallerARN(callerArn), Service: "iam", AccountID: aws.ToString(identity.Account), Resource: "mfa/" + userName}.String()
+}
+
+// callerPartition resolves the partition b.SourceProfile's credentials
+// belong to, from the same GetCallerIdentity call AssumeRole needs anyway,
+// so roleArn above targets "aws-us-gov"/"aws-cn"/... correctly instead of
+// assuming the commercial partition.
+func callerPartition(ctx context.Context, stsClient *sts.Client) string {
+	identity, err := stsClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return partition.Default
+	}
+	return partition.FromCallerARN(aws.ToString(identity.Arn))
+}
+
+// getSSOAccessToken runs the SSO OIDC device-authorization flow: register a
+// client, start device authorization, print the verification URL for the
+// operator to approve in a browser, then poll CreateToken until it's
+// approved or the device code expires.
+func getSSOAccessToken(ctx context.Context, client *ssooidc.Client, startURL string) (string, error) {
+	register, err := client.RegisterClient(ctx, &ssooidc.RegisterClientInput{
+		ClientName: aws.String("cloudfox"),
+		ClientType: aws.String("public"),
+	})
+	if err != nil {
+		return "", fmt.Errorf("registering OIDC client: %s", err)
+	}
+
+	deviceAuth, err := client.StartDeviceAuthorization(ctx, &ssooidc.StartDeviceAuthorizationInput{
+		ClientId:     register.ClientId,
+		ClientSecret: register.ClientSecret,
+		StartUrl:     aws.String(startURL),
+	})
+	if err != nil {
+		return "", fmt.Errorf("starting device authorization: %s", err)
+	}
+
+	fmt.Printf("[*] Approve this login at %s (code: %s)\n", aws.ToString(deviceAuth.VerificationUriComplete), aws.ToString(deviceAuth.UserCode))
+
+	interval := time.Duration(deviceAuth.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	deadline := time.Now().Add(time.Duration(deviceAuth.ExpiresIn) * time.Second)
+
+	for time.Now().Before(deadline) {
+		token, err := client.CreateToken(ctx, &ssooidc.CreateTokenInput{
+			ClientId:     register.ClientId,
+			ClientSecret: register.ClientSecret,
+			DeviceCode:   deviceAuth.DeviceCode,
+			GrantType:    aws.String("urn:ietf:params:oauth:grant-type:device_code"),
+		})
+		if err == nil {
+			return aws.ToString(token.AccessToken), nil
+		}
+
+		var pending *ssooidcTypes.AuthorizationPendingException
+		if errors.As(err, &pending) {
+			time.Sleep(interval)
+			continue
+		}
+		return "", fmt.Errorf("polling for SSO token: %s", err)
+	}
+
+	return "", fmt.Errorf("timed out waiting for SSO login approval")
+}
+
+func profileName(target Target) string {
+	return fmt.Sprintf("cloudfox-%s-%s", target.AccountID, target.RoleName)
+}
+
+// ParseTargets accepts either a comma-separated list or the path to a
+// newline-delimited file of "<account-id>:<role-name>" pairs (falling back
+// to defaultRoleName when an entry is a bare account ID) and returns the
+// parsed Target slice.
+func ParseTargets(accounts string, defaultRoleName string) ([]Target, error) {
+	var entries []string
+	if data, err := os.ReadFile(accounts); err == nil {
+		entries = strings.Split(strings.TrimSpace(string(data)), "\n")
+	} else {
+		entries = strings.Split(accounts, ",")
+	}
+
+	var targets []Target
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		accountID, roleName := entry, defaultRoleName
+		if idx := strings.Index(entry, ":"); idx != -1 {
+			accountID, roleName = entry[:idx], entry[idx+1:]
+		}
+		if roleName == "" {
+			return nil, fmt.Errorf("no role name given for account %s (use --role-name or <account>:<role> pairs)", accountID)
+		}
+
+		targets = append(targets, Target{AccountID: accountID, RoleName: roleName})
+	}
+
+	return targets, nil
+}
+
+// WriteCredentialsFile merges each profile's entry into an AWS-CLI-
+// compatible shared credentials file, preserving any existing profiles
+// already in the file - a login refresh shouldn't clobber unrelated
+// profiles the operator manages by hand.
+func WriteCredentialsFile(path string, manifest Manifest) error {
+	profiles, order, err := readCredentialsFile(path)
+	if err != nil {
+		return err
+	}
+
+	for _, profile := range manifest.Profiles {
+		if _, exists := profiles[profile.ProfileName]; !exists {
+			order = append(order, profile.ProfileName)
+		}
+		profiles[profile.ProfileName] = profile
+	}
+
+	var b strings.Builder
+	for _, name := range order {
+		profile := profiles[name]
+		fmt.Fprintf(&b, "[%s]\n", name)
+		fmt.Fprintf(&b, "aws_access_key_id = %s\n", profile.AccessKeyID)
+		fmt.Fprintf(&b, "aws_secret_access_key = %s\n", profile.SecretAccessKey)
+		if profile.SessionToken != "" {
+			fmt.Fprintf(&b, "aws_session_token = %s\n", profile.SessionToken)
+		}
+		b.WriteString("\n")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("creating directory for %s: %s", path, err)
+	}
+	return os.WriteFile(path, []byte(b.String()), 0600)
+}
+
+func readCredentialsFile(path string) (map[string]ProfileCredential, []string, error) {
+	profiles := make(map[string]ProfileCredential)
+	var order []string
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return profiles, order, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading %s: %s", path, err)
+	}
+
+	var current string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "", strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			current = strings.Trim(line, "[]")
+			profiles[current] = ProfileCredential{ProfileName: current}
+			order = append(order, current)
+		case current != "":
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				continue
+			}
+			key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+			profile := profiles[current]
+			switch key {
+			case "aws_access_key_id":
+				profile.AccessKeyID = value
+			case "aws_secret_access_key":
+				profile.SecretAccessKey = value
+			case "aws_session_token":
+				profile.SessionToken = value
+			}
+			profiles[current] = profile
+		}
+	}
+
+	return profiles, order, nil
+}
+
+// WriteManifest writes the JSON manifest initAWSProfiles knows how to read
+// back via LoadManifestProfiles, so -l can point straight at what login
+// just produced instead of the operator hand-writing a profile list.
+func WriteManifest(path string, manifest Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling manifest: %s", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("creating directory for %s: %s", path, err)
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// IsManifest sniffs whether path points at a login-produced JSON manifest
+// rather than a plain newline-delimited profile list, so initAWSProfiles can
+// dispatch to the right parser without needing a separate flag.
+func IsManifest(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var manifest Manifest
+	return json.Unmarshal(data, &manifest) == nil && len(manifest.Profiles) > 0
+}
+
+// LoadManifestProfiles reads a manifest written by WriteManifest and returns
+// just the profile names, ready to drop into AWSProfiles the same way a
+// plain profiles-list file would.
+func LoadManifestProfiles(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest %s: %s", path, err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("parsing manifest %s: %s", path, err)
+	}
+
+	var profiles []string
+	for _, profile := range manifest.Profiles {
+		profiles = append(profiles, profile.ProfileName)
+	}
+	return profiles, nil
+}
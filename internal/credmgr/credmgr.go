@@ -0,0 +1,102 @@
+package credmgr
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/BishopFox/cloudfox/internal/credbroker"
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// RefreshingProvider wraps a profile's credentials so long-running scans
+// (cape, graph) survive past the lifetime of the STS token they started
+// with. It re-invokes Loader whenever the cached credentials are within
+// RefreshWindow of expiring, mirroring the pattern gossamer uses for its
+// account-fanout sessions, and optionally writes the refreshed keys back to
+// a shared credentials file under a fixed profile name so other tooling
+// sharing the same session can pick them up too.
+type RefreshingProvider struct {
+	Profile       string
+	Loader        func(profile string) aws.Config
+	RefreshWindow time.Duration
+
+	WriteBackFile string
+	WriteBackName string
+
+	mu    sync.Mutex
+	creds aws.Credentials
+}
+
+// NewRefreshingProvider returns a RefreshingProvider for profile. loader is
+// called (from scratch) every time the cached credentials are missing or
+// within refreshWindow of expiring - for an SSO or assume-role chained
+// profile this is the same resolution AWSConfigFileLoader already does, so
+// passing internal.AWSConfigFileLoader here is the common case.
+func NewRefreshingProvider(profile string, loader func(profile string) aws.Config, refreshWindow time.Duration) *RefreshingProvider {
+	return &RefreshingProvider{Profile: profile, Loader: loader, RefreshWindow: refreshWindow}
+}
+
+// Retrieve implements aws.CredentialsProvider.
+func (p *RefreshingProvider) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.creds.HasKeys() && !p.needsRefresh() {
+		return p.creds, nil
+	}
+
+	cfg := p.Loader(p.Profile)
+	creds, err := cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return aws.Credentials{}, fmt.Errorf("refreshing credentials for profile %s: %s", p.Profile, err)
+	}
+	p.creds = creds
+
+	if p.WriteBackFile != "" && p.WriteBackName != "" {
+		if err := p.writeBack(creds); err != nil {
+			fmt.Printf("[-] Failed to write back refreshed credentials for %s: %s\n", p.Profile, err)
+		}
+	}
+
+	return creds, nil
+}
+
+func (p *RefreshingProvider) needsRefresh() bool {
+	if p.creds.Expires.IsZero() {
+		return false
+	}
+	return time.Until(p.creds.Expires) <= p.RefreshWindow
+}
+
+func (p *RefreshingProvider) writeBack(creds aws.Credentials) error {
+	manifest := credbroker.Manifest{
+		GeneratedAt: time.Now(),
+		Profiles: []credbroker.ProfileCredential{
+			{
+				ProfileName:     p.WriteBackName,
+				AccessKeyID:     creds.AccessKeyID,
+				SecretAccessKey: creds.SecretAccessKey,
+				SessionToken:    creds.SessionToken,
+				Expiration:      creds.Expires,
+			},
+		},
+	}
+	return credbroker.WriteCredentialsFile(p.WriteBackFile, manifest)
+}
+
+// NewRefreshingConfig builds on loader(profile) but replaces its
+// Credentials with a cached RefreshingProvider, so every client built from
+// the returned config transparently refreshes instead of holding onto a
+// snapshot for the life of a long cape/graph run.
+func NewRefreshingConfig(profile string, loader func(profile string) aws.Config, refreshWindow time.Duration, writeBackFile, writeBackName string) aws.Config {
+	cfg := loader(profile)
+
+	provider := NewRefreshingProvider(profile, loader, refreshWindow)
+	provider.WriteBackFile = writeBackFile
+	provider.WriteBackName = writeBackName
+
+	cfg.Credentials = aws.NewCredentialsCache(provider)
+	return cfg
+}
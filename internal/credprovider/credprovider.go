@@ -0,0 +1,64 @@
+// Package credprovider is a provider-agnostic counterpart to
+// internal/credmgr.RefreshingProvider: the same "cache a value, re-fetch it
+// once it's within a window of expiring" behavior, generalized so something
+// that isn't an aws.Credentials - a GCP oauth2.Token, say - can use it too.
+// internal/gcp.NewGCPClient and internal.AWSConfigFileLoader both end up
+// wanting the same shape of pluggable credential source (Vault secrets, AWS
+// IAM Identity Center, ...), so the Source/Cache split here is what both of
+// them are built against.
+package credprovider
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Source fetches a fresh credential value of type T, together with when it
+// expires. Implementations talk to whatever backend actually mints the
+// credential - Vault, AWS SSO, a local gcloud profile - and Cache never
+// needs to know which.
+type Source[T any] interface {
+	Fetch(ctx context.Context) (value T, expiry time.Time, err error)
+}
+
+// Cache wraps a Source with the same refresh-before-expiry behavior
+// credmgr.RefreshingProvider already gives AWS SDK credentials, generalized
+// to any T. A cached value is reused until it's within RefreshWindow of
+// expiring, at which point Fetch is called again; a zero RefreshWindow only
+// refetches once the value has actually expired.
+type Cache[T any] struct {
+	Source        Source[T]
+	RefreshWindow time.Duration
+
+	mu     sync.Mutex
+	value  T
+	expiry time.Time
+	have   bool
+}
+
+// NewCache returns a Cache that calls source.Fetch whenever the cached
+// value is missing or within refreshWindow of expiring.
+func NewCache[T any](source Source[T], refreshWindow time.Duration) *Cache[T] {
+	return &Cache[T]{Source: source, RefreshWindow: refreshWindow}
+}
+
+// Get returns the cached value, refreshing it first if it's missing or
+// close to expiring.
+func (c *Cache[T]) Get(ctx context.Context) (T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.have && time.Until(c.expiry) > c.RefreshWindow {
+		return c.value, nil
+	}
+
+	value, expiry, err := c.Source.Fetch(ctx)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	c.value, c.expiry, c.have = value, expiry, true
+	return c.value, nil
+}
@@ -0,0 +1,32 @@
+package credprovider
+
+import (
+	"context"
+
+	"golang.org/x/oauth2"
+)
+
+// GCPTokenSource adapts a Cache[string] (a bearer access token, e.g. from
+// VaultGCPSource) to oauth2.TokenSource, the interface internal/gcp.GCPClient
+// needs to authenticate its API clients with. oauth2.TokenSource.Token has
+// no context parameter, so Ctx is used for every Fetch call instead - the
+// same trade every non-contextual TokenSource wrapping a contextual fetch
+// has to make.
+type GCPTokenSource struct {
+	Ctx   context.Context
+	Cache *Cache[string]
+}
+
+// Token implements oauth2.TokenSource.
+func (s GCPTokenSource) Token() (*oauth2.Token, error) {
+	accessToken, err := s.Cache.Get(s.Ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Cache.mu.Lock()
+	expiry := s.Cache.expiry
+	s.Cache.mu.Unlock()
+
+	return &oauth2.Token{AccessToken: accessToken, TokenType: "Bearer", Expiry: expiry}, nil
+}
@@ -0,0 +1,124 @@
+package credprovider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	vaultapi "github.com/hashicorp/vault/api"
+)
+
+// AWSCredentials is the STS-shaped triple VaultAWSSource reads out of
+// Vault's AWS secrets engine, mirroring the fields credbroker.ProfileCredential
+// keeps for a minted role.
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// VaultAWSSource is a Source[AWSCredentials] backed by Vault's AWS secrets
+// engine (vault-plugin-secrets-aws or the built-in aws engine): reading
+// MountPath+"/creds/"+Role mints (or, for a Vault STS role, assumes) a new
+// set of short-lived AWS credentials each time.
+type VaultAWSSource struct {
+	Client    *vaultapi.Client
+	MountPath string
+	Role      string
+}
+
+// Fetch implements Source[AWSCredentials]. The lease attached to the
+// returned secret is what lets the caller (via Cache.RefreshWindow) read
+// again well before Vault itself would revoke the credentials - Vault has
+// no "renew AWS creds in place" operation, a renewed AWS secrets engine
+// lease just extends how long Vault keeps honoring the same STS
+// credentials, so a fresh Fetch is the only way to get a later expiry.
+func (s VaultAWSSource) Fetch(ctx context.Context) (AWSCredentials, time.Time, error) {
+	path := fmt.Sprintf("%s/creds/%s", s.MountPath, s.Role)
+	secret, err := s.Client.Logical().ReadWithContext(ctx, path)
+	if err != nil {
+		return AWSCredentials{}, time.Time{}, fmt.Errorf("reading AWS credentials from vault at %s: %s", path, err)
+	}
+	if secret == nil || secret.Data == nil {
+		return AWSCredentials{}, time.Time{}, fmt.Errorf("vault returned no AWS credentials at %s", path)
+	}
+
+	creds := AWSCredentials{
+		AccessKeyID:     asString(secret.Data["access_key"]),
+		SecretAccessKey: asString(secret.Data["secret_key"]),
+		SessionToken:    asString(secret.Data["security_token"]),
+	}
+	if creds.AccessKeyID == "" {
+		return AWSCredentials{}, time.Time{}, fmt.Errorf("vault secret at %s had no access_key", path)
+	}
+
+	expiry := time.Now().Add(time.Duration(secret.LeaseDuration) * time.Second)
+	return creds, expiry, nil
+}
+
+// VaultGCPSource is a Source[string] (a bearer access token) over Vault's GCP
+// secrets engine: reading MountPath+"/token/"+RoleSet mints a short-lived
+// OAuth access token for the service account the roleset is bound to.
+type VaultGCPSource struct {
+	Client    *vaultapi.Client
+	MountPath string
+	RoleSet   string
+}
+
+// Fetch implements Source[string], returning the bearer access token.
+func (s VaultGCPSource) Fetch(ctx context.Context) (string, time.Time, error) {
+	path := fmt.Sprintf("%s/token/%s", s.MountPath, s.RoleSet)
+	secret, err := s.Client.Logical().ReadWithContext(ctx, path)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("reading GCP token from vault at %s: %s", path, err)
+	}
+	if secret == nil || secret.Data == nil {
+		return "", time.Time{}, fmt.Errorf("vault returned no GCP token at %s", path)
+	}
+
+	token := asString(secret.Data["token"])
+	if token == "" {
+		return "", time.Time{}, fmt.Errorf("vault secret at %s had no token", path)
+	}
+
+	expiry := time.Now().Add(time.Duration(secret.LeaseDuration) * time.Second)
+	if expiresAtSeconds, ok := secret.Data["expires_at_seconds"].(float64); ok {
+		expiry = time.Unix(int64(expiresAtSeconds), 0)
+	}
+	return token, expiry, nil
+}
+
+func asString(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+// AWSCredentialsProvider adapts a Cache[AWSCredentials] to
+// aws.CredentialsProvider, the same boundary credmgr.RefreshingProvider
+// crosses for its own Loader-based provider - this lets
+// internal.AWSConfigFileLoader hand a Vault-backed Cache straight to
+// aws.NewCredentialsCache like it would any other provider.
+type AWSCredentialsProvider struct {
+	Cache *Cache[AWSCredentials]
+}
+
+// Retrieve implements aws.CredentialsProvider.
+func (p AWSCredentialsProvider) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	creds, err := p.Cache.Get(ctx)
+	if err != nil {
+		return aws.Credentials{}, err
+	}
+
+	p.Cache.mu.Lock()
+	expires := p.Cache.expiry
+	p.Cache.mu.Unlock()
+
+	return aws.Credentials{
+		AccessKeyID:     creds.AccessKeyID,
+		SecretAccessKey: creds.SecretAccessKey,
+		SessionToken:    creds.SessionToken,
+		CanExpire:       true,
+		Expires:         expires,
+	}, nil
+}
@@ -82,6 +82,51 @@ func NewGCPClient() *GCPClient {
 	client.init()
 	return client
 }
+
+// NewGCPClientFromTokenSource builds a GCPClient from an explicit
+// oauth2.TokenSource instead of a local gcloud profile - the entry point
+// internal/credprovider's Vault-backed GCPTokenSource plugs into, for
+// running against a service account token Vault hands out instead of
+// whatever's cached under the local gcloud config.
+func NewGCPClientFromTokenSource(ts oauth2.TokenSource) *GCPClient {
+	client := new(GCPClient)
+	client.initFromTokenSource(ts)
+	return client
+}
+
+func (g *GCPClient) initFromTokenSource(ts oauth2.TokenSource) {
+	g.Logger = internal.NewLogger()
+	ctx := context.Background()
+
+	client := oauth2.NewClient(ctx, ts)
+	g.TokenSource = &ts
+
+	oauth2Service, err := goauth2.NewService(ctx, option.WithHTTPClient(client))
+	if err != nil {
+		log.Fatal(err)
+	}
+	tokenInfo, err := oauth2Service.Tokeninfo().Do()
+	if err != nil {
+		log.Fatal(err)
+	}
+	g.TokenInfo = tokenInfo
+	cloudresourcemanagerService, err := cloudresourcemanager.NewService(ctx, option.WithHTTPClient(client))
+	if err != nil {
+		log.Fatal(err)
+	}
+	g.CloudresourcemanagerService = cloudresourcemanagerService
+	cloudassetService, err := cloudasset.NewService(ctx, option.WithHTTPClient(client))
+	if err != nil {
+		log.Fatal(err)
+	}
+	g.CloudAssetService = cloudassetService
+	g.ResourcesService = cloudasset.NewResourcesService(cloudassetService)
+	g.IamPoliciesService = cloudasset.NewIamPoliciesService(cloudassetService)
+	g.OrganizationsService = cloudresourcemanager.NewOrganizationsService(cloudresourcemanagerService)
+	g.FoldersService = cloudresourcemanager.NewFoldersService(cloudresourcemanagerService)
+	g.ProjectsService = cloudresourcemanager.NewProjectsService(cloudresourcemanagerService)
+}
+
 /*
 	Get all usable GCP Profiles
 	We are using only non expired user-tokens
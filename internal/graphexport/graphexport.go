@@ -0,0 +1,305 @@
+// Package graphexport writes the cape command's merged node/edge graph out
+// to formats other tools already know how to query - GraphML/GEXF for
+// Gephi/yEd, a Cypher statement stream for Neo4j, and a JSON node/edge dump
+// compatible with awspx - so users can run graph queries (shortest path from
+// a vendor account to an admin role, cross-account cycles) that cloudfox
+// itself doesn't ship.
+package graphexport
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Node is one vertex of the graph, keyed by ARN the way cape's GlobalGraph
+// already is. Attributes holds whatever vertex properties the graph carries
+// (Type, Name, VendorName, IsAdminString, CanPrivEscToAdminString,
+// AccountID, ...).
+type Node struct {
+	ID         string
+	Attributes map[string]string
+}
+
+// Edge is one directed edge of the graph, keyed by the same ARNs as Node.ID.
+type Edge struct {
+	Source     string
+	Target     string
+	Attributes map[string]string
+}
+
+// sortedKeys returns m's keys in a stable order so repeated exports of the
+// same graph produce byte-identical output (diffable between runs).
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedSetKeys is sortedKeys for a set represented as map[string]bool.
+func sortedSetKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type graphmlKey struct {
+	XMLName  xml.Name `xml:"key"`
+	ID       string   `xml:"id,attr"`
+	For      string   `xml:"for,attr"`
+	AttrName string   `xml:"attr.name,attr"`
+	AttrType string   `xml:"attr.type,attr"`
+}
+
+type graphmlData struct {
+	XMLName xml.Name `xml:"data"`
+	Key     string   `xml:"key,attr"`
+	Value   string   `xml:",chardata"`
+}
+
+type graphmlNode struct {
+	XMLName xml.Name      `xml:"node"`
+	ID      string        `xml:"id,attr"`
+	Data    []graphmlData `xml:"data"`
+}
+
+type graphmlEdge struct {
+	XMLName xml.Name      `xml:"edge"`
+	Source  string        `xml:"source,attr"`
+	Target  string        `xml:"target,attr"`
+	Data    []graphmlData `xml:"data"`
+}
+
+type graphmlGraph struct {
+	XMLName xml.Name      `xml:"graph"`
+	EdgeDef string        `xml:"edgedefault,attr"`
+	Nodes   []graphmlNode `xml:"node"`
+	Edges   []graphmlEdge `xml:"edge"`
+}
+
+type graphmlDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Keys    []graphmlKey `xml:"key"`
+	Graph   graphmlGraph `xml:"graph"`
+}
+
+// WriteGraphML writes nodes/edges as a GraphML document readable by Gephi
+// and yEd. Every distinct attribute name used across all nodes/edges gets
+// its own <key>, declared up front the way GraphML requires.
+func WriteGraphML(w io.Writer, nodes []Node, edges []Edge) error {
+	nodeKeys := map[string]bool{}
+	edgeKeys := map[string]bool{}
+	for _, n := range nodes {
+		for k := range n.Attributes {
+			nodeKeys[k] = true
+		}
+	}
+	for _, e := range edges {
+		for k := range e.Attributes {
+			edgeKeys[k] = true
+		}
+	}
+
+	doc := graphmlDocument{
+		Graph: graphmlGraph{EdgeDef: "directed"},
+	}
+	for _, k := range sortedSetKeys(nodeKeys) {
+		doc.Keys = append(doc.Keys, graphmlKey{ID: "n_" + k, For: "node", AttrName: k, AttrType: "string"})
+	}
+	for _, k := range sortedSetKeys(edgeKeys) {
+		doc.Keys = append(doc.Keys, graphmlKey{ID: "e_" + k, For: "edge", AttrName: k, AttrType: "string"})
+	}
+
+	for _, n := range nodes {
+		node := graphmlNode{ID: n.ID}
+		for _, k := range sortedKeys(n.Attributes) {
+			node.Data = append(node.Data, graphmlData{Key: "n_" + k, Value: n.Attributes[k]})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+	}
+	for _, e := range edges {
+		edge := graphmlEdge{Source: e.Source, Target: e.Target}
+		for _, k := range sortedKeys(e.Attributes) {
+			edge.Data = append(edge.Data, graphmlData{Key: "e_" + k, Value: e.Attributes[k]})
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, edge)
+	}
+
+	if _, err := w.Write([]byte(xml.Header)); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("encoding graphml: %s", err)
+	}
+	return nil
+}
+
+type gexfAttribute struct {
+	XMLName xml.Name `xml:"attribute"`
+	ID      string   `xml:"id,attr"`
+	Title   string   `xml:"title,attr"`
+	Type    string   `xml:"type,attr"`
+}
+
+type gexfAttvalue struct {
+	XMLName xml.Name `xml:"attvalue"`
+	For     string   `xml:"for,attr"`
+	Value   string   `xml:"value,attr"`
+}
+
+type gexfNode struct {
+	XMLName   xml.Name       `xml:"node"`
+	ID        string         `xml:"id,attr"`
+	Label     string         `xml:"label,attr"`
+	Attvalues []gexfAttvalue `xml:"attvalues>attvalue"`
+}
+
+type gexfEdge struct {
+	XMLName   xml.Name       `xml:"edge"`
+	ID        string         `xml:"id,attr"`
+	Source    string         `xml:"source,attr"`
+	Target    string         `xml:"target,attr"`
+	Attvalues []gexfAttvalue `xml:"attvalues>attvalue"`
+}
+
+type gexfDocument struct {
+	XMLName xml.Name `xml:"gexf"`
+	Version string   `xml:"version,attr"`
+	Graph   struct {
+		DefaultEdgeType string          `xml:"defaultedgetype,attr"`
+		NodeAttrs       []gexfAttribute `xml:"attributes>attribute"`
+		Nodes           []gexfNode      `xml:"nodes>node"`
+		Edges           []gexfEdge      `xml:"edges>edge"`
+	} `xml:"graph"`
+}
+
+// WriteGEXF writes nodes/edges as a GEXF document, Gephi's native format.
+func WriteGEXF(w io.Writer, nodes []Node, edges []Edge) error {
+	attrKeys := map[string]bool{}
+	for _, n := range nodes {
+		for k := range n.Attributes {
+			attrKeys[k] = true
+		}
+	}
+
+	doc := gexfDocument{Version: "1.3"}
+	doc.Graph.DefaultEdgeType = "directed"
+	for i, k := range sortedSetKeys(attrKeys) {
+		doc.Graph.NodeAttrs = append(doc.Graph.NodeAttrs, gexfAttribute{ID: fmt.Sprintf("%d", i), Title: k, Type: "string"})
+	}
+	attrIndex := map[string]string{}
+	for _, a := range doc.Graph.NodeAttrs {
+		attrIndex[a.Title] = a.ID
+	}
+
+	for _, n := range nodes {
+		node := gexfNode{ID: n.ID, Label: n.Attributes["Name"]}
+		for _, k := range sortedKeys(n.Attributes) {
+			node.Attvalues = append(node.Attvalues, gexfAttvalue{For: attrIndex[k], Value: n.Attributes[k]})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+	}
+	for i, e := range edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, gexfEdge{ID: fmt.Sprintf("%d", i), Source: e.Source, Target: e.Target})
+	}
+
+	if _, err := w.Write([]byte(xml.Header)); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("encoding gexf: %s", err)
+	}
+	return nil
+}
+
+// awspxDocument is the node/edge shape awspx's importer expects.
+type awspxDocument struct {
+	Nodes []awspxNode `json:"nodes"`
+	Edges []awspxEdge `json:"edges"`
+}
+
+type awspxNode struct {
+	ID         string            `json:"id"`
+	Properties map[string]string `json:"properties"`
+}
+
+type awspxEdge struct {
+	Source     string            `json:"source"`
+	Target     string            `json:"target"`
+	Properties map[string]string `json:"properties"`
+}
+
+// WriteJSON writes nodes/edges as an awspx-compatible node/edge dump.
+func WriteJSON(w io.Writer, nodes []Node, edges []Edge) error {
+	doc := awspxDocument{}
+	for _, n := range nodes {
+		doc.Nodes = append(doc.Nodes, awspxNode{ID: n.ID, Properties: n.Attributes})
+	}
+	for _, e := range edges {
+		doc.Edges = append(doc.Edges, awspxEdge{Source: e.Source, Target: e.Target, Properties: e.Attributes})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("encoding json: %s", err)
+	}
+	return nil
+}
+
+// cypherLabel picks a Neo4j node label from a node's Type attribute,
+// defaulting to a generic label so every node still imports even without one.
+func cypherLabel(n Node) string {
+	if t := n.Attributes["Type"]; t != "" {
+		return strings.ReplaceAll(t, " ", "")
+	}
+	return "Principal"
+}
+
+func cypherEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `'`, `\'`)
+	return s
+}
+
+// WriteCypher writes a stream of MERGE statements that load nodes/edges into
+// Neo4j via cypher-shell (`cypher-shell < graph.cypher`) or any tool that
+// accepts a plain Cypher script. cloudfox doesn't vendor a bolt driver, so
+// this is the supported path to Neo4j rather than a live push.
+func WriteCypher(w io.Writer, nodes []Node, edges []Edge) error {
+	for _, n := range nodes {
+		props := make([]string, 0, len(n.Attributes))
+		for _, k := range sortedKeys(n.Attributes) {
+			props = append(props, fmt.Sprintf("%s: '%s'", k, cypherEscape(n.Attributes[k])))
+		}
+		if _, err := fmt.Fprintf(w, "MERGE (n:%s {Arn: '%s'}) SET n += {%s};\n",
+			cypherLabel(n), cypherEscape(n.ID), strings.Join(props, ", ")); err != nil {
+			return err
+		}
+	}
+
+	for _, e := range edges {
+		props := make([]string, 0, len(e.Attributes))
+		for _, k := range sortedKeys(e.Attributes) {
+			props = append(props, fmt.Sprintf("%s: '%s'", k, cypherEscape(e.Attributes[k])))
+		}
+		if _, err := fmt.Fprintf(w, "MATCH (a {Arn: '%s'}), (b {Arn: '%s'}) MERGE (a)-[r:RELATES_TO]->(b) SET r += {%s};\n",
+			cypherEscape(e.Source), cypherEscape(e.Target), strings.Join(props, ", ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
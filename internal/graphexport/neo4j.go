@@ -0,0 +1,122 @@
+package graphexport
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// cypherRelType picks a Neo4j relationship type from an edge's Type
+// attribute (e.g. "CanAssume", "CanPrivEscTo", "Trusts"), normalizing it to
+// the SCREAMING_SNAKE_CASE Neo4j convention. Edges with no Type attribute
+// still import under a generic relationship rather than being dropped.
+func cypherRelType(e Edge) string {
+	t := e.Attributes["Type"]
+	if t == "" {
+		return "RELATES_TO"
+	}
+	replacer := strings.NewReplacer(" ", "_", "-", "_")
+	return strings.ToUpper(replacer.Replace(t))
+}
+
+// WriteNeo4jCypher writes a CREATE-based Cypher script for a one-time bulk
+// load of the cape graph into an empty Neo4j database - unlike WriteCypher's
+// MERGE statements, this assumes the target graph is empty and doesn't pay
+// MERGE's per-row lookup cost, at the expense of not being re-runnable
+// without wiping the database first.
+func WriteNeo4jCypher(w io.Writer, nodes []Node, edges []Edge) error {
+	for _, n := range nodes {
+		props := make([]string, 0, len(n.Attributes)+1)
+		props = append(props, fmt.Sprintf("arn: '%s'", cypherEscape(n.ID)))
+		for _, k := range sortedKeys(n.Attributes) {
+			props = append(props, fmt.Sprintf("%s: '%s'", k, cypherEscape(n.Attributes[k])))
+		}
+		if _, err := fmt.Fprintf(w, "CREATE (:%s {%s});\n", cypherLabel(n), strings.Join(props, ", ")); err != nil {
+			return err
+		}
+	}
+
+	for _, e := range edges {
+		props := make([]string, 0, len(e.Attributes))
+		for _, k := range sortedKeys(e.Attributes) {
+			props = append(props, fmt.Sprintf("%s: '%s'", k, cypherEscape(e.Attributes[k])))
+		}
+		if _, err := fmt.Fprintf(w, "MATCH (a {arn: '%s'}), (b {arn: '%s'}) CREATE (a)-[:%s {%s}]->(b);\n",
+			cypherEscape(e.Source), cypherEscape(e.Target), cypherRelType(e), strings.Join(props, ", ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nodeAttributeKeys and edgeAttributeKeys collect every distinct attribute
+// name used across nodes/edges, sorted, so the CSV files below get a stable
+// set of columns even though not every node/edge sets every attribute.
+func nodeAttributeKeys(nodes []Node) []string {
+	keys := map[string]bool{}
+	for _, n := range nodes {
+		for k := range n.Attributes {
+			keys[k] = true
+		}
+	}
+	return sortedSetKeys(keys)
+}
+
+func edgeAttributeKeys(edges []Edge) []string {
+	keys := map[string]bool{}
+	for _, e := range edges {
+		for k := range e.Attributes {
+			keys[k] = true
+		}
+	}
+	return sortedSetKeys(keys)
+}
+
+// WriteNodesCSV and WriteEdgesCSV write a neo4j-admin database import
+// compatible CSV pair: header columns use neo4j-admin's :ID/:LABEL/:TYPE
+// column-name convention, so `neo4j-admin database import full --nodes
+// nodes.csv --relationships edges.csv` loads them directly.
+func WriteNodesCSV(w io.Writer, nodes []Node) error {
+	keys := nodeAttributeKeys(nodes)
+	cw := csv.NewWriter(w)
+
+	header := append([]string{"arn:ID", ":LABEL"}, keys...)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, n := range nodes {
+		row := make([]string, 0, len(header))
+		row = append(row, n.ID, cypherLabel(n))
+		for _, k := range keys {
+			row = append(row, n.Attributes[k])
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func WriteEdgesCSV(w io.Writer, edges []Edge) error {
+	keys := edgeAttributeKeys(edges)
+	cw := csv.NewWriter(w)
+
+	header := append([]string{":START_ID", ":END_ID", ":TYPE"}, keys...)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, e := range edges {
+		row := make([]string, 0, len(header))
+		row = append(row, e.Source, e.Target, cypherRelType(e))
+		for _, k := range keys {
+			row = append(row, e.Attributes[k])
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
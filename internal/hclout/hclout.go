@@ -0,0 +1,157 @@
+// Package hclout turns cloudfox-discovered AWS resources into a Terraform
+// (HCL) `import` block plus a stub resource declaration for each one, so a
+// red/blue team can round-trip an enumerated environment into a Terraform
+// workspace for further analysis or reproduction. No module calls Write,
+// and no --output value selects it: Inventory2Module and
+// ResourceTrustsModule do not produce Terraform output.
+package hclout
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Resource kinds understood by TerraformType. These match the resource
+// families Inventory2Module already enumerates.
+const (
+	KindS3Bucket = "s3-bucket"
+	KindLambda   = "lambda-function"
+	KindEC2      = "ec2-instance"
+	KindRDS      = "rds-instance"
+	KindIAMRole  = "iam-role"
+	KindSNSTopic = "sns-topic"
+	KindSQSQueue = "sqs-queue"
+	KindSecret   = "secretsmanager-secret"
+)
+
+// TerraformType maps a Kind to the Terraform resource type that should
+// import it. A Kind with no entry here is skipped rather than guessed at.
+var TerraformType = map[string]string{
+	KindS3Bucket: "aws_s3_bucket",
+	KindLambda:   "aws_lambda_function",
+	KindEC2:      "aws_instance",
+	KindRDS:      "aws_db_instance",
+	KindIAMRole:  "aws_iam_role",
+	KindSNSTopic: "aws_sns_topic",
+	KindSQSQueue: "aws_sqs_queue",
+	KindSecret:   "aws_secretsmanager_secret",
+}
+
+// Resource is one discovered resource to round-trip into Terraform. ARN is
+// both the import ID and what resources are keyed/deduplicated by. Name is
+// used only to build a readable local resource name and may be empty.
+type Resource struct {
+	ARN  string
+	Kind string
+	Name string
+}
+
+// Write generates import.tf (one Terraform 1.5+ `import` block per
+// resource) and stubs.tf (an empty resource declaration per resource, so
+// `terraform plan` has something to reconcile each import against) under
+// outputDirectory. Resources whose Kind has no entry in TerraformType are
+// skipped and reported, not silently dropped. Does nothing if resources is
+// empty.
+func Write(outputDirectory string, resources []Resource) error {
+	if len(resources) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(outputDirectory, 0755); err != nil {
+		return fmt.Errorf("creating %s: %s", outputDirectory, err)
+	}
+
+	deduped := dedupeByARN(resources)
+	sort.Slice(deduped, func(i, j int) bool { return deduped[i].ARN < deduped[j].ARN })
+
+	importPath := filepath.Join(outputDirectory, "import.tf")
+	importFile, err := os.Create(importPath)
+	if err != nil {
+		return fmt.Errorf("creating %s: %s", importPath, err)
+	}
+	defer importFile.Close()
+
+	stubsPath := filepath.Join(outputDirectory, "stubs.tf")
+	stubsFile, err := os.Create(stubsPath)
+	if err != nil {
+		return fmt.Errorf("creating %s: %s", stubsPath, err)
+	}
+	defer stubsFile.Close()
+
+	seen := make(map[string]int)
+	var skipped []string
+	for _, r := range deduped {
+		tfType, ok := TerraformType[r.Kind]
+		if !ok {
+			skipped = append(skipped, fmt.Sprintf("%s (%s)", r.ARN, r.Kind))
+			continue
+		}
+		name := localName(tfType, r, seen)
+
+		fmt.Fprintf(importFile, "import {\n  to = %s.%s\n  id = %q\n}\n\n", tfType, name, r.ARN)
+		fmt.Fprintf(stubsFile, "# %s\nresource %q %q {}\n\n", r.ARN, tfType, name)
+	}
+
+	if len(skipped) > 0 {
+		fmt.Printf("[hclout] %d resource(s) have no Terraform mapping yet, skipped: %s\n", len(skipped), strings.Join(skipped, ", "))
+	}
+	fmt.Printf("[hclout] Terraform import blocks written to %s (stubs: %s)\n", importPath, stubsPath)
+	return nil
+}
+
+// dedupeByARN keeps the first Resource seen for each ARN, so a resource
+// discovered by more than one check (e.g. both Inventory2 and
+// ResourceTrusts) only gets one import block.
+func dedupeByARN(resources []Resource) []Resource {
+	seen := make(map[string]bool, len(resources))
+	deduped := make([]Resource, 0, len(resources))
+	for _, r := range resources {
+		if seen[r.ARN] {
+			continue
+		}
+		seen[r.ARN] = true
+		deduped = append(deduped, r)
+	}
+	return deduped
+}
+
+// localName builds a stable, readable, unique local resource name for r,
+// disambiguating collisions (e.g. two resources of the same type whose
+// Name sanitizes to the same identifier) with a numeric suffix.
+func localName(tfType string, r Resource, seen map[string]int) string {
+	base := sanitize(r.Name)
+	if base == "" {
+		base = sanitize(r.ARN)
+	}
+	key := tfType + "." + base
+	seen[key]++
+	if seen[key] == 1 {
+		return base
+	}
+	return fmt.Sprintf("%s_%d", base, seen[key])
+}
+
+// sanitize turns an arbitrary resource name or ARN into a valid Terraform
+// identifier: letters, digits and underscores only, not starting with a
+// digit.
+func sanitize(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	out := strings.Trim(b.String(), "_")
+	if out == "" {
+		return "resource"
+	}
+	if out[0] >= '0' && out[0] <= '9' {
+		out = "r_" + out
+	}
+	return strings.ToLower(out)
+}
@@ -0,0 +1,38 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TxtLog is the package-wide structured logger. Every module's modLog field
+// is derived from it with WithFields, and run*Command routes per-profile
+// errors through it instead of fmt.Printf, so a single --log-level/
+// --log-format pair governs every message cloudfox emits regardless of
+// which module logged it. awsPreRun configures level and format from the
+// --log-level/--log-format flags; until then it defaults to info/text so
+// code that never goes through PreRun (e.g. a unit test) still logs
+// something sane.
+var TxtLog = logrus.New()
+
+func init() {
+	TxtLog.SetOutput(os.Stderr)
+	TxtLog.SetFormatter(&logrus.TextFormatter{})
+}
+
+// SetLogFormat switches TxtLog between human-readable text and
+// newline-delimited JSON, the latter so --log-level debug output can be fed
+// to a log aggregator instead of scrolled past in a terminal.
+func SetLogFormat(format string) error {
+	switch format {
+	case "", "text":
+		TxtLog.SetFormatter(&logrus.TextFormatter{})
+	case "json":
+		TxtLog.SetFormatter(&logrus.JSONFormatter{})
+	default:
+		return fmt.Errorf("invalid --log-format %q, must be \"text\" or \"json\"", format)
+	}
+	return nil
+}
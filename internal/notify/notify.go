@@ -0,0 +1,194 @@
+// Package notify lets a long-running command (all-checks, cape, inventory)
+// publish a single scan-completion or scan-error event to an operator's own
+// notification channel, so they can kick off a multi-account scan and get
+// pinged instead of tailing logs. It's deliberately separate from
+// internal/outputsink, which forwards individual per-module findings rather
+// than a run's overall outcome.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+)
+
+// Event describes one command's outcome for one profile.
+type Event struct {
+	Profile    string
+	Module     string
+	Duration   time.Duration
+	OutputPath string
+	Err        error
+}
+
+// payload is the JSON shape published to SNS/webhook notifiers.
+type payload struct {
+	Profile    string `json:"profile"`
+	Module     string `json:"module"`
+	DurationMs int64  `json:"duration_ms"`
+	OutputPath string `json:"output_path"`
+	Error      string `json:"error,omitempty"`
+}
+
+func newPayload(event Event) payload {
+	p := payload{
+		Profile:    event.Profile,
+		Module:     event.Module,
+		DurationMs: event.Duration.Milliseconds(),
+		OutputPath: event.OutputPath,
+	}
+	if event.Err != nil {
+		p.Error = event.Err.Error()
+	}
+	return p
+}
+
+func (e Event) subject() string {
+	if e.Err != nil {
+		return fmt.Sprintf("cloudfox: %s failed for %s", e.Module, e.Profile)
+	}
+	return fmt.Sprintf("cloudfox: %s finished for %s", e.Module, e.Profile)
+}
+
+// Notifier is a destination an Event can be published to.
+type Notifier interface {
+	Notify(event Event) error
+}
+
+// NoopNotifier is the default when neither --notify-sns-topic nor
+// --notify-webhook-url is set, so a run with no notification flags behaves
+// exactly like it always did.
+type NoopNotifier struct{}
+
+func (NoopNotifier) Notify(Event) error { return nil }
+
+// MultiNotifier fans an Event out to every notifier it wraps, collecting
+// (rather than stopping on) the first error so one bad webhook doesn't
+// swallow a notification the SNS topic would have delivered.
+type MultiNotifier []Notifier
+
+func (m MultiNotifier) Notify(event Event) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("notifying %d destination(s): %v", len(errs), errs)
+	}
+	return nil
+}
+
+// SNSNotifier publishes each Event as a JSON message to a topic.
+type SNSNotifier struct {
+	Client   *sns.Client
+	TopicArn string
+}
+
+func (s SNSNotifier) Notify(event Event) error {
+	data, err := json.Marshal(newPayload(event))
+	if err != nil {
+		return fmt.Errorf("marshalling notification: %s", err)
+	}
+
+	_, err = s.Client.Publish(context.Background(), &sns.PublishInput{
+		TopicArn: aws.String(s.TopicArn),
+		Subject:  aws.String(event.subject()),
+		Message:  aws.String(string(data)),
+	})
+	if err != nil {
+		return fmt.Errorf("publishing notification to %s: %s", s.TopicArn, err)
+	}
+	return nil
+}
+
+// WebhookNotifier POSTs each Event as JSON to a generic HTTPS endpoint. The
+// payload carries a "text" field so Slack/Teams incoming webhooks render a
+// readable message out of the box without a translation layer.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+func (w WebhookNotifier) Notify(event Event) error {
+	p := newPayload(event)
+	body, err := json.Marshal(struct {
+		payload
+		Text string `json:"text"`
+	}{
+		payload: p,
+		Text:    fmt.Sprintf("%s (profile=%s, duration=%s, output=%s)", event.subject(), p.Profile, event.Duration, p.OutputPath),
+	})
+	if err != nil {
+		return fmt.Errorf("marshalling notification: %s", err)
+	}
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Post(w.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("posting notification to webhook: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %s", resp.Status)
+	}
+	return nil
+}
+
+// New builds a Notifier from the --notify-sns-topic/--notify-webhook-url
+// flags. cfg is used to construct the SNS client if snsTopicArn is set.
+// Returns NoopNotifier{} if both are empty.
+func New(snsTopicArn string, webhookURL string, cfg aws.Config) Notifier {
+	var notifiers MultiNotifier
+	if snsTopicArn != "" {
+		notifiers = append(notifiers, SNSNotifier{Client: sns.NewFromConfig(cfg), TopicArn: snsTopicArn})
+	}
+	if webhookURL != "" {
+		notifiers = append(notifiers, WebhookNotifier{URL: webhookURL})
+	}
+	if len(notifiers) == 0 {
+		return NoopNotifier{}
+	}
+	return notifiers
+}
+
+// ParseTargets builds a Notifier from one or more --notify destinations,
+// each scheme-prefixed the way cloudfox's --sink flag already works:
+// "sns://arn:aws:sns:region:acct:topic", "slack://hooks.slack.com/...", or a
+// bare "https://..."/"http://..." webhook URL. cfg is used to construct the
+// SNS client for any sns:// targets. Returns NoopNotifier{} if targets is
+// empty, and combines multiple targets (or a mix of these and the older
+// --notify-sns-topic/--notify-webhook-url flags, via New+MultiNotifier) the
+// same way New does.
+func ParseTargets(targets []string, cfg aws.Config) (Notifier, error) {
+	var notifiers MultiNotifier
+	for _, target := range targets {
+		switch {
+		case strings.HasPrefix(target, "sns://"):
+			notifiers = append(notifiers, SNSNotifier{Client: sns.NewFromConfig(cfg), TopicArn: strings.TrimPrefix(target, "sns://")})
+		case strings.HasPrefix(target, "slack://"):
+			notifiers = append(notifiers, WebhookNotifier{URL: "https://" + strings.TrimPrefix(target, "slack://")})
+		case strings.HasPrefix(target, "https://"), strings.HasPrefix(target, "http://"):
+			notifiers = append(notifiers, WebhookNotifier{URL: target})
+		default:
+			return nil, fmt.Errorf("invalid --notify target %q: must start with \"sns://\", \"slack://\", \"https://\", or \"http://\"", target)
+		}
+	}
+	if len(notifiers) == 0 {
+		return NoopNotifier{}, nil
+	}
+	return notifiers, nil
+}
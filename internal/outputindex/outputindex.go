@@ -0,0 +1,258 @@
+// Package outputindex builds and maintains a machine-readable index of the
+// module CSVs written under a run's cloudfox-output directory, so tools like
+// "cloudfox tui" can discover what's there without re-walking the filesystem
+// on every start.
+//
+// internal.OutputClient - the type the ~40 existing PrintXxx methods build
+// their tables through - isn't defined anywhere in this checkout, so it
+// can't be the thing that's factored to emit this index. Instead,
+// internal/outputs.FileSink (the one writer in this tree that both exists
+// and actually lands rows on disk today) calls Upsert after every
+// WriteTable. Output written the old way, directly by a PrintXxx method that
+// builds its own internal.OutputClient/TableClient, won't appear in
+// index.json. Load walks the tree with Scan only when index.json does not
+// exist yet; once it exists, Load reads only that file, so such output
+// written afterwards is not listed.
+package outputindex
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ModuleEntry is one module's CSV under an account/project/subscription
+// directory.
+type ModuleEntry struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Rows int    `json:"rows"`
+}
+
+// AccountEntry is one provider run: an AWS profile-account, a GCP project, or
+// an Azure tenant-subscription, identified the same way its directory name
+// already is.
+type AccountEntry struct {
+	Provider   string        `json:"provider"`
+	Identifier string        `json:"identifier"`
+	Dir        string        `json:"dir"`
+	Modules    []ModuleEntry `json:"modules"`
+}
+
+// Index is the full index.json contents: every account/project/subscription
+// run found under cloudfox-output, across every provider.
+type Index struct {
+	Accounts []AccountEntry `json:"accounts"`
+}
+
+// Providers returns the distinct provider directory names present in the
+// index (e.g. "aws", "gcp", "azure"), sorted for stable menu ordering.
+func (idx *Index) Providers() []string {
+	seen := map[string]bool{}
+	var providers []string
+	for _, a := range idx.Accounts {
+		if !seen[a.Provider] {
+			seen[a.Provider] = true
+			providers = append(providers, a.Provider)
+		}
+	}
+	sort.Strings(providers)
+	return providers
+}
+
+// AccountsForProvider returns every account/project/subscription run for the
+// given provider, sorted by identifier.
+func (idx *Index) AccountsForProvider(provider string) []AccountEntry {
+	var accounts []AccountEntry
+	for _, a := range idx.Accounts {
+		if a.Provider == provider {
+			accounts = append(accounts, a)
+		}
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].Identifier < accounts[j].Identifier })
+	return accounts
+}
+
+func indexPath(outputDirectory string) string {
+	return filepath.Join(outputDirectory, "cloudfox-output", "index.json")
+}
+
+// Load reads cloudfox-output/index.json if present; otherwise it falls back
+// to Scan and writes the result so the next Load is a plain file read.
+func Load(outputDirectory string) (*Index, error) {
+	data, err := os.ReadFile(indexPath(outputDirectory))
+	if err == nil {
+		var idx Index
+		if err := json.Unmarshal(data, &idx); err != nil {
+			return nil, fmt.Errorf("parsing %s: %s", indexPath(outputDirectory), err)
+		}
+		return &idx, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading %s: %s", indexPath(outputDirectory), err)
+	}
+
+	idx, err := Scan(outputDirectory)
+	if err != nil {
+		return nil, err
+	}
+	if err := Write(outputDirectory, idx); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// Scan walks cloudfox-output/<provider>/<identifier>/*.csv directly, for
+// output that was never written through Upsert.
+func Scan(outputDirectory string) (*Index, error) {
+	root := filepath.Join(outputDirectory, "cloudfox-output")
+	idx := &Index{}
+
+	providerDirs, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return idx, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %s", root, err)
+	}
+
+	for _, providerDir := range providerDirs {
+		if !providerDir.IsDir() {
+			continue
+		}
+		provider := providerDir.Name()
+
+		accountDirs, err := os.ReadDir(filepath.Join(root, provider))
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %s", filepath.Join(root, provider), err)
+		}
+		for _, accountDir := range accountDirs {
+			if !accountDir.IsDir() {
+				continue
+			}
+
+			dir := filepath.Join(root, provider, accountDir.Name())
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				return nil, fmt.Errorf("reading %s: %s", dir, err)
+			}
+
+			account := AccountEntry{Provider: provider, Identifier: accountDir.Name(), Dir: dir}
+			for _, entry := range entries {
+				if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".csv") {
+					continue
+				}
+				path := filepath.Join(dir, entry.Name())
+				rows, err := countCSVRows(path)
+				if err != nil {
+					return nil, err
+				}
+				account.Modules = append(account.Modules, ModuleEntry{
+					Name: strings.TrimSuffix(entry.Name(), ".csv"),
+					Path: path,
+					Rows: rows,
+				})
+			}
+			if len(account.Modules) > 0 {
+				idx.Accounts = append(idx.Accounts, account)
+			}
+		}
+	}
+	return idx, nil
+}
+
+func countCSVRows(path string) (int, error) {
+	_, rows, err := ReadCSV(path)
+	if err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
+
+// ReadCSV reads a module's CSV file, splitting the header row from the data
+// rows.
+func ReadCSV(path string) ([]string, [][]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening %s: %s", path, err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing %s: %s", path, err)
+	}
+	if len(records) == 0 {
+		return nil, nil, nil
+	}
+	return records[0], records[1:], nil
+}
+
+// Write serializes idx to cloudfox-output/index.json.
+func Write(outputDirectory string, idx *Index) error {
+	dir := filepath.Join(outputDirectory, "cloudfox-output")
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("creating %s: %s", dir, err)
+	}
+
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling index: %s", err)
+	}
+	if err := os.WriteFile(indexPath(outputDirectory), data, 0640); err != nil {
+		return fmt.Errorf("writing %s: %s", indexPath(outputDirectory), err)
+	}
+	return nil
+}
+
+// Upsert adds or replaces one account's one module entry and rewrites
+// index.json. It's called from internal/outputs.FileSink.WriteTable right
+// after a module's CSV is written, so index.json always reflects what's on
+// disk through that path.
+func Upsert(outputDirectory, provider, identifier, dir, module, path string, rows int) error {
+	idx, err := loadOrEmpty(outputDirectory)
+	if err != nil {
+		return err
+	}
+
+	var account *AccountEntry
+	for i := range idx.Accounts {
+		if idx.Accounts[i].Provider == provider && idx.Accounts[i].Identifier == identifier {
+			account = &idx.Accounts[i]
+			break
+		}
+	}
+	if account == nil {
+		idx.Accounts = append(idx.Accounts, AccountEntry{Provider: provider, Identifier: identifier, Dir: dir})
+		account = &idx.Accounts[len(idx.Accounts)-1]
+	}
+
+	entry := ModuleEntry{Name: module, Path: path, Rows: rows}
+	for i := range account.Modules {
+		if account.Modules[i].Name == module {
+			account.Modules[i] = entry
+			return Write(outputDirectory, idx)
+		}
+	}
+	account.Modules = append(account.Modules, entry)
+	return Write(outputDirectory, idx)
+}
+
+func loadOrEmpty(outputDirectory string) (*Index, error) {
+	data, err := os.ReadFile(indexPath(outputDirectory))
+	if os.IsNotExist(err) {
+		return &Index{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %s", indexPath(outputDirectory), err)
+	}
+	var idx Index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("parsing %s: %s", indexPath(outputDirectory), err)
+	}
+	return &idx, nil
+}
@@ -0,0 +1,98 @@
+// Package outputsink lets a module fan a notable finding out to destinations
+// beyond the local table/loot files that internal.OutputClient already
+// writes - SNS, a webhook, or a JSON-lines object in S3 - without the module
+// itself knowing which destinations are configured. A module just builds a
+// Finding and calls Sinks.Emit; cli/aws.go is the only place that decides
+// what Sinks actually contains for a given run.
+package outputsink
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity is a finding's relative importance, used to gate which sinks a
+// finding is forwarded to.
+type Severity int
+
+const (
+	Info Severity = iota
+	Low
+	Medium
+	High
+	Critical
+)
+
+// severityNames is ordered to match the Severity iota values above.
+var severityNames = []string{"info", "low", "medium", "high", "critical"}
+
+func (s Severity) String() string {
+	if s < 0 || int(s) >= len(severityNames) {
+		return "unknown"
+	}
+	return severityNames[s]
+}
+
+// ParseSeverity parses the --sink-severity flag's values (e.g. "high"),
+// defaulting to Info for an empty string so an unset threshold never filters
+// anything out.
+func ParseSeverity(s string) (Severity, error) {
+	if s == "" {
+		return Info, nil
+	}
+	for i, name := range severityNames {
+		if strings.EqualFold(name, s) {
+			return Severity(i), nil
+		}
+	}
+	return Info, fmt.Errorf("unrecognized severity %q, must be one of %s", s, strings.Join(severityNames, ", "))
+}
+
+// Finding is one notable result a module wants surfaced beyond its own
+// output table - a public bucket, an admin escalation path, and so on.
+type Finding struct {
+	Module   string
+	Account  string
+	Profile  string
+	Title    string
+	Row      []string
+	Severity Severity
+}
+
+// Sink is a destination a Finding can be forwarded to.
+type Sink interface {
+	Emit(finding Finding) error
+}
+
+// MultiSink fans a Finding out to every sink it wraps, collecting (rather
+// than stopping on) the first error so one bad webhook doesn't swallow
+// notifications to the others.
+type MultiSink []Sink
+
+func (m MultiSink) Emit(finding Finding) error {
+	var errs []string
+	for _, sink := range m {
+		if err := sink.Emit(finding); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("emitting finding to %d sink(s): %s", len(errs), strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// FilterSink drops findings below Min before handing them to Sink, so a
+// per-module --sink-severity threshold can sit in front of any sink without
+// that sink needing to know about severity at all.
+type FilterSink struct {
+	Min  Severity
+	Sink Sink
+}
+
+func (f FilterSink) Emit(finding Finding) error {
+	if finding.Severity < f.Min {
+		return nil
+	}
+	return f.Sink.Emit(finding)
+}
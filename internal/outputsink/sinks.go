@@ -0,0 +1,195 @@
+package outputsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+)
+
+// StdoutSink prints a Finding the same way cloudfox already prints other
+// one-off notices, for when --sink is left at its default.
+type StdoutSink struct{}
+
+func (StdoutSink) Emit(finding Finding) error {
+	fmt.Printf("[finding][%s][%s] %s: %s\n", finding.Severity, finding.Module, finding.Title, strings.Join(finding.Row, " "))
+	return nil
+}
+
+// findingRecord is the JSON-lines shape S3Sink writes and the payload
+// WebhookSink posts - the same Finding fields, stamped with a timestamp so a
+// downstream consumer can order them without relying on S3/SNS delivery order.
+type findingRecord struct {
+	Timestamp time.Time `json:"timestamp"`
+	Module    string    `json:"module"`
+	Account   string    `json:"account"`
+	Profile   string    `json:"profile"`
+	Title     string    `json:"title"`
+	Row       []string  `json:"row"`
+	Severity  string    `json:"severity"`
+}
+
+func newFindingRecord(finding Finding) findingRecord {
+	return findingRecord{
+		Timestamp: time.Now(),
+		Module:    finding.Module,
+		Account:   finding.Account,
+		Profile:   finding.Profile,
+		Title:     finding.Title,
+		Row:       finding.Row,
+		Severity:  finding.Severity.String(),
+	}
+}
+
+// S3Sink writes each Finding as its own one-line JSON object under
+// Prefix/<module>/<unix-nanos>.jsonl, so no finding overwrites another and a
+// long-running scan never needs to read-modify-write a single ever-growing key.
+type S3Sink struct {
+	Client *s3.Client
+	Bucket string
+	Prefix string
+}
+
+func (s S3Sink) Emit(finding Finding) error {
+	record := newFindingRecord(finding)
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshalling finding: %s", err)
+	}
+
+	key := fmt.Sprintf("%s/%s/%d.jsonl", strings.TrimSuffix(s.Prefix, "/"), finding.Module, record.Timestamp.UnixNano())
+	key = strings.TrimPrefix(key, "/")
+
+	_, err = s.Client.PutObject(context.Background(), &s3.PutObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(append(data, '\n')),
+	})
+	if err != nil {
+		return fmt.Errorf("writing finding to s3://%s/%s: %s", s.Bucket, key, err)
+	}
+	return nil
+}
+
+// SNSSink publishes each Finding as a JSON message to a topic - useful for
+// fanning a finding out to whatever is already subscribed to that topic
+// (email, a Lambda, a queue) without cloudfox needing to know what.
+type SNSSink struct {
+	Client   *sns.Client
+	TopicArn string
+}
+
+func (s SNSSink) Emit(finding Finding) error {
+	data, err := json.Marshal(newFindingRecord(finding))
+	if err != nil {
+		return fmt.Errorf("marshalling finding: %s", err)
+	}
+
+	_, err = s.Client.Publish(context.Background(), &sns.PublishInput{
+		TopicArn: aws.String(s.TopicArn),
+		Subject:  aws.String(fmt.Sprintf("cloudfox finding: %s", finding.Title)),
+		Message:  aws.String(string(data)),
+	})
+	if err != nil {
+		return fmt.Errorf("publishing finding to %s: %s", s.TopicArn, err)
+	}
+	return nil
+}
+
+// WebhookSink POSTs each Finding as JSON to a generic HTTPS endpoint. The
+// payload is plain JSON rather than a Slack/Teams-specific shape, but the
+// "text" field is populated with a one-line summary so both products render
+// something readable out of the box without a translation layer.
+type WebhookSink struct {
+	URL    string
+	Client *http.Client
+}
+
+func (w WebhookSink) Emit(finding Finding) error {
+	record := newFindingRecord(finding)
+	body, err := json.Marshal(struct {
+		findingRecord
+		Text string `json:"text"`
+	}{
+		findingRecord: record,
+		Text:          fmt.Sprintf("[%s][%s] %s: %s", record.Severity, record.Module, record.Title, strings.Join(record.Row, " ")),
+	})
+	if err != nil {
+		return fmt.Errorf("marshalling finding: %s", err)
+	}
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Post(w.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("posting finding to webhook: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %s", resp.Status)
+	}
+	return nil
+}
+
+// ParseSinks builds a Sink for each spec in specs. Recognized forms:
+//
+//	stdout             - StdoutSink (also the default if specs is empty)
+//	s3://bucket/prefix - S3Sink
+//	sns:<topic-arn>    - SNSSink
+//	webhook:<url>      - WebhookSink
+//
+// cfg is used to construct the S3/SNS clients for any specs that need them.
+func ParseSinks(specs []string, cfg aws.Config) (Sink, error) {
+	if len(specs) == 0 {
+		return StdoutSink{}, nil
+	}
+
+	var sinks MultiSink
+	for _, spec := range specs {
+		sink, err := parseSink(spec, cfg)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+func parseSink(spec string, cfg aws.Config) (Sink, error) {
+	switch {
+	case spec == "stdout":
+		return StdoutSink{}, nil
+	case strings.HasPrefix(spec, "s3://"):
+		bucketAndPrefix := strings.TrimPrefix(spec, "s3://")
+		bucket, prefix, _ := strings.Cut(bucketAndPrefix, "/")
+		if bucket == "" {
+			return nil, fmt.Errorf("invalid sink %q: missing bucket name", spec)
+		}
+		return S3Sink{Client: s3.NewFromConfig(cfg), Bucket: bucket, Prefix: prefix}, nil
+	case strings.HasPrefix(spec, "sns:"):
+		topicArn := strings.TrimPrefix(spec, "sns:")
+		if topicArn == "" {
+			return nil, fmt.Errorf("invalid sink %q: missing topic ARN", spec)
+		}
+		return SNSSink{Client: sns.NewFromConfig(cfg), TopicArn: topicArn}, nil
+	case strings.HasPrefix(spec, "webhook:"):
+		url := strings.TrimPrefix(spec, "webhook:")
+		if url == "" {
+			return nil, fmt.Errorf("invalid sink %q: missing URL", spec)
+		}
+		return WebhookSink{URL: url}, nil
+	default:
+		return nil, fmt.Errorf("unrecognized sink %q, must be stdout, s3://bucket/prefix, sns:<topic-arn>, or webhook:<url>", spec)
+	}
+}
@@ -0,0 +1,40 @@
+// Package partition resolves the AWS partition a caller identity belongs
+// to. It's a leaf package (no dependency on the root internal package or
+// any of its other subpackages) so that internal/credbroker - which sits
+// underneath internal in the import graph - can depend on it without
+// creating a cycle.
+package partition
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+)
+
+// Override is set from the --partition flag. When non-empty, it takes
+// precedence over whatever a caller identity ARN's own partition segment
+// says - the escape hatch for a profile whose GetCallerIdentity call
+// itself needs a --partition hint to resolve (e.g. a not-yet-cached
+// GovCloud SSO session).
+var Override string
+
+// Default is the partition every module assumed before this field
+// existed, and what FromCallerARN falls back to when it can't parse an
+// ARN.
+const Default = "aws"
+
+// FromCallerARN returns the AWS partition ("aws", "aws-us-gov", "aws-cn",
+// "aws-iso", "aws-iso-b", ...) a caller identity ARN belongs to, honoring
+// Override first. callerARN is expected to be the Arn field of an
+// sts:GetCallerIdentity response; an unparsable value (or Override left
+// unset with a parse failure) falls back to the commercial "aws"
+// partition rather than an empty string, so callers don't have to
+// special-case it when building further ARNs.
+func FromCallerARN(callerARN string) string {
+	if Override != "" {
+		return Override
+	}
+	parsed, err := arn.Parse(callerARN)
+	if err != nil || parsed.Partition == "" {
+		return Default
+	}
+	return parsed.Partition
+}
@@ -0,0 +1,56 @@
+package privesc
+
+// AzureRoleAssignment is one role assignment as azure.IamPrincipalsModule's
+// getRoleAssignments already enumerates: a principal ID bound to a role
+// name at some scope.
+type AzureRoleAssignment struct {
+	PrincipalID string
+	RoleName    string
+}
+
+// azureDirectAdminRoles are the built-in roles with unrestricted control
+// plane access, matching azure.isAzureAdminRole.
+var azureDirectAdminRoles = map[string]bool{
+	"Owner":       true,
+	"Contributor": true,
+}
+
+// azurePrivescRoles are built-in roles that don't themselves grant
+// unrestricted access but let the holder reach it: User Access
+// Administrator can grant any role (including Owner) to any principal via
+// Microsoft.Authorization/roleAssignments/write, and Managed Identity
+// Operator can assign a user-assigned identity (whose own permissions may be
+// Owner-equivalent) to a resource the holder controls via
+// Microsoft.ManagedIdentity/userAssignedIdentities/assign/action.
+var azurePrivescRoles = map[string]string{
+	"User Access Administrator": "Microsoft.Authorization/roleAssignments/write",
+	"Managed Identity Operator": "Microsoft.ManagedIdentity/userAssignedIdentities/assign/action",
+}
+
+// BuildAzureGraph turns every role assignment seen across the resolved
+// subscriptions into a reachability graph. Unlike the GCP case, neither
+// privesc role here needs a specific target resource modeled as an edge -
+// both are treated as a direct admin-equivalent grant on the assignee.
+func BuildAzureGraph(assignments []AzureRoleAssignment) (*Graph, error) {
+	b := NewBuilder()
+
+	for _, a := range assignments {
+		b.AddPrincipal(a.PrincipalID, azureDirectAdminRoles[a.RoleName])
+		if _, ok := azurePrivescRoles[a.RoleName]; ok {
+			b.AddPrincipal(a.PrincipalID, true)
+		}
+	}
+
+	return b.Build()
+}
+
+// GetAzurePrivescResults mirrors aws.GetPmapperResults: given a built graph
+// and a principal ID, it returns the IsAdmin?/CanPrivEscToAdmin? column
+// values for that principal.
+func GetAzurePrivescResults(g *Graph, principalID string) (isAdmin, canPrivEsc string) {
+	admin, privesc, err := g.CanReachAdmin(principalID)
+	if err != nil {
+		return "Unknown", "Unknown"
+	}
+	return FormatResult(admin, privesc)
+}
@@ -0,0 +1,75 @@
+package privesc
+
+import "strings"
+
+// GCPBinding is one (member, role, resource) triple as returned by
+// CloudAssetService.IamPoliciesService.SearchAll, the same shape
+// gcp.PrincipalsModule.getPrincipals already iterates.
+type GCPBinding struct {
+	Member   string
+	Role     string
+	Resource string
+}
+
+// gcpPrivescPrimitives maps a predefined role, when bound directly on a
+// service account resource, to the privesc primitive it grants against that
+// service account.
+var gcpPrivescPrimitives = map[string]string{
+	"roles/iam.serviceAccountUser":         "iam.serviceAccounts.actAs",
+	"roles/iam.serviceAccountTokenCreator": "iam.serviceAccounts.getAccessToken",
+}
+
+// BuildGCPGraph turns every IAM binding seen across a project (or an
+// organization, if SearchAll was called at that scope) into a reachability
+// graph: a member with iam.serviceAccountUser/iam.serviceAccountTokenCreator
+// on a service account gets an edge to that service account, so a member who
+// can't assume admin directly but can become an admin-equivalent service
+// account still shows up as CanPrivEscToAdmin.
+func BuildGCPGraph(bindings []GCPBinding) (*Graph, error) {
+	b := NewBuilder()
+
+	for _, binding := range bindings {
+		b.AddPrincipal(binding.Member, isGCPAdminRole(binding.Role))
+
+		if primitive, ok := gcpPrivescPrimitives[binding.Role]; ok {
+			if serviceAccount, ok := gcpServiceAccountFromResource(binding.Resource); ok {
+				b.AddEdge(binding.Member, serviceAccount, primitive)
+			}
+		}
+
+		if binding.Role == "roles/deploymentmanager.editor" {
+			// Deployment Manager templates execute as the project's default
+			// Compute Engine service account, which commonly holds
+			// roles/editor on the project. There's no specific target
+			// resource to model an edge against here, so this is recorded
+			// as a direct admin-equivalent grant instead.
+			b.AddPrincipal(binding.Member, true)
+		}
+	}
+
+	return b.Build()
+}
+
+func gcpServiceAccountFromResource(resource string) (string, bool) {
+	const marker = "/serviceAccounts/"
+	idx := strings.Index(resource, marker)
+	if idx < 0 {
+		return "", false
+	}
+	return "serviceAccount:" + resource[idx+len(marker):], true
+}
+
+func isGCPAdminRole(role string) bool {
+	return strings.HasSuffix(role, "/owner") || strings.HasSuffix(role, "/editor")
+}
+
+// GetGcpPrivescResults mirrors aws.GetPmapperResults: given a built graph
+// and a member string, it returns the IsAdmin?/CanPrivEscToAdmin? column
+// values for that member.
+func GetGcpPrivescResults(g *Graph, member string) (isAdmin, canPrivEsc string) {
+	admin, privesc, err := g.CanReachAdmin(member)
+	if err != nil {
+		return "Unknown", "Unknown"
+	}
+	return FormatResult(admin, privesc)
+}
@@ -0,0 +1,139 @@
+// Package privesc is the provider-agnostic counterpart to the AWS package's
+// pmapper integration (aws.InitPmapperGraph/aws.GetPmapperResults - neither
+// defined in this checkout, but their call sites in aws/principals.go,
+// aws/ecs-tasks.go, and aws/codebuild-escalation.go show the shape this
+// package mirrors). GCP and Azure have no pmapper equivalent, so this
+// package builds its own reachability graph over github.com/dominikbraun/
+// graph: vertices are principals, edges are known privilege-escalation
+// primitives, and "can this principal reach an admin-equivalent node" is a
+// plain graph search instead of a pmapper-compatible solver.
+package privesc
+
+import (
+	"fmt"
+
+	"github.com/dominikbraun/graph"
+)
+
+// Node is one principal in the graph: a GCP member string
+// ("user:alice@example.com", "serviceAccount:sa@project.iam.gserviceaccount.com")
+// or an Azure principal/object ID, tagged with whether it already holds an
+// admin-equivalent grant directly.
+type Node struct {
+	ID      string
+	IsAdmin bool
+}
+
+type primEdge struct {
+	source, target, primitive string
+}
+
+// Builder collects principals and privesc edges before the graph itself is
+// built. A principal can be declared admin more than once (e.g. a role
+// assignment module sees it for more than one role); Build keeps it admin if
+// any declaration said so, rather than requiring callers to pre-merge.
+type Builder struct {
+	isAdmin map[string]bool
+	edges   []primEdge
+}
+
+// NewBuilder returns an empty Builder.
+func NewBuilder() *Builder {
+	return &Builder{isAdmin: map[string]bool{}}
+}
+
+// AddPrincipal registers a principal, OR-ing isAdmin into whatever was
+// already recorded for it.
+func (b *Builder) AddPrincipal(id string, isAdmin bool) {
+	b.isAdmin[id] = b.isAdmin[id] || isAdmin
+}
+
+// AddEdge records that source can reach target via a known privesc
+// primitive (e.g. "iam.serviceAccounts.actAs",
+// "Microsoft.Authorization/roleAssignments/write"). Both ends are
+// registered as principals automatically if AddPrincipal wasn't already
+// called for them.
+func (b *Builder) AddEdge(source, target, primitive string) {
+	b.AddPrincipal(source, false)
+	b.AddPrincipal(target, false)
+	b.edges = append(b.edges, primEdge{source: source, target: target, primitive: primitive})
+}
+
+// Build constructs the reachability graph. Graph is safe to query
+// concurrently once built; building it is not meant to be repeated per
+// query.
+func (b *Builder) Build() (*Graph, error) {
+	hash := func(n Node) string { return n.ID }
+	g := graph.New(hash, graph.Directed())
+
+	var adminNodes []string
+	for id, admin := range b.isAdmin {
+		if err := g.AddVertex(Node{ID: id, IsAdmin: admin}); err != nil {
+			return nil, fmt.Errorf("adding principal %s: %s", id, err)
+		}
+		if admin {
+			adminNodes = append(adminNodes, id)
+		}
+	}
+
+	for _, e := range b.edges {
+		if err := g.AddEdge(e.source, e.target, graph.EdgeAttribute("primitive", e.primitive)); err != nil && err != graph.ErrEdgeAlreadyExists {
+			return nil, fmt.Errorf("adding privesc edge %s -> %s via %s: %s", e.source, e.target, e.primitive, err)
+		}
+	}
+
+	return &Graph{g: g, adminNodes: adminNodes}, nil
+}
+
+// Graph is a built reachability graph, ready to be queried via
+// CanReachAdmin.
+type Graph struct {
+	g          graph.Graph[string, Node]
+	adminNodes []string
+}
+
+// CanReachAdmin reports whether id is itself admin-equivalent, or can reach
+// an admin-equivalent node through one or more privesc edges. An id absent
+// from the graph is neither - it was never observed as a principal or an
+// edge endpoint.
+func (pg *Graph) CanReachAdmin(id string) (isAdmin, canPrivEsc bool, err error) {
+	node, err := pg.g.Vertex(id)
+	if err == graph.ErrVertexNotFound {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, fmt.Errorf("looking up principal %s: %s", id, err)
+	}
+	if node.IsAdmin {
+		return true, false, nil
+	}
+
+	for _, admin := range pg.adminNodes {
+		if admin == id {
+			continue
+		}
+		path, err := graph.ShortestPath(pg.g, id, admin)
+		if err != nil && err != graph.ErrTargetNotReachable {
+			return false, false, fmt.Errorf("searching for a privesc path from %s to %s: %s", id, admin, err)
+		}
+		if len(path) > 0 {
+			return false, true, nil
+		}
+	}
+	return false, false, nil
+}
+
+// FormatResult renders isAdmin/canPrivEsc as the "YES"/"No" strings the AWS/
+// GCP/Azure IAM principals modules' IsAdmin?/CanPrivEscToAdmin? columns
+// already use.
+func FormatResult(isAdmin, canPrivEsc bool) (string, string) {
+	admin := "No"
+	if isAdmin {
+		admin = "YES"
+	}
+	privesc := "No"
+	if canPrivEsc {
+		privesc = "YES"
+	}
+	return admin, privesc
+}
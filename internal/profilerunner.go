@@ -0,0 +1,105 @@
+package internal
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ProfileRunner fans a per-profile closure out across a bounded worker
+// pool, with an optional per-profile timeout and aggregated error
+// reporting, so a single bad profile (revoked credentials, a hung API
+// call) can't stall or abort a scan across dozens of other profiles.
+type ProfileRunner struct {
+	Concurrency int
+	Timeout     time.Duration
+}
+
+// NewProfileRunner returns a ProfileRunner bounded to concurrency workers.
+// concurrency <= 1 runs profiles one at a time, in order - the same
+// behavior as a plain range loop, and the default unless --profile-concurrency
+// is raised.
+func NewProfileRunner(concurrency int) ProfileRunner {
+	return ProfileRunner{Concurrency: concurrency}
+}
+
+// ProfileError pairs a profile with the error its closure returned, so a
+// Run's aggregated failures can still be traced back to an account.
+type ProfileError struct {
+	Profile string
+	Err     error
+}
+
+func (e ProfileError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Profile, e.Err)
+}
+
+// Run calls fn once per profile, fanning out across r.Concurrency workers
+// (serially if <= 1), printing a one-line status for every profile that
+// fails and returning every error it collected along the way instead of
+// stopping at the first one.
+func (r ProfileRunner) Run(profiles []string, fn func(profile string) error) []ProfileError {
+	if r.Concurrency <= 1 {
+		var errs []ProfileError
+		for _, profile := range profiles {
+			if err := r.runOne(profile, fn); err != nil {
+				errs = append(errs, *err)
+			}
+		}
+		return errs
+	}
+
+	var (
+		mu   sync.Mutex
+		errs []ProfileError
+		wg   sync.WaitGroup
+	)
+	semaphore := make(chan struct{}, r.Concurrency)
+	for _, profile := range profiles {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(profile string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			if err := r.runOne(profile, fn); err != nil {
+				mu.Lock()
+				errs = append(errs, *err)
+				mu.Unlock()
+			}
+		}(profile)
+	}
+	wg.Wait()
+	return errs
+}
+
+// runOne runs fn for a single profile, enforcing r.Timeout (if set) and
+// turning a panic inside fn into an error rather than taking the whole run
+// down with it.
+func (r ProfileRunner) runOne(profile string, fn func(profile string) error) *ProfileError {
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				done <- fmt.Errorf("panic: %v", rec)
+			}
+		}()
+		done <- fn(profile)
+	}()
+
+	var err error
+	if r.Timeout > 0 {
+		select {
+		case err = <-done:
+		case <-time.After(r.Timeout):
+			err = fmt.Errorf("timed out after %s", r.Timeout)
+		}
+	} else {
+		err = <-done
+	}
+
+	if err == nil {
+		return nil
+	}
+	TxtLog.WithField("profile", profile).Errorf("profile failed: %s", err)
+	return &ProfileError{Profile: profile, Err: err}
+}
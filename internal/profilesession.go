@@ -0,0 +1,49 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// profileSession caches the AWSConfig/AWSWhoami pair for one profile, so
+// every module a command dispatches to for that profile reuses the same
+// credentials and caller identity instead of re-resolving them.
+type profileSession struct {
+	Config aws.Config
+	Caller *sts.GetCallerIdentityOutput
+}
+
+var (
+	profileSessionMu sync.Mutex
+	profileSessions  = map[string]*profileSession{}
+)
+
+// GetProfileSession returns the AWSConfig/caller identity for profile,
+// resolving and GetCallerIdentity-ing them at most once per profile for the
+// life of the process - runAllChecksCommand (and any run*Command that fans
+// out across many modules for the same profiles) would otherwise repeat
+// both for every module.
+func GetProfileSession(profile string, version string, mfaToken string) (aws.Config, *sts.GetCallerIdentityOutput, error) {
+	profileSessionMu.Lock()
+	if s, ok := profileSessions[profile]; ok {
+		profileSessionMu.Unlock()
+		return s.Config, s.Caller, nil
+	}
+	profileSessionMu.Unlock()
+
+	cfg := AWSConfigFileLoader(profile, version, mfaToken)
+	caller, err := sts.NewFromConfig(cfg).GetCallerIdentity(context.Background(), &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return cfg, nil, fmt.Errorf("getting caller identity for profile %s: %s", profile, err)
+	}
+
+	profileSessionMu.Lock()
+	profileSessions[profile] = &profileSession{Config: cfg, Caller: caller}
+	profileSessionMu.Unlock()
+
+	return cfg, caller, nil
+}
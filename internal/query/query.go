@@ -0,0 +1,282 @@
+// Package query lets "cloudfox aws query" filter across the CSV files
+// modules already write to cloudfox-output/aws/<profile>-<account>/ (the
+// same layout internal/outputs.FileSink produces), instead of each module's
+// --cached output being re-examined by hand one file at a time.
+//
+// --cached's own on-disk format (internal.SaveCacheToGobFiles/
+// LoadCacheFromGobFiles/SaveCacheToFiles) isn't usable here: those functions
+// serialize raw SDK API responses for --cached's own re-run-without-
+// refetching purpose, they aren't defined anywhere in this tree to inspect
+// their shape, and what they cache isn't the stable, column-named rows a
+// query needs anyway. The per-module CSVs already written by every
+// PrintXxx call are that stable representation, so this package reads those
+// instead.
+package query
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Table is one module's CSV loaded into memory.
+type Table struct {
+	Module string
+	Header []string
+	Rows   [][]string
+}
+
+// LoadModules reads "<module>.csv" for each name in modules out of
+// accountDir (e.g. <outdir>/cloudfox-output/aws/<profile>-<account>/). A
+// module with no CSV there yet (never run, or --cached not yet written) is
+// skipped rather than treated as an error, so --from can list modules that
+// haven't all been run for every account in --profiles-list.
+func LoadModules(accountDir string, modules []string) ([]Table, error) {
+	var tables []Table
+	for _, module := range modules {
+		path := filepath.Join(accountDir, module+".csv")
+		file, err := os.Open(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("opening %s: %s", path, err)
+		}
+
+		rows, err := csv.NewReader(file).ReadAll()
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %s", path, err)
+		}
+		if len(rows) == 0 {
+			continue
+		}
+
+		tables = append(tables, Table{Module: module, Header: rows[0], Rows: rows[1:]})
+	}
+	return tables, nil
+}
+
+// row is one CSV row as a column-name-keyed map, for predicate evaluation.
+type row map[string]string
+
+// Predicate reports whether r matches a parsed --where clause.
+type Predicate interface {
+	Match(r row) bool
+}
+
+type andPredicate []Predicate
+
+func (a andPredicate) Match(r row) bool {
+	for _, p := range a {
+		if !p.Match(r) {
+			return false
+		}
+	}
+	return true
+}
+
+type comparison struct {
+	column string
+	op     string
+	value  string
+}
+
+func (c comparison) Match(r row) bool {
+	got, ok := r[c.column]
+	if !ok {
+		return false
+	}
+	switch c.op {
+	case "=":
+		return got == c.value
+	case "!=":
+		return got != c.value
+	case "like":
+		return matchLike(got, c.value)
+	default:
+		return false
+	}
+}
+
+// matchLike implements the subset of SQL LIKE this package supports: "%"
+// matches any run of characters (including none) wherever it appears in
+// pattern, so "arn:aws:iam::%:role/dev-%" matches every dev- role in any
+// account. Every other character, "_" and "*" included, matches only itself.
+func matchLike(value, pattern string) bool {
+	parts := strings.Split(pattern, "%")
+	if len(parts) == 1 {
+		return value == pattern
+	}
+	if !strings.HasPrefix(value, parts[0]) {
+		return false
+	}
+	value = value[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		idx := strings.Index(value, part)
+		if idx < 0 {
+			return false
+		}
+		value = value[idx+len(part):]
+	}
+	return strings.HasSuffix(value, parts[len(parts)-1])
+}
+
+// ParseWhere parses a --where expression: one or more "column op value"
+// comparisons joined by "and" (case-insensitive). op is one of "=", "!=",
+// "like". A value may optionally be single- or double-quoted; quotes are
+// stripped before matching.
+func ParseWhere(expr string) (Predicate, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return andPredicate{}, nil
+	}
+
+	var clauses andPredicate
+	for _, clause := range splitAnd(expr) {
+		c, err := parseComparison(clause)
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, c)
+	}
+	return clauses, nil
+}
+
+// splitAnd splits on the word "and" outside of quotes.
+func splitAnd(expr string) []string {
+	var parts []string
+	var current strings.Builder
+	var inQuote rune
+	words := strings.Fields(expr)
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if inQuote == 0 && strings.EqualFold(word, "and") {
+			parts = append(parts, strings.TrimSpace(current.String()))
+			current.Reset()
+			continue
+		}
+		if current.Len() > 0 {
+			current.WriteByte(' ')
+		}
+		current.WriteString(word)
+		for _, r := range word {
+			if r == '\'' || r == '"' {
+				if inQuote == r {
+					inQuote = 0
+				} else if inQuote == 0 {
+					inQuote = r
+				}
+			}
+		}
+	}
+	parts = append(parts, strings.TrimSpace(current.String()))
+	return parts
+}
+
+// operator patterns are checked longest-delimiter-first so "!=" and " like "
+// are never misread as a bare "=".
+var operatorDelims = []struct {
+	op    string
+	delim string
+}{
+	{"!=", "!="},
+	{"like", " like "},
+	{"=", "="},
+}
+
+func parseComparison(clause string) (comparison, error) {
+	for _, o := range operatorDelims {
+		idx := strings.Index(strings.ToLower(clause), o.delim)
+		if idx < 0 {
+			continue
+		}
+		column := strings.TrimSpace(clause[:idx])
+		value := unquote(strings.TrimSpace(clause[idx+len(o.delim):]))
+		if column == "" {
+			continue
+		}
+		return comparison{column: column, op: o.op, value: value}, nil
+	}
+	return comparison{}, fmt.Errorf("unrecognized --where clause %q: expected \"column = value\", \"column != value\", or \"column like 'pattern'\"", clause)
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 {
+		if (value[0] == '\'' && value[len(value)-1] == '\'') || (value[0] == '"' && value[len(value)-1] == '"') {
+			return value[1 : len(value)-1]
+		}
+	}
+	return value
+}
+
+// Result is one row surviving a query, tagged with the module it came from
+// since --from can list more than one.
+type Result struct {
+	Module string
+	Row    []string
+}
+
+// Run filters each table's rows through where and projects select (falling
+// back to the union of every table's header, in first-seen order, if select
+// is empty), returning the header to print and the matching rows.
+func Run(tables []Table, where Predicate, selectCols []string) ([]string, []Result) {
+	header := selectCols
+	if len(header) == 0 {
+		header = unionHeaders(tables)
+	}
+	header = append([]string{"module"}, header...)
+
+	var results []Result
+	for _, table := range tables {
+		colIndex := make(map[string]int, len(table.Header))
+		for i, col := range table.Header {
+			colIndex[col] = i
+		}
+
+		for _, r := range table.Rows {
+			asRow := make(row, len(table.Header))
+			for i, col := range table.Header {
+				if i < len(r) {
+					asRow[col] = r[i]
+				}
+			}
+			if !where.Match(asRow) {
+				continue
+			}
+
+			projected := make([]string, 0, len(header)-1)
+			for _, col := range header[1:] {
+				if idx, ok := colIndex[col]; ok && idx < len(r) {
+					projected = append(projected, r[idx])
+				} else {
+					projected = append(projected, "")
+				}
+			}
+			results = append(results, Result{Module: table.Module, Row: append([]string{table.Module}, projected...)})
+		}
+	}
+	return header, results
+}
+
+func unionHeaders(tables []Table) []string {
+	seen := map[string]bool{}
+	var cols []string
+	for _, table := range tables {
+		for _, col := range table.Header {
+			if !seen[col] {
+				seen[col] = true
+				cols = append(cols, col)
+			}
+		}
+	}
+	return cols
+}
+
+// FormatCount renders "N row(s)" for a --where summary line.
+func FormatCount(n int) string {
+	return strconv.Itoa(n) + " row(s)"
+}
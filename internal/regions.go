@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+)
+
+// RegionsOverride is set from the --regions flag. When non-empty,
+// GetEnabledRegions returns it directly for every profile and never calls
+// ec2:DescribeRegions.
+var RegionsOverride []string
+
+var (
+	regionCacheMu sync.Mutex
+	regionCache   = map[string][]string{}
+)
+
+// GetEnabledRegions returns the enabled regions for profile, calling
+// ec2:DescribeRegions at most once per profile for the life of the
+// invocation - every runXxxCommand dispatcher in cli/aws.go calls this once
+// per module, so without the cache a multi-hundred-account org scan turns
+// into one DescribeRegions call per module per account instead of one per
+// account.
+func GetEnabledRegions(profile string, version string, mfaToken string) []string {
+	if len(RegionsOverride) > 0 {
+		return RegionsOverride
+	}
+
+	regionCacheMu.Lock()
+	if regions, ok := regionCache[profile]; ok {
+		regionCacheMu.Unlock()
+		return regions
+	}
+	regionCacheMu.Unlock()
+
+	regions, err := describeEnabledRegions(profile, version, mfaToken)
+	if err != nil {
+		fmt.Printf("[-] Error describing regions for profile %s: %s\n", profile, err)
+		return nil
+	}
+
+	regionCacheMu.Lock()
+	regionCache[profile] = regions
+	regionCacheMu.Unlock()
+
+	return regions
+}
+
+func describeEnabledRegions(profile string, version string, mfaToken string) ([]string, error) {
+	cfg := AWSConfigFileLoader(profile, version, mfaToken)
+
+	output, err := ec2.NewFromConfig(cfg).DescribeRegions(context.TODO(), &ec2.DescribeRegionsInput{})
+	if err != nil {
+		return nil, fmt.Errorf("describing regions: %s", err)
+	}
+
+	var regions []string
+	for _, region := range output.Regions {
+		regions = append(regions, aws.ToString(region.RegionName))
+	}
+	return regions, nil
+}
@@ -0,0 +1,79 @@
+package scancache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Manifest tracks which profiles a multi-profile cape run has already
+// finished, so a run interrupted partway through (a revoked credential, a
+// killed process) can be continued with --resume instead of restarted from
+// the first profile.
+type Manifest struct {
+	JobID     string          `json:"job_id"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	Done      map[string]bool `json:"done"`
+
+	path string
+}
+
+func manifestPath(jobID string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolving home directory: %s", err)
+	}
+	dir := filepath.Join(home, ".cloudfox", "cache", "manifests")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("creating manifest directory %s: %s", dir, err)
+	}
+	return filepath.Join(dir, jobID+".json"), nil
+}
+
+// LoadManifest reads the manifest for jobID, returning a fresh, empty one if
+// none exists yet.
+func LoadManifest(jobID string) (*Manifest, error) {
+	path, err := manifestPath(jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{JobID: jobID, CreatedAt: time.Now(), Done: map[string]bool{}, path: path}
+
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest %s: %s", path, err)
+	}
+	if err := json.Unmarshal(raw, m); err != nil {
+		return nil, fmt.Errorf("parsing manifest %s: %s", path, err)
+	}
+	m.path = path
+	return m, nil
+}
+
+// IsDone reports whether profile completed successfully in a prior run of
+// this job.
+func (m *Manifest) IsDone(profile string) bool {
+	return m.Done[profile]
+}
+
+// MarkDone records profile as completed and persists the manifest.
+func (m *Manifest) MarkDone(profile string) error {
+	m.Done[profile] = true
+	m.UpdatedAt = time.Now()
+
+	raw, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding manifest %s: %s", m.JobID, err)
+	}
+	if err := os.WriteFile(m.path, raw, 0600); err != nil {
+		return fmt.Errorf("writing manifest %s: %s", m.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// Package scancache is a small, file-based cache. Entries live under
+// ~/.cloudfox/cache/<accountId>/ as JSON, each stored under a key, a hash
+// and a TTL, and a Load within the TTL whose hash matches returns the cached
+// value instead of a re-fetch. Two callers use it: cape caches the GAAD
+// permission rows for --cache-ttl under a hash of the profile and account
+// ID only, so IAM changes made within the TTL are not picked up; and the
+// endpoints module caches each endpoint's probe result for 24 hours.
+// ListRoles, ListUsers, pmapper data and the privesc graph are not cached.
+package scancache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// entry is the on-disk envelope around a cached value.
+type entry struct {
+	Hash     string          `json:"hash"`
+	StoredAt time.Time       `json:"stored_at"`
+	TTL      time.Duration   `json:"ttl"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// Dir returns the cache directory for accountID, creating it if necessary.
+func Dir(accountID string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolving home directory: %s", err)
+	}
+	dir := filepath.Join(home, ".cloudfox", "cache", accountID)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("creating cache directory %s: %s", dir, err)
+	}
+	return dir, nil
+}
+
+// Hash returns a stable hash of parts, suitable for use as an entry's
+// content hash (e.g. a GAAD blob's size/ETag, or a join of resource IDs).
+func Hash(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Load reads the cached value for key into out, returning false if the
+// entry is missing, expired (older than its stored TTL), or its hash
+// doesn't match the caller's current hash of the source data.
+func Load(accountID, key, hash string, out interface{}) (bool, error) {
+	dir, err := Dir(accountID)
+	if err != nil {
+		return false, err
+	}
+	path := filepath.Join(dir, key+".json")
+
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("reading cache entry %s: %s", path, err)
+	}
+
+	var e entry
+	if err := json.Unmarshal(raw, &e); err != nil {
+		return false, fmt.Errorf("parsing cache entry %s: %s", path, err)
+	}
+
+	if e.Hash != hash || time.Since(e.StoredAt) > e.TTL {
+		return false, nil
+	}
+
+	if err := json.Unmarshal(e.Data, out); err != nil {
+		return false, fmt.Errorf("decoding cache entry %s: %s", path, err)
+	}
+	return true, nil
+}
+
+// Store writes data under key, stamped with hash and ttl, so a later Load
+// with the same hash within ttl returns it instead of recomputing it.
+func Store(accountID, key, hash string, ttl time.Duration, data interface{}) error {
+	dir, err := Dir(accountID)
+	if err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("encoding cache entry %s: %s", key, err)
+	}
+	e := entry{Hash: hash, StoredAt: time.Now(), TTL: ttl, Data: raw}
+
+	out, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding cache entry %s: %s", key, err)
+	}
+
+	path := filepath.Join(dir, key+".json")
+	if err := os.WriteFile(path, out, 0600); err != nil {
+		return fmt.Errorf("writing cache entry %s: %s", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+// Package securityhub turns a module's admin/privesc findings into AWS
+// Security Hub's ASFF (AWS Security Finding Format) and, when a client is
+// given, imports them via BatchImportFindings. It's consumed by any module
+// whose --output supports "securityhub" - currently IamPrincipalsModule.
+package securityhub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/securityhub"
+	"github.com/aws/aws-sdk-go-v2/service/securityhub/types"
+)
+
+// Finding is one result to export as an ASFF finding: an admin or
+// privesc-capable principal, keyed by Arn so a re-run updates the same
+// Security Hub finding instead of creating a duplicate.
+type Finding struct {
+	Module     string
+	Arn        string
+	Title      string
+	Admin      string
+	CanPrivEsc string
+}
+
+// asffSchemaVersion is the ASFF version BatchImportFindings currently
+// requires.
+const asffSchemaVersion = "2018-10-08"
+
+// BuildASFF converts findings into ASFF, scoped to accountID/region/partition
+// the way Security Hub expects AwsAccountId/ProductArn to be. GeneratorId is
+// deterministic per module ("cloudfox-<module>") and Id is deterministic per
+// finding ("cloudfox/<module>/<arn>"), so importing the same finding twice
+// updates it in place rather than creating a duplicate.
+func BuildASFF(findings []Finding, accountID, region, partition string) []types.AwsSecurityFinding {
+	now := time.Now().UTC().Format(time.RFC3339)
+	productArn := fmt.Sprintf("arn:%s:securityhub:%s::product/%s/default", partition, region, accountID)
+
+	asff := make([]types.AwsSecurityFinding, 0, len(findings))
+	for _, f := range findings {
+		asff = append(asff, types.AwsSecurityFinding{
+			SchemaVersion: aws.String(asffSchemaVersion),
+			Id:            aws.String(fmt.Sprintf("cloudfox/%s/%s", f.Module, f.Arn)),
+			ProductArn:    aws.String(productArn),
+			GeneratorId:   aws.String("cloudfox-" + f.Module),
+			AwsAccountId:  aws.String(accountID),
+			Types:         []string{"Software and Configuration Checks/AWS Security Best Practices"},
+			CreatedAt:     aws.String(now),
+			UpdatedAt:     aws.String(now),
+			Severity:      &types.Severity{Label: severityLabel(f.Admin, f.CanPrivEsc)},
+			Title:         aws.String(f.Title),
+			Description:   aws.String(fmt.Sprintf("%s - IsAdmin=%s, CanPrivEscToAdmin=%s", f.Arn, f.Admin, f.CanPrivEsc)),
+			Resources: []types.Resource{
+				{Type: aws.String("AwsIamRole"), Id: aws.String(f.Arn)},
+			},
+			RecordState: types.RecordStateActive,
+		})
+	}
+	return asff
+}
+
+// severityLabel maps the IsAdmin?/CanPrivEscToAdmin? columns every IAM
+// principals module already produces to an ASFF severity: an admin
+// principal is CRITICAL, one that can privesc to admin is HIGH, anything
+// else is INFORMATIONAL.
+func severityLabel(admin, canPrivEsc string) types.SeverityLabel {
+	switch {
+	case admin == "YES":
+		return types.SeverityLabelCritical
+	case canPrivEsc == "YES":
+		return types.SeverityLabelHigh
+	default:
+		return types.SeverityLabelInformational
+	}
+}
+
+// Write serializes findings as an ASFF JSON array to
+// <outputDirectory>/securityhub.json, the on-disk counterpart to Import for
+// runs that want the ASFF payload without calling the API (e.g. to hand to
+// another ingestion pipeline). Does nothing if findings is empty.
+func Write(outputDirectory string, findings []Finding, accountID, region, partition string) error {
+	if len(findings) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(outputDirectory, 0755); err != nil {
+		return fmt.Errorf("creating %s: %s", outputDirectory, err)
+	}
+
+	data, err := json.MarshalIndent(BuildASFF(findings, accountID, region, partition), "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling ASFF findings: %s", err)
+	}
+
+	path := filepath.Join(outputDirectory, "securityhub.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("writing %s: %s", path, err)
+	}
+	fmt.Printf("[securityhub] ASFF findings written to %s\n", path)
+	return nil
+}
+
+// Import calls BatchImportFindings for findings, in batches of at most 100 -
+// the limit BatchImportFindings itself enforces per call. A non-zero
+// FailedCount is reported as an error naming the first failure, rather than
+// silently dropping it.
+func Import(ctx context.Context, client *securityhub.Client, findings []Finding, accountID, region, partition string) error {
+	if len(findings) == 0 {
+		return nil
+	}
+
+	asff := BuildASFF(findings, accountID, region, partition)
+	const batchSize = 100
+	for start := 0; start < len(asff); start += batchSize {
+		end := start + batchSize
+		if end > len(asff) {
+			end = len(asff)
+		}
+
+		output, err := client.BatchImportFindings(ctx, &securityhub.BatchImportFindingsInput{
+			Findings: asff[start:end],
+		})
+		if err != nil {
+			return fmt.Errorf("importing findings to security hub: %s", err)
+		}
+		if output.FailedCount != nil && *output.FailedCount > 0 {
+			var firstErr string
+			if len(output.FailedFindings) > 0 && output.FailedFindings[0].ErrorMessage != nil {
+				firstErr = *output.FailedFindings[0].ErrorMessage
+			}
+			return fmt.Errorf("%d of %d findings failed to import to security hub, e.g. %s", *output.FailedCount, end-start, firstErr)
+		}
+	}
+
+	fmt.Printf("[securityhub] %d finding(s) imported\n", len(asff))
+	return nil
+}
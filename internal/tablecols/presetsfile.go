@@ -0,0 +1,60 @@
+package tablecols
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+// PresetsFile is the shape of ~/.cloudfox/presets.yaml: module name ->
+// preset name -> columns, the same two-level shape Register populates
+// in-memory.
+type PresetsFile struct {
+	Presets map[string]map[string][]string `yaml:"presets"`
+}
+
+// DefaultPresetsPath returns $HOME/.cloudfox/presets.yaml.
+func DefaultPresetsPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".cloudfox", "presets.yaml")
+}
+
+// LoadUserPresets reads and parses ~/.cloudfox/presets.yaml. A missing file
+// is not an error - most invocations won't have one - it just yields a
+// PresetsFile with no entries.
+func LoadUserPresets() (PresetsFile, error) {
+	path := DefaultPresetsPath()
+	if path == "" {
+		return PresetsFile{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return PresetsFile{}, nil
+		}
+		return PresetsFile{}, fmt.Errorf("reading presets file %s: %s", path, err)
+	}
+
+	var file PresetsFile
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return PresetsFile{}, fmt.Errorf("parsing presets file %s: %s", path, err)
+	}
+	return file, nil
+}
+
+// RegisterAll merges every module/preset pair in file into the in-memory
+// registry, overriding any built-in preset of the same name - so a user can
+// redefine "default" for a module in their presets.yaml.
+func (file PresetsFile) RegisterAll() {
+	for module, modulePresets := range file.Presets {
+		for preset, columns := range modulePresets {
+			Register(module, preset, columns)
+		}
+	}
+}
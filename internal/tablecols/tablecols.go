@@ -0,0 +1,107 @@
+// Package tablecols centralizes the column-selection logic that used to be
+// duplicated across modules as a strings.Split/strings.ReplaceAll dance on
+// --cols plus a hand-rolled removeStringFromSlice call. It's built on
+// golang.org/x/exp/slices' generics and adds a preset system on top: a
+// module registers its named column sets (minimal, default, wide, audit,
+// privesc, ...) once via Register, and --columns-preset selects one of them
+// at run time. Users can add their own presets in ~/.cloudfox/presets.yaml
+// (see LoadUserPresets) without touching any module's code.
+package tablecols
+
+import (
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// presets maps module name -> preset name -> columns. Populated by Register
+// (module defaults) and by merging in a user's ~/.cloudfox/presets.yaml
+// (LoadUserPresets + RegisterAll), in that order, so a user preset with the
+// same name as a built-in one overrides it.
+var presets = map[string]map[string][]string{}
+
+// Register adds or replaces one named preset for module. Modules call this
+// from an init() with their built-in presets, e.g. "minimal", "default",
+// "wide", "audit", "privesc".
+func Register(module, preset string, columns []string) {
+	if presets[module] == nil {
+		presets[module] = map[string][]string{}
+	}
+	presets[module][preset] = columns
+}
+
+// Lookup returns the columns registered for module/preset, and whether a
+// preset by that name exists.
+func Lookup(module, preset string) ([]string, bool) {
+	cols, ok := presets[module][preset]
+	return cols, ok
+}
+
+// ParseList splits a user-supplied --cols value on commas, trimming spaces
+// around each entry, replacing the old strings.ReplaceAll(", "->",") dance
+// every module used to repeat.
+func ParseList(raw string) []string {
+	parts := strings.Split(raw, ",")
+	cols := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if trimmed := strings.TrimSpace(p); trimmed != "" {
+			cols = append(cols, trimmed)
+		}
+	}
+	return cols
+}
+
+// Contains reports whether cols contains col. Thin wrapper over
+// slices.Contains so callers don't need their own import of x/exp/slices.
+func Contains(cols []string, col string) bool {
+	return slices.Contains(cols, col)
+}
+
+// Remove returns cols with every occurrence of col deleted, replacing the
+// ad-hoc removeStringFromSlice helper modules used to call. cols is not
+// mutated in place.
+func Remove(cols []string, col string) []string {
+	out := slices.Clone(cols)
+	return slices.DeleteFunc(out, func(c string) bool { return c == col })
+}
+
+// Options configures Select.
+type Options struct {
+	// Module is the preset registry key, normally the same string as
+	// output.CallingModule for the calling module.
+	Module string
+	// Explicit is the raw --cols value, if the user passed one. Wins over
+	// Preset and Wide when non-empty.
+	Explicit string
+	// Preset is the raw --columns-preset value, if the user passed one.
+	Preset string
+	// Wide is true when the user asked for -o/--output wide and didn't pass
+	// --cols or --columns-preset.
+	Wide bool
+	// Default is the module's hardcoded default columns.
+	Default []string
+	// WideCols is the module's hardcoded wide columns, used when Wide is
+	// true and neither Explicit nor Preset is set.
+	WideCols []string
+}
+
+// Select resolves the column list a module should render, in the order
+// every module already applied by hand: an explicit --cols value wins,
+// then a --columns-preset registered for this module, then -o wide's
+// columns, then the module's hardcoded default.
+func Select(opts Options) []string {
+	switch {
+	case opts.Explicit != "":
+		return ParseList(opts.Explicit)
+	case opts.Preset != "":
+		if cols, ok := Lookup(opts.Module, opts.Preset); ok {
+			return cols
+		}
+		// Unknown preset name: fall through to the wide/default behavior
+		// rather than erroring out mid-module.
+	}
+	if opts.Wide && opts.WideCols != nil {
+		return opts.WideCols
+	}
+	return opts.Default
+}
@@ -0,0 +1,190 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// moduleView is the row-table screen: a filterable, column-toggleable table
+// over one module's CSV plus a detail pane for the selected row.
+type moduleView struct {
+	app      *App
+	pageName string
+	backPage string
+
+	header      []string
+	rows        [][]string
+	visibleCols []bool
+	filter      string
+
+	table     *tview.Table
+	detail    *tview.TextView
+	container *tview.Flex
+}
+
+func newModuleView(app *App, pageName, backPage string, header []string, rows [][]string) *moduleView {
+	v := &moduleView{
+		app:         app,
+		pageName:    pageName,
+		backPage:    backPage,
+		header:      header,
+		rows:        rows,
+		visibleCols: make([]bool, len(header)),
+	}
+	for i := range v.visibleCols {
+		v.visibleCols[i] = true
+	}
+
+	v.table = tview.NewTable().SetFixed(1, 0).SetSelectable(true, false)
+	v.table.SetBorder(true).SetTitle("Rows  (/ filter, c columns, Enter detail, b/Esc back)")
+
+	v.detail = tview.NewTextView().SetDynamicColors(true)
+	v.detail.SetBorder(true).SetTitle("Detail")
+
+	v.container = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(v.table, 0, 3, true).
+		AddItem(v.detail, 0, 1, false)
+
+	v.table.SetSelectedFunc(func(row, column int) { v.showDetail(row) })
+	v.table.SetInputCapture(v.handleKey)
+
+	v.redraw()
+	return v
+}
+
+func (v *moduleView) root() tview.Primitive { return v.container }
+
+func (v *moduleView) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	switch {
+	case isBackKey(event):
+		v.app.pages.SwitchToPage(v.backPage)
+		v.app.pages.RemovePage(v.pageName)
+		return nil
+	case event.Rune() == '/':
+		v.promptFilter()
+		return nil
+	case event.Rune() == 'c':
+		v.promptColumnToggle()
+		return nil
+	}
+	return event
+}
+
+// filteredRows returns the rows matching v.filter (a case-insensitive
+// substring match against any visible cell), or every row if no filter is
+// set.
+func (v *moduleView) filteredRows() [][]string {
+	if v.filter == "" {
+		return v.rows
+	}
+	needle := strings.ToLower(v.filter)
+	var out [][]string
+	for _, row := range v.rows {
+		for i, cell := range row {
+			if i < len(v.visibleCols) && !v.visibleCols[i] {
+				continue
+			}
+			if strings.Contains(strings.ToLower(cell), needle) {
+				out = append(out, row)
+				break
+			}
+		}
+	}
+	return out
+}
+
+func (v *moduleView) redraw() {
+	v.table.Clear()
+
+	col := 0
+	for i, h := range v.header {
+		if !v.visibleCols[i] {
+			continue
+		}
+		v.table.SetCell(0, col, tview.NewTableCell(h).SetSelectable(false).SetTextColor(tcell.ColorYellow))
+		col++
+	}
+
+	for r, row := range v.filteredRows() {
+		col = 0
+		for i, cell := range row {
+			if i >= len(v.visibleCols) || !v.visibleCols[i] {
+				continue
+			}
+			v.table.SetCell(r+1, col, tview.NewTableCell(cell))
+			col++
+		}
+	}
+}
+
+// showDetail renders every column (not just the visible ones) for the
+// selected row, since a toggled-off column - e.g. an attached/inline policy
+// document - is exactly the kind of wide field the detail pane exists for.
+func (v *moduleView) showDetail(tableRow int) {
+	filtered := v.filteredRows()
+	if tableRow < 1 || tableRow > len(filtered) {
+		return
+	}
+	row := filtered[tableRow-1]
+
+	var b strings.Builder
+	for i, h := range v.header {
+		if i < len(row) {
+			fmt.Fprintf(&b, "[yellow]%s[-]: %s\n", h, row[i])
+		}
+	}
+	v.detail.SetText(b.String())
+}
+
+func (v *moduleView) promptFilter() {
+	input := tview.NewInputField().SetLabel("Filter: ").SetText(v.filter)
+	input.SetBorder(true).SetTitle("Filter rows (Enter to apply, Esc to cancel)")
+
+	page := v.pageName + ":filter"
+	input.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			v.filter = input.GetText()
+			v.redraw()
+		}
+		v.app.pages.RemovePage(page)
+		v.app.pages.SwitchToPage(v.pageName)
+	})
+	v.app.pages.AddAndSwitchToPage(page, input, true)
+}
+
+func (v *moduleView) promptColumnToggle() {
+	list := tview.NewList()
+	list.SetBorder(true).SetTitle("Toggle columns (Enter to flip, b/Esc done)")
+
+	page := v.pageName + ":columns"
+	var rebuild func()
+	rebuild = func() {
+		list.Clear()
+		for i, h := range v.header {
+			i := i
+			label := h
+			if !v.visibleCols[i] {
+				label += "  [hidden]"
+			}
+			list.AddItem(label, "", 0, func() {
+				v.visibleCols[i] = !v.visibleCols[i]
+				v.redraw()
+				rebuild()
+			})
+		}
+		list.AddItem("Done", "", 'b', func() {
+			v.app.pages.RemovePage(page)
+			v.app.pages.SwitchToPage(v.pageName)
+		})
+	}
+	rebuild()
+	list.SetDoneFunc(func() {
+		v.app.pages.RemovePage(page)
+		v.app.pages.SwitchToPage(v.pageName)
+	})
+
+	v.app.pages.AddAndSwitchToPage(page, list, true)
+}
@@ -0,0 +1,132 @@
+// Package tui is the interactive browser behind "cloudfox tui": provider ->
+// account/project/subscription -> module -> row, reading whatever
+// internal/outputindex finds under a run's cloudfox-output directory.
+package tui
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/BishopFox/cloudfox/internal/outputindex"
+)
+
+// App is one "cloudfox tui" session: a single tview.Pages root that every
+// screen (provider list, account list, module list, row table, filter/
+// column-toggle prompts) is pushed onto, so Escape/"b" can always pop back
+// to the previous screen without losing the rest of the navigation stack.
+type App struct {
+	OutputDirectory string
+
+	app   *tview.Application
+	pages *tview.Pages
+	index *outputindex.Index
+}
+
+// NewApp builds a TUI session rooted at outputDirectory (the same --outdir a
+// provider's commands were run with).
+func NewApp(outputDirectory string) *App {
+	return &App{
+		OutputDirectory: outputDirectory,
+		app:             tview.NewApplication(),
+		pages:           tview.NewPages(),
+	}
+}
+
+// Run loads the output index and blocks until the user quits.
+func (a *App) Run() error {
+	index, err := outputindex.Load(a.OutputDirectory)
+	if err != nil {
+		return fmt.Errorf("loading cloudfox-output index: %s", err)
+	}
+	a.index = index
+
+	a.pages.AddPage("providers", a.providersList(), true, true)
+	return a.app.SetRoot(a.pages, true).SetFocus(a.pages).Run()
+}
+
+func (a *App) providersList() tview.Primitive {
+	list := tview.NewList()
+	list.SetBorder(true).SetTitle("cloudfox tui - providers")
+
+	providers := a.index.Providers()
+	if len(providers) == 0 {
+		list.AddItem("(no cloudfox-output found under "+a.OutputDirectory+")", "", 0, nil)
+	}
+	for _, provider := range providers {
+		provider := provider
+		list.AddItem(provider, "", 0, func() {
+			page := "accounts:" + provider
+			a.pages.AddAndSwitchToPage(page, a.accountsList(provider, "providers"), true)
+		})
+	}
+	list.AddItem("Quit", "", 'q', func() { a.app.Stop() })
+	return list
+}
+
+func (a *App) accountsList(provider, backPage string) tview.Primitive {
+	list := tview.NewList()
+	list.SetBorder(true).SetTitle(provider + " - accounts / projects / subscriptions")
+
+	for _, account := range a.index.AccountsForProvider(provider) {
+		account := account
+		list.AddItem(account.Identifier, fmt.Sprintf("%d module(s)", len(account.Modules)), 0, func() {
+			page := "modules:" + provider + ":" + account.Identifier
+			a.pages.AddAndSwitchToPage(page, a.modulesList(account, "accounts:"+provider), true)
+		})
+	}
+	addBack(list, func() { a.pages.SwitchToPage(backPage) })
+	return list
+}
+
+func (a *App) modulesList(account outputindex.AccountEntry, backPage string) tview.Primitive {
+	list := tview.NewList()
+	list.SetBorder(true).SetTitle(account.Identifier + " - modules")
+
+	for _, module := range account.Modules {
+		module := module
+		list.AddItem(module.Name, fmt.Sprintf("%d row(s)", module.Rows), 0, func() {
+			a.showModule(account, module, "modules:"+account.Provider+":"+account.Identifier)
+		})
+	}
+	addBack(list, func() { a.pages.SwitchToPage(backPage) })
+	return list
+}
+
+func (a *App) showModule(account outputindex.AccountEntry, module outputindex.ModuleEntry, backPage string) {
+	header, rows, err := outputindex.ReadCSV(module.Path)
+	if err != nil {
+		a.showError(err, backPage)
+		return
+	}
+
+	pageName := "table:" + account.Identifier + ":" + module.Name
+	view := newModuleView(a, pageName, backPage, header, rows)
+	a.pages.AddAndSwitchToPage(pageName, view.root(), true)
+}
+
+func (a *App) showError(err error, backPage string) {
+	modal := tview.NewModal().
+		SetText(err.Error()).
+		AddButtons([]string{"OK"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.pages.RemovePage("error")
+			a.pages.SwitchToPage(backPage)
+		})
+	a.pages.AddAndSwitchToPage("error", modal, true)
+}
+
+// addBack appends the "go back" list item every screen but the provider
+// list has, with the 'b' shortcut and a consistent label.
+func addBack(list *tview.List, back func()) {
+	list.AddItem("Back", "", 'b', back)
+	list.SetDoneFunc(back)
+}
+
+// isBackKey is the row table's Escape/"b" check for "go back one screen";
+// the filter and column-toggle prompts handle Escape through their own done
+// callbacks instead.
+func isBackKey(event *tcell.EventKey) bool {
+	return event.Key() == tcell.KeyEscape || (event.Key() == tcell.KeyRune && event.Rune() == 'b')
+}